@@ -0,0 +1,297 @@
+// Package app builds the object graph of the service from its
+// configuration and owns the lifecycle of the HTTP and gRPC servers and
+// the database.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/aldisaputra17/book-store/cache"
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/controllers"
+	"github.com/aldisaputra17/book-store/database"
+	"github.com/aldisaputra17/book-store/graph"
+	"github.com/aldisaputra17/book-store/grpcserver"
+	"github.com/aldisaputra17/book-store/health"
+	"github.com/aldisaputra17/book-store/metrics"
+	"github.com/aldisaputra17/book-store/middleware"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/aldisaputra17/book-store/routes"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/aldisaputra17/book-store/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"gorm.io/gorm"
+)
+
+type App struct {
+	cfg        *config.Config
+	db         *gorm.DB
+	ownsDB     bool
+	health     *health.Health
+	router     *gin.Engine
+	httpServer *http.Server
+	grpcServer *grpc.Server
+	httpLis    net.Listener
+	grpcLis    net.Listener
+	errs       chan error
+
+	shutdownTracing func(context.Context) error
+}
+
+// New connects to the configured database, retrying for the configured
+// period or until ctx is done, and builds the application on it. It
+// refuses to when the schema is out of date, unless configured to migrate
+// it. Stop closes the connection.
+func New(ctx context.Context, cfg *config.Config) (*App, error) {
+	db, err := database.ConnectionDB(ctx, cfg.Database, cfg.Location())
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareSchema(ctx, cfg.Database, db); err != nil {
+		_ = database.CloseDatabaseConnection(db)
+		return nil, err
+	}
+	a, err := NewWithDB(cfg, db)
+	if err != nil {
+		_ = database.CloseDatabaseConnection(db)
+		return nil, err
+	}
+	a.ownsDB = true
+	return a, nil
+}
+
+// NewWithDB builds the application on db, e.g. a test database. The
+// caller keeps ownership of db.
+func NewWithDB(cfg *config.Config, db *gorm.DB) (*App, error) {
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+
+	m := metrics.New()
+	if db != nil {
+		if err := instrumentDB(db, cfg.Database.Name, m); err != nil {
+			return nil, err
+		}
+	}
+
+	bookRepository := repositories.NewBookRepository(db)
+	authorRepository := repositories.NewAuthorRepository(db)
+	userRepository := repositories.NewUserRepository(db)
+
+	bookService := services.NewBookService(bookRepository, authorRepository, cfg.ContextTimeout)
+	authorService := services.NewAuthorService(authorRepository, cfg.ContextTimeout)
+	if cfg.Cache.Backend == "memory" {
+		bookService, authorService = cache.New(cache.NewLRU(cfg.Cache.Size), cfg.Cache.TTL).Services(bookService, authorService)
+	}
+	bookService = m.BookService(tracing.BookService(bookService))
+	authorService = tracing.AuthorService(authorService)
+	authService := m.AuthService(tracing.AuthService(services.NewAuthService(userRepository, cfg.ContextTimeout)))
+	jwtService := services.NewJWTService(cfg.JWT)
+
+	schema, err := graph.NewSchema(bookService, authorService)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := health.New(health.DefaultTimeout, health.Database(db))
+	if db != nil {
+		// Fails when migrations this release needs are reverted while it
+		// runs.
+		checks.Add(health.Check{Name: "schema", Func: func(ctx context.Context) error {
+			return database.CheckSchema(ctx, db)
+		}})
+	}
+
+	router := gin.New()
+	// Lets services see the span and logger the middleware put on the
+	// request context through the *gin.Context the controllers pass down.
+	router.ContextWithFallback = true
+	// Metrics wraps Recovery so that recovered panics count as 500s.
+	router.Use(
+		otelgin.Middleware(cfg.Tracing.ServiceName),
+		middleware.RequestContext(),
+		middleware.AccessLog(),
+		m.Middleware(),
+		middleware.Recovery(),
+	)
+	routes.Register(router, routes.Handlers{
+		AuthController:     controllers.NewAuthController(authService, jwtService),
+		BookController:     controllers.NewBookController(bookService, jwtService),
+		AuthorController:   controllers.NewAuthorController(authorService, jwtService),
+		GraphQLController:  controllers.NewGraphQLController(schema),
+		HealthController:   controllers.NewHealthController(checks),
+		Metrics:            m.Handler(),
+		JWTService:         jwtService,
+		IdempotencyStore:   middleware.NewMemoryIdempotencyStore(),
+		IdempotencyTTL:     cfg.Idempotency.TTL,
+		IdempotencyMaxBody: cfg.Idempotency.MaxBody,
+	})
+
+	grpcOpts := []grpc.ServerOption{grpc.StatsHandler(otelgrpc.NewServerHandler())}
+	if cfg.HTTP.TLS.Enabled() {
+		creds, err := credentials.NewServerTLSFromFile(cfg.HTTP.TLS.CertFile, cfg.HTTP.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		grpcOpts = append(grpcOpts, grpc.Creds(creds))
+	}
+
+	return &App{
+		cfg:        cfg,
+		db:         db,
+		health:     checks,
+		router:     router,
+		httpServer: newHTTPServer(cfg.HTTP, router),
+		grpcServer: grpcserver.NewServer(bookService, authorService, authService, jwtService, grpcOpts...),
+		errs:       make(chan error, 2),
+
+		shutdownTracing: shutdownTracing,
+	}, nil
+}
+
+func prepareSchema(ctx context.Context, cfg config.DatabaseConfig, db *gorm.DB) error {
+	if cfg.MigrateOnStart {
+		if _, err := database.Migrate(ctx, db); err != nil {
+			return err
+		}
+	}
+	if err := database.CheckSchema(ctx, db); err != nil {
+		return fmt.Errorf("%w; run `book-store migrate up`", err)
+	}
+	return nil
+}
+
+// instrumentDB traces and times the queries of db and exports its pool
+// statistics.
+func instrumentDB(db *gorm.DB, name string, m *metrics.Metrics) error {
+	if err := db.Use(tracing.GormPlugin()); err != nil {
+		return err
+	}
+	if err := db.Use(m.GormPlugin()); err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return m.RegisterDBStats(sqlDB, name)
+}
+
+func newHTTPServer(cfg config.HTTPConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// Handler serves the HTTP API without listening, for in-process tests.
+func (a *App) Handler() http.Handler {
+	return a.router
+}
+
+// Start listens on the configured HTTP and gRPC ports and serves in the
+// background, over TLS when configured. Errors that stop a server are
+// reported on Errors.
+func (a *App) Start() error {
+	httpLis, err := net.Listen("tcp", ":"+a.cfg.HTTP.Port)
+	if err != nil {
+		return err
+	}
+	grpcLis, err := net.Listen("tcp", ":"+a.cfg.GRPC.Port)
+	if err != nil {
+		httpLis.Close()
+		return err
+	}
+	a.httpLis, a.grpcLis = httpLis, grpcLis
+
+	go func() {
+		if err := a.serveHTTP(httpLis); !errors.Is(err, http.ErrServerClosed) {
+			a.errs <- err
+		}
+	}()
+	go func() {
+		if err := a.grpcServer.Serve(grpcLis); err != nil {
+			a.errs <- err
+		}
+	}()
+	return nil
+}
+
+func (a *App) serveHTTP(lis net.Listener) error {
+	if tls := a.cfg.HTTP.TLS; tls.Enabled() {
+		return a.httpServer.ServeTLS(lis, tls.CertFile, tls.KeyFile)
+	}
+	return a.httpServer.Serve(lis)
+}
+
+// Errors reports servers that stopped on their own.
+func (a *App) Errors() <-chan error {
+	return a.errs
+}
+
+// HTTPAddr and GRPCAddr are the listening addresses once started, useful
+// with port 0.
+func (a *App) HTTPAddr() net.Addr {
+	return a.httpLis.Addr()
+}
+
+func (a *App) GRPCAddr() net.Addr {
+	return a.grpcLis.Addr()
+}
+
+// Health is the readiness state served at /readyz, for adding checks.
+func (a *App) Health() *health.Health {
+	return a.health
+}
+
+// Stop fails readiness and, after the configured shutdown delay, stops
+// accepting connections and drains in-flight HTTP requests and gRPC calls
+// until ctx is done, when the remaining ones are cut off. It then flushes
+// pending spans and closes the database if New opened it.
+func (a *App) Stop(ctx context.Context) error {
+	a.health.ShutDown()
+	select {
+	case <-time.After(a.cfg.HTTP.ShutdownDelay):
+	case <-ctx.Done():
+	}
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		a.grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	err := a.httpServer.Shutdown(ctx)
+	if err != nil {
+		a.httpServer.Close()
+	}
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		a.grpcServer.Stop()
+		<-grpcStopped
+	}
+
+	if tracingErr := a.shutdownTracing(ctx); err == nil {
+		err = tracingErr
+	}
+	if a.ownsDB {
+		if dbErr := database.CloseDatabaseConnection(a.db); err == nil {
+			err = dbErr
+		}
+	}
+	return err
+}
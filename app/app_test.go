@@ -0,0 +1,227 @@
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/health"
+	"github.com/gin-gonic/gin"
+)
+
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Env = config.EnvTest
+	cfg.HTTP.Port, cfg.GRPC.Port = "0", "0"
+	return cfg
+}
+
+func newTestApp(t *testing.T, cfg *config.Config) *App {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	// Only routes that do not reach the database are exercised here.
+	a, err := NewWithDB(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestHandlerServesAPI(t *testing.T) {
+	w := httptest.NewRecorder()
+	newTestApp(t, testConfig()).Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /api/openapi.json: status %d", w.Code)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	a := newTestApp(t, testConfig())
+	probe := func(path string) int {
+		w := httptest.NewRecorder()
+		a.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	// The built-in database check fails without a database.
+	if code := probe("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz without database: status %d, want 503", code)
+	}
+	if code := probe("/healthz"); code != http.StatusOK {
+		t.Errorf("GET /healthz: status %d, want 200", code)
+	}
+}
+
+func TestStopFailsReadinessFirst(t *testing.T) {
+	cfg := testConfig()
+	cfg.HTTP.ShutdownDelay = 200 * time.Millisecond
+	a := newTestApp(t, cfg)
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+	readyz := func() health.Report {
+		res, err := http.Get(fmt.Sprintf("http://%s/readyz", a.HTTPAddr()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		var report health.Report
+		if err := json.NewDecoder(res.Body).Decode(&report); err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+	if _, ok := readyz().Components["server"]; ok {
+		t.Fatal("server reported as shutting down before Stop")
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- a.Stop(context.Background()) }()
+	time.Sleep(50 * time.Millisecond)
+	if server := readyz().Components["server"]; server.Status != health.StatusFailing {
+		t.Errorf("server component while stopping = %+v, want failing", server)
+	}
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	a := newTestApp(t, testConfig())
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := http.Get(fmt.Sprintf("http://%s/api/openapi.json", a.HTTPAddr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("status %d", res.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := a.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-a.Errors():
+		t.Errorf("server error after Stop: %v", err)
+	default:
+	}
+	if _, err := http.Get(fmt.Sprintf("http://%s/", a.HTTPAddr())); err == nil {
+		t.Error("HTTP server still accepting connections after Stop")
+	}
+}
+
+func TestStopDrainsInFlightRequests(t *testing.T) {
+	a := newTestApp(t, testConfig())
+	started := make(chan struct{})
+	a.router.GET("/slow", func(c *gin.Context) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	})
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		res, err := http.Get(fmt.Sprintf("http://%s/slow", a.HTTPAddr()))
+		if err == nil {
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+			if string(body) != "done" {
+				err = fmt.Errorf("body %q", body)
+			}
+		}
+		result <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := a.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-result; err != nil {
+		t.Errorf("in-flight request was not drained: %v", err)
+	}
+}
+
+func TestStartTLS(t *testing.T) {
+	cfg := testConfig()
+	cfg.HTTP.TLS.CertFile, cfg.HTTP.TLS.KeyFile = writeCertificate(t)
+	a := newTestApp(t, cfg)
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Stop(context.Background())
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	res, err := client.Get(fmt.Sprintf("https://%s/api/openapi.json", a.HTTPAddr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.TLS == nil {
+		t.Errorf("status %d, TLS %v", res.StatusCode, res.TLS)
+	}
+}
+
+// writeCertificate writes a self-signed certificate for localhost.
+func writeCertificate(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv6loopback, net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
@@ -0,0 +1,137 @@
+// Package apperror defines the domain errors returned by repositories and
+// services. Each carries a Kind, which transports map to a status, and a
+// stable machine-readable Code for clients.
+package apperror
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindForbidden
+	KindUnauthorized
+	KindTooLarge
+)
+
+// Codes are part of the API contract; do not rename them.
+const (
+	CodeInternal           = "internal_error"
+	CodeBadRequest         = "bad_request"
+	CodeValidation         = "validation_failed"
+	CodeInvalidID          = "invalid_id"
+	CodeUnsupportedFormat  = "unsupported_format"
+	CodeNotFound           = "not_found"
+	CodeBookNotFound       = "book_not_found"
+	CodeAuthorNotFound     = "author_not_found"
+	CodeUserNotFound       = "user_not_found"
+	CodeUnknownReference   = "unknown_reference"
+	CodeConflict           = "conflict"
+	CodeEmailTaken         = "email_taken"
+	CodeIdempotencyReused  = "idempotency_key_reused"
+	CodeIdempotencyPending = "idempotency_key_in_progress"
+	CodeForbidden          = "forbidden"
+	CodeUnauthorized       = "unauthorized"
+	CodeMissingToken       = "missing_token"
+	CodeInvalidToken       = "invalid_token"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeRequestTooLarge    = "request_too_large"
+)
+
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind and code, so sentinel errors keep
+// matching once wrapped around a cause.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Wrap(kind Kind, code, message string, err error) *Error {
+	return &Error{Kind: kind, Code: code, Message: message, Err: err}
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Validation(code, message string, err error) *Error {
+	return Wrap(KindValidation, code, message, err)
+}
+
+func BadRequest(code, message string, err error) *Error {
+	return Wrap(KindBadRequest, code, message, err)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+// Binding classifies a request binding error: failed validation rules are
+// a Validation error, anything else (malformed JSON, wrong types) is a bad
+// request.
+func Binding(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return Validation(CodeValidation, "request validation failed", err)
+	}
+	return BadRequest(CodeBadRequest, "malformed request", err)
+}
+
+// From returns err as an *Error. Validator errors become Validation errors
+// and anything unrecognised is Internal.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return Validation(CodeValidation, "request validation failed", err)
+	}
+	return Wrap(KindInternal, CodeInternal, "internal error", err)
+}
+
+func KindOf(err error) Kind {
+	return From(err).Kind
+}
+
+func IsNotFound(err error) bool {
+	return err != nil && KindOf(err) == KindNotFound
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/google/uuid"
+)
+
+const (
+	// listsKey versions every listing of books and authors. Listings
+	// embed the records they relate to, so any change to the catalog
+	// invalidates all of them.
+	listsKey = "lists"
+
+	versionPrefix = "version:"
+)
+
+// Cache reads through a Store. Each entry depends on a key whose version
+// is part of the entry's own key; invalidating the key replaces its
+// version, so that the entries stored under the old one are never read
+// again, even when a read racing the write stores one after the
+// invalidation. Stale entries are left to expire.
+type Cache struct {
+	store Store
+	ttl   time.Duration
+}
+
+// New caches in store for ttl.
+func New(store Store, ttl time.Duration) *Cache {
+	return &Cache{store: store, ttl: ttl}
+}
+
+func bookKey(id string) string {
+	return "book:" + canonical(id)
+}
+
+func authorKey(id string) string {
+	return "author:" + canonical(id)
+}
+
+// canonical spells UUIDs the way invalidations do, whatever the case and
+// braces of the request.
+func canonical(id string) string {
+	if parsed, err := uuid.Parse(id); err == nil {
+		return parsed.String()
+	}
+	return id
+}
+
+// version returns the current version of key, starting one when it has
+// none. A new version is stored before the caller loads the data it will
+// cache under it, so a write finishing in between is always seen.
+func (c *Cache) version(ctx context.Context, key string) (string, error) {
+	value, ok, err := c.store.Get(ctx, versionPrefix+key)
+	if err != nil || ok {
+		return string(value), err
+	}
+	version := uuid.NewString()
+	return version, c.store.Set(ctx, versionPrefix+key, []byte(version), c.ttl)
+}
+
+// get fills v, a pointer, from the entry named name under the current
+// version of dependency, or else with load, which must fill v, and stores
+// the result. Failures of the store are logged and fall back to load.
+func (c *Cache) get(ctx context.Context, dependency, name string, v interface{}, load func() error) error {
+	version, err := c.version(ctx, dependency)
+	if err != nil {
+		helper.Logger(ctx).WithError(err).Warn("cache: get version")
+		return load()
+	}
+	key := name + "@" + version
+	if data, ok, err := c.store.Get(ctx, key); err != nil {
+		helper.Logger(ctx).WithError(err).Warn("cache: get")
+	} else if ok {
+		if err := json.Unmarshal(data, v); err == nil {
+			return nil
+		}
+	}
+
+	if err := load(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = c.store.Set(ctx, key, data, c.ttl)
+	}
+	if err != nil {
+		helper.Logger(ctx).WithError(err).Warn("cache: set")
+	}
+	return nil
+}
+
+// invalidate gives keys new versions. A failure is logged: the write it
+// follows succeeded, and its entries expire in time.
+func (c *Cache) invalidate(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := c.store.Set(ctx, versionPrefix+key, []byte(uuid.NewString()), c.ttl); err != nil {
+			helper.Logger(ctx).WithError(err).WithField("key", key).Error("cache: invalidate")
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package cache
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/formats"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/google/uuid"
+)
+
+// page is a cached listing.
+type page struct {
+	Books    []dto.ReadBookResponse   `json:"books,omitempty"`
+	Authors  []dto.ReadAuthorResponse `json:"authors,omitempty"`
+	PageInfo entities.Pagination      `json:"page_info"`
+}
+
+func listKey(kind, filterName, filter, searchName, search string, page, pageSize int) string {
+	return kind + "?" + url.Values{
+		filterName:  {filter},
+		searchName:  {search},
+		"page":      {strconv.Itoa(page)},
+		"page_size": {strconv.Itoa(pageSize)},
+	}.Encode()
+}
+
+// Services caches the reads of books and authors and invalidates them on
+// writes, including the cached authors of changed books and the cached
+// books of changed authors, whose relations books and authors look up.
+func (c *Cache) Services(books services.BookService, authors services.AuthorService) (services.BookService, services.AuthorService) {
+	return &bookService{BookService: books, cache: c, authors: authors},
+		&authorService{AuthorService: authors, cache: c, books: books}
+}
+
+type bookService struct {
+	services.BookService
+	cache   *Cache
+	authors services.AuthorService
+}
+
+func (s *bookService) FindByID(ctx context.Context, id string) (res *dto.ReadBookResponse, err error) {
+	err = s.cache.get(ctx, bookKey(id), bookKey(id), &res, func() error {
+		res, err = s.BookService.FindByID(ctx, id)
+		return err
+	})
+	return res, err
+}
+
+func (s *bookService) GetBookByCondition(ctx context.Context, authorID string, name string, pageNum int, PageSize int) ([]dto.ReadBookResponse, entities.Pagination, error) {
+	var cached page
+	key := listKey("books", "author_id", authorID, "name", name, pageNum, PageSize)
+	err := s.cache.get(ctx, listsKey, key, &cached, func() (err error) {
+		cached.Books, cached.PageInfo, err = s.BookService.GetBookByCondition(ctx, authorID, name, pageNum, PageSize)
+		return err
+	})
+	return cached.Books, cached.PageInfo, err
+}
+
+func (s *bookService) Create(ctx context.Context, bookReq *dto.CreateBookRequest) (*dto.CreateBookResponse, error) {
+	res, err := s.BookService.Create(ctx, bookReq)
+	if err == nil {
+		s.invalidate(ctx, nil, bookReq.AuthorID)
+	}
+	return res, err
+}
+
+func (s *bookService) Update(ctx context.Context, bookReq *dto.UpdateBookRequest) (*dto.UpdateBookResponse, error) {
+	res, err := s.BookService.Update(ctx, bookReq)
+	if err == nil {
+		ids := []string{bookReq.ID.String()}
+		s.invalidate(ctx, ids, s.authorsOf(ctx, ids))
+	}
+	return res, err
+}
+
+func (s *bookService) Delete(ctx context.Context, book entities.Book) error {
+	ids := []string{book.ID.String()}
+	authorIDs := s.authorsOf(ctx, ids)
+	err := s.BookService.Delete(ctx, book)
+	if err == nil {
+		s.invalidate(ctx, ids, authorIDs)
+	}
+	return err
+}
+
+func (s *bookService) BulkCreate(ctx context.Context, bulkReq *dto.BulkCreateBookRequest) (*dto.BulkResponse, error) {
+	res, err := s.BookService.BulkCreate(ctx, bulkReq)
+	if err == nil {
+		var authorIDs []string
+		for _, item := range bulkReq.Items {
+			authorIDs = append(authorIDs, item.AuthorID...)
+		}
+		s.invalidate(ctx, nil, authorIDs)
+	}
+	return res, err
+}
+
+func (s *bookService) BulkUpdate(ctx context.Context, bulkReq *dto.BulkUpdateBookRequest) (*dto.BulkResponse, error) {
+	res, err := s.BookService.BulkUpdate(ctx, bulkReq)
+	if err == nil {
+		ids := make([]string, len(bulkReq.Items))
+		for i, item := range bulkReq.Items {
+			ids[i] = item.ID.String()
+		}
+		s.invalidate(ctx, ids, s.authorsOf(ctx, ids))
+	}
+	return res, err
+}
+
+func (s *bookService) BulkDelete(ctx context.Context, bulkReq *dto.BulkDeleteRequest) (*dto.BulkResponse, error) {
+	authorIDs := s.authorsOf(ctx, bulkReq.IDs)
+	res, err := s.BookService.BulkDelete(ctx, bulkReq)
+	if err == nil {
+		s.invalidate(ctx, bulkReq.IDs, authorIDs)
+	}
+	return res, err
+}
+
+func (s *bookService) Import(ctx context.Context, records []formats.Record, dryRun bool) (*dto.ImportResponse, error) {
+	res, err := s.BookService.Import(ctx, records, dryRun)
+	if err == nil && !dryRun && res.Imported > 0 {
+		var ids []string
+		for _, result := range res.Results {
+			if result.Success {
+				ids = append(ids, result.ID)
+			}
+		}
+		s.invalidate(ctx, nil, s.authorsOf(ctx, ids))
+	}
+	return res, err
+}
+
+// authorsOf returns the ids of the authors of the books with ids, which
+// must be looked up before the books are deleted.
+func (s *bookService) authorsOf(ctx context.Context, ids []string) []string {
+	ids = validIDs(ids)
+	if len(ids) == 0 {
+		return nil
+	}
+	authors, err := s.authors.FindByBookIDs(ctx, ids)
+	if err != nil {
+		helper.Logger(ctx).WithError(err).Error("cache: find authors of changed books")
+		return nil
+	}
+	var authorIDs []string
+	for _, list := range authors {
+		for _, author := range list {
+			authorIDs = append(authorIDs, author.ID)
+		}
+	}
+	return authorIDs
+}
+
+// invalidate drops the cached books with bookIDs, authors with authorIDs
+// and every listing.
+func (s *bookService) invalidate(ctx context.Context, bookIDs, authorIDs []string) {
+	s.cache.invalidate(ctx, keys(bookIDs, authorIDs)...)
+}
+
+type authorService struct {
+	services.AuthorService
+	cache *Cache
+	books services.BookService
+}
+
+func (s *authorService) FindByID(ctx context.Context, id string) (res *dto.ReadAuthorResponse, err error) {
+	err = s.cache.get(ctx, authorKey(id), authorKey(id), &res, func() error {
+		res, err = s.AuthorService.FindByID(ctx, id)
+		return err
+	})
+	return res, err
+}
+
+func (s *authorService) GetAuthorByCondition(ctx context.Context, bookID string, title string, pageNum int, PageSize int) ([]dto.ReadAuthorResponse, entities.Pagination, error) {
+	var cached page
+	key := listKey("authors", "book_id", bookID, "title", title, pageNum, PageSize)
+	err := s.cache.get(ctx, listsKey, key, &cached, func() (err error) {
+		cached.Authors, cached.PageInfo, err = s.AuthorService.GetAuthorByCondition(ctx, bookID, title, pageNum, PageSize)
+		return err
+	})
+	return cached.Authors, cached.PageInfo, err
+}
+
+func (s *authorService) Create(ctx context.Context, authorReq *dto.CreateAuthorRequest) (*dto.AuthorResponse, error) {
+	res, err := s.AuthorService.Create(ctx, authorReq)
+	if err == nil {
+		s.invalidate(ctx, nil, nil)
+	}
+	return res, err
+}
+
+func (s *authorService) Update(ctx context.Context, authorReq *dto.UpdateAuthorRequest) (*dto.UpdateAuthorResponse, error) {
+	res, err := s.AuthorService.Update(ctx, authorReq)
+	if err == nil {
+		ids := []string{authorReq.ID.String()}
+		s.invalidate(ctx, ids, s.booksOf(ctx, ids))
+	}
+	return res, err
+}
+
+func (s *authorService) Delete(ctx context.Context, author entities.Author) error {
+	ids := []string{author.ID.String()}
+	bookIDs := s.booksOf(ctx, ids)
+	err := s.AuthorService.Delete(ctx, author)
+	if err == nil {
+		s.invalidate(ctx, ids, bookIDs)
+	}
+	return err
+}
+
+func (s *authorService) BulkCreate(ctx context.Context, bulkReq *dto.BulkCreateAuthorRequest) (*dto.BulkResponse, error) {
+	res, err := s.AuthorService.BulkCreate(ctx, bulkReq)
+	if err == nil {
+		s.invalidate(ctx, nil, nil)
+	}
+	return res, err
+}
+
+func (s *authorService) BulkUpdate(ctx context.Context, bulkReq *dto.BulkUpdateAuthorRequest) (*dto.BulkResponse, error) {
+	res, err := s.AuthorService.BulkUpdate(ctx, bulkReq)
+	if err == nil {
+		ids := make([]string, len(bulkReq.Items))
+		for i, item := range bulkReq.Items {
+			ids[i] = item.ID.String()
+		}
+		s.invalidate(ctx, ids, s.booksOf(ctx, ids))
+	}
+	return res, err
+}
+
+func (s *authorService) BulkDelete(ctx context.Context, bulkReq *dto.BulkDeleteRequest) (*dto.BulkResponse, error) {
+	bookIDs := s.booksOf(ctx, bulkReq.IDs)
+	res, err := s.AuthorService.BulkDelete(ctx, bulkReq)
+	if err == nil {
+		s.invalidate(ctx, bulkReq.IDs, bookIDs)
+	}
+	return res, err
+}
+
+// booksOf returns the ids of the books of the authors with ids, which
+// must be looked up before the authors are deleted.
+func (s *authorService) booksOf(ctx context.Context, ids []string) []string {
+	ids = validIDs(ids)
+	if len(ids) == 0 {
+		return nil
+	}
+	books, err := s.books.FindByAuthorIDs(ctx, ids)
+	if err != nil {
+		helper.Logger(ctx).WithError(err).Error("cache: find books of changed authors")
+		return nil
+	}
+	var bookIDs []string
+	for _, list := range books {
+		for _, book := range list {
+			bookIDs = append(bookIDs, book.ID)
+		}
+	}
+	return bookIDs
+}
+
+// invalidate drops the cached authors with authorIDs, books with bookIDs
+// and every listing.
+func (s *authorService) invalidate(ctx context.Context, authorIDs, bookIDs []string) {
+	s.cache.invalidate(ctx, keys(bookIDs, authorIDs)...)
+}
+
+// keys returns the keys to invalidate after a change to the books with
+// bookIDs or the authors with authorIDs: theirs and the listings'.
+func keys(bookIDs, authorIDs []string) []string {
+	keys := make([]string, 0, len(bookIDs)+len(authorIDs)+1)
+	keys = append(keys, listsKey)
+	seen := make(map[string]bool)
+	for _, id := range bookIDs {
+		if key := bookKey(id); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, id := range authorIDs {
+		if key := authorKey(id); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// validIDs drops the ids that are not UUIDs, which the repositories
+// reject and no record has.
+func validIDs(ids []string) []string {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	return valid
+}
@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/google/uuid"
+)
+
+// countingBooks counts the reads that reach the book service.
+type countingBooks struct {
+	services.BookService
+	finds, lists int
+}
+
+func (s *countingBooks) FindByID(ctx context.Context, id string) (*dto.ReadBookResponse, error) {
+	s.finds++
+	return s.BookService.FindByID(ctx, id)
+}
+
+func (s *countingBooks) GetBookByCondition(ctx context.Context, authorID string, name string, page int, PageSize int) ([]dto.ReadBookResponse, entities.Pagination, error) {
+	s.lists++
+	return s.BookService.GetBookByCondition(ctx, authorID, name, page, PageSize)
+}
+
+// countingAuthors counts the reads that reach the author service.
+type countingAuthors struct {
+	services.AuthorService
+	finds int
+}
+
+func (s *countingAuthors) FindByID(ctx context.Context, id string) (*dto.ReadAuthorResponse, error) {
+	s.finds++
+	return s.AuthorService.FindByID(ctx, id)
+}
+
+type fixture struct {
+	books         services.BookService
+	authors       services.AuthorService
+	bookReads     *countingBooks
+	authorReads   *countingAuthors
+	author, other *dto.AuthorResponse
+	book          *dto.CreateBookResponse
+}
+
+// newFixture returns cached services on in-memory repositories holding
+// two authors and a book by the first.
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	bookRepo := repositories.NewMemoryBookRepository(store)
+	authorRepo := repositories.NewMemoryAuthorRepository(store)
+	f := &fixture{
+		bookReads:   &countingBooks{BookService: services.NewBookService(bookRepo, authorRepo, time.Second)},
+		authorReads: &countingAuthors{AuthorService: services.NewAuthorService(authorRepo, time.Second)},
+	}
+	f.books, f.authors = New(NewLRU(100), time.Minute).Services(f.bookReads, f.authorReads)
+
+	var err error
+	if f.author, err = f.authors.Create(ctx, &dto.CreateAuthorRequest{Name: "Terry Pratchett", Country: "GB"}); err != nil {
+		t.Fatal(err)
+	}
+	if f.other, err = f.authors.Create(ctx, &dto.CreateAuthorRequest{Name: "Neil Gaiman", Country: "GB"}); err != nil {
+		t.Fatal(err)
+	}
+	if f.book, err = f.books.Create(ctx, &dto.CreateBookRequest{Title: "Mort", AuthorID: []string{f.author.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// findBook reads the book and reports whether the service was reached.
+func (f *fixture) findBook(t *testing.T, id string) bool {
+	t.Helper()
+	before := f.bookReads.finds
+	if _, err := f.books.FindByID(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	return f.bookReads.finds > before
+}
+
+func (f *fixture) listBooks(t *testing.T) []dto.ReadBookResponse {
+	t.Helper()
+	books, _, err := f.books.GetBookByCondition(context.Background(), "", "", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return books
+}
+
+func TestReadsAreCached(t *testing.T) {
+	f := newFixture(t)
+	if !f.findBook(t, f.book.ID) {
+		t.Fatal("first read was not loaded")
+	}
+	if f.findBook(t, f.book.ID) {
+		t.Error("second read was not cached")
+	}
+	// Other spellings of the id share the entry.
+	if f.findBook(t, "{"+f.book.ID+"}") {
+		t.Error("braced id was not cached")
+	}
+
+	f.listBooks(t)
+	f.listBooks(t)
+	if f.bookReads.lists != 1 {
+		t.Errorf("listing loaded %d times", f.bookReads.lists)
+	}
+	f.books.GetBookByCondition(context.Background(), "", "", 2, 10)
+	if f.bookReads.lists != 2 {
+		t.Error("another page was served from the cache")
+	}
+}
+
+func TestNotFoundIsNotCached(t *testing.T) {
+	f := newFixture(t)
+	id := uuid.NewString()
+	for i := 0; i < 2; i++ {
+		if _, err := f.books.FindByID(context.Background(), id); !errors.Is(err, repositories.ErrBookNotFound) {
+			t.Fatalf("err = %v", err)
+		}
+	}
+	if f.bookReads.finds != 2 {
+		t.Errorf("missing book loaded %d times", f.bookReads.finds)
+	}
+}
+
+func TestBookWritesInvalidate(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.findBook(t, f.book.ID)
+	f.authors.FindByID(ctx, f.author.ID)
+	f.listBooks(t)
+
+	if _, err := f.books.Update(ctx, &dto.UpdateBookRequest{ID: uuid.MustParse(f.book.ID), Title: "Mort (revised)"}); err != nil {
+		t.Fatal(err)
+	}
+	if !f.findBook(t, f.book.ID) {
+		t.Error("updated book served from the cache")
+	}
+	if books := f.listBooks(t); len(books) != 1 || books[0].Title != "Mort (revised)" {
+		t.Errorf("listing after update %+v", books)
+	}
+	before := f.authorReads.finds
+	f.authors.FindByID(ctx, f.author.ID)
+	if f.authorReads.finds == before {
+		t.Error("author of the updated book served from the cache")
+	}
+
+	if err := f.books.Delete(ctx, entities.Book{ID: uuid.MustParse(f.book.ID)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.books.FindByID(ctx, f.book.ID); !errors.Is(err, repositories.ErrBookNotFound) {
+		t.Errorf("deleted book: err = %v", err)
+	}
+	if books := f.listBooks(t); len(books) != 0 {
+		t.Errorf("listing after delete %+v", books)
+	}
+}
+
+func TestAuthorWritesInvalidateTheirBooks(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.findBook(t, f.book.ID)
+	f.listBooks(t)
+
+	// A change to another author leaves the book cached.
+	if _, err := f.authors.Update(ctx, &dto.UpdateAuthorRequest{ID: uuid.MustParse(f.other.ID), Country: "UK"}); err != nil {
+		t.Fatal(err)
+	}
+	if f.findBook(t, f.book.ID) {
+		t.Error("book of an unchanged author was invalidated")
+	}
+
+	if _, err := f.authors.Update(ctx, &dto.UpdateAuthorRequest{ID: uuid.MustParse(f.author.ID), Name: "Sir Terry Pratchett"}); err != nil {
+		t.Fatal(err)
+	}
+	if !f.findBook(t, f.book.ID) {
+		t.Error("book of the updated author served from the cache")
+	}
+	if books := f.listBooks(t); len(books) != 1 || books[0].Author[0].Name != "Sir Terry Pratchett" {
+		t.Errorf("listing after author update %+v", books)
+	}
+	f.findBook(t, f.book.ID)
+
+	res, err := f.authors.BulkDelete(ctx, &dto.BulkDeleteRequest{Mode: dto.BulkModeBestEffort, IDs: []string{f.author.ID}})
+	if err != nil || res.Succeeded != 1 {
+		t.Fatalf("bulk delete %+v (%v)", res, err)
+	}
+	if !f.findBook(t, f.book.ID) {
+		t.Error("book of the deleted author served from the cache")
+	}
+}
+
+// failingStore fails every call.
+type failingStore struct{}
+
+func (failingStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("store down")
+}
+
+func (failingStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.New("store down")
+}
+
+func TestStoreFailuresFallBackToTheService(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	authorRepo := repositories.NewMemoryAuthorRepository(store)
+	books, authors := New(failingStore{}, time.Minute).Services(
+		services.NewBookService(repositories.NewMemoryBookRepository(store), authorRepo, time.Second),
+		services.NewAuthorService(authorRepo, time.Second))
+
+	author, err := authors.Create(ctx, &dto.CreateAuthorRequest{Name: "Terry Pratchett", Country: "GB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := authors.FindByID(ctx, author.ID); err != nil || got.Name != author.Name {
+		t.Fatalf("read %+v (%v)", got, err)
+	}
+	if list, _, err := books.GetBookByCondition(ctx, author.ID, "", 1, 10); err != nil || len(list) != 0 {
+		t.Fatalf("list %+v (%v)", list, err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package cache caches the catalog reads of the book and author services
+// in a Store, and invalidates them when the catalog changes.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps values for a while. Values are opaque bytes so that a shared
+// backend such as Redis or Memcached can implement it with GET and SET
+// with an expiry; a miss is ok false, not an error. A store may evict
+// anything at any time.
+type Store interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	// order holds the most recently used entry at the front.
+	order *list.List
+	now   func() time.Time
+}
+
+// NewLRU keeps up to size values in process memory, evicting the least
+// recently used one first. Each replica of the service has its own, so
+// they only agree once their entries expire.
+func NewLRU(size int) Store {
+	return &lru{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+func (c *lru) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.remove(elem)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set keeps value until ttl has passed, or for good when ttl is 0.
+func (c *lru) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *lru) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := NewLRU(2)
+	store.Set(ctx, "a", []byte("1"), 0)
+	store.Set(ctx, "b", []byte("2"), 0)
+	// Reading a makes b the least recently used.
+	if _, ok, _ := store.Get(ctx, "a"); !ok {
+		t.Fatal("a missing")
+	}
+	store.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := store.Get(ctx, "b"); ok {
+		t.Error("b kept over the size")
+	}
+	for key, want := range map[string]string{"a": "1", "c": "3"} {
+		if value, ok, _ := store.Get(ctx, key); !ok || string(value) != want {
+			t.Errorf("%s = %q, %v", key, value, ok)
+		}
+	}
+}
+
+func TestLRUExpires(t *testing.T) {
+	ctx := context.Background()
+	store := NewLRU(10).(*lru)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	store.Set(ctx, "short", []byte("1"), time.Minute)
+	store.Set(ctx, "forever", []byte("2"), 0)
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := store.Get(ctx, "short"); ok {
+		t.Error("expired entry returned")
+	}
+	if _, ok, _ := store.Get(ctx, "forever"); !ok {
+		t.Error("entry without ttl expired")
+	}
+	if len(store.entries) != 1 || store.order.Len() != 1 {
+		t.Errorf("expired entry kept: %d entries", len(store.entries))
+	}
+}
@@ -0,0 +1,127 @@
+// Package cli implements the book-store command: the server and the
+// operational tasks that share its configuration and repositories.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/database"
+	"github.com/aldisaputra17/book-store/helper"
+	"gorm.io/gorm"
+)
+
+const usage = `Usage: book-store [--config file] <command> [arguments]
+
+Commands:
+  serve                                 start the HTTP and gRPC servers (default)
+  help                                  print this message
+  migrate up|down [n]|status            apply, revert (the last n, default 1) or
+                                        list the schema migrations
+  seed --fixtures dir                   create the records of the YAML files in dir
+  user create-admin --email e [--password-stdin]
+                                        create an administrator
+  user reset-password --email e [--password-stdin]
+                                        set a new password for a user
+
+The password is read from the first line of stdin with --password-stdin,
+else from $BOOK_STORE_PASSWORD, else a random one is generated and printed.
+--password p also works but leaves p in the shell history and ps output.
+
+Only administrators can write books and authors; registered users can read.
+`
+
+// errUsage makes Run print the usage and exit with status 2.
+var errUsage = errors.New("usage")
+
+type command func(ctx context.Context, env *env, args []string) error
+
+var commands = map[string]command{
+	"serve":   serve,
+	"migrate": migrate,
+	"seed":    seed,
+	"user":    user,
+}
+
+// env is what commands share: the loaded configuration, the input and
+// output, and the function that stops turning SIGINT and SIGTERM into the
+// cancellation of the command's context.
+type env struct {
+	cfg         *config.Config
+	stdin       io.Reader
+	stdout      io.Writer
+	stopSignals func()
+}
+
+// Run executes the command of args (without the program name) and returns
+// the exit status.
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("book-store", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	configPath := flags.String("config", "", "YAML configuration file (default $"+config.FileEnv+")")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	name, args := "serve", flags.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "book-store: unknown command %q\n\n%s", name, usage)
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "book-store:", err)
+		return 1
+	}
+	if err := helper.ConfigureLogger(cfg.Log, cfg.Tracing.ServiceName); err != nil {
+		fmt.Fprintln(stderr, "book-store:", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := cmd(ctx, &env{cfg: cfg, stdin: stdin, stdout: stdout, stopSignals: stop}, args); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+		fmt.Fprintf(stderr, "book-store %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// openDB connects to the configured database; the caller closes it.
+func (e *env) openDB(ctx context.Context) (*gorm.DB, error) {
+	return database.ConnectionDB(ctx, e.cfg.Database, e.cfg.Location())
+}
+
+// parseFlags parses the flags of a subcommand, which take no positional
+// arguments.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunRejectsBadUsage(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		status int
+		stderr string
+	}{
+		{"help", []string{"help"}, 0, ""},
+		{"unknown command", []string{"deploy"}, 2, `unknown command "deploy"`},
+		{"unknown flag", []string{"--verbose", "serve"}, 2, "flag provided but not defined"},
+		{"serve arguments", []string{"serve", "now"}, 2, "Usage:"},
+		{"migrate direction", []string{"migrate", "sideways"}, 2, "Usage:"},
+		{"seed without fixtures", []string{"seed"}, 2, "Usage:"},
+		{"user without action", []string{"user"}, 2, "Usage:"},
+		{"user unknown action", []string{"user", "delete", "--email", "admin@example.com"}, 2, "Usage:"},
+		{"user unknown flag", []string{"user", "create-admin", "--name", "x"}, 2, "Usage:"},
+		{"short password", []string{"user", "create-admin", "--email", "admin@example.com", "--password", "abc"}, 1, "password must be at least 6 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if status := Run(tt.args, strings.NewReader(""), &stdout, &stderr); status != tt.status {
+				t.Fatalf("status %d, want %d; stderr: %s", status, tt.status, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("stderr %q does not contain %q", stderr.String(), tt.stderr)
+			}
+		})
+	}
+}
+
+func TestUserPasswordSources(t *testing.T) {
+	createAdmin := []string{"user", "create-admin", "--email", "admin@example.com"}
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		env    string
+		stderr string
+	}{
+		// A short password fails validation before the database is opened.
+		{"stdin", append(createAdmin, "--password-stdin"), "abc\nrest\n", "", "password must be at least 6 characters"},
+		{"empty stdin", append(createAdmin, "--password-stdin"), "", "", "no password on stdin"},
+		{"environment", createAdmin, "", "abc", "password must be at least 6 characters"},
+		{"flag over environment", append(createAdmin, "--password", "abc"), "", "long enough", "password must be at least 6 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PasswordEnv, tt.env)
+			var stdout, stderr bytes.Buffer
+			if status := Run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr); status != 1 {
+				t.Fatalf("status %d, want 1; stderr: %s", status, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("stderr %q does not contain %q", stderr.String(), tt.stderr)
+			}
+		})
+	}
+}
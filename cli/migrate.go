@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aldisaputra17/book-store/database"
+)
+
+// migrate applies the pending migrations (up), reverts the last n, one by
+// default (down [n]), or lists them (status).
+func migrate(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	steps := 1
+	switch {
+	case args[0] == "down" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return errUsage
+		}
+		steps = n
+	case len(args) != 1, args[0] != "up" && args[0] != "down" && args[0] != "status":
+		return errUsage
+	}
+
+	db, err := e.openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.CloseDatabaseConnection(db)
+
+	switch args[0] {
+	case "up":
+		applied, err := database.Migrate(ctx, db)
+		for _, m := range applied {
+			fmt.Fprintf(e.stdout, "applied  %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(e.stdout, "schema is up to date")
+		}
+	case "down":
+		reverted, err := database.Rollback(ctx, db, steps)
+		for _, m := range reverted {
+			fmt.Fprintf(e.stdout, "reverted %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(e.stdout, "no migration to revert")
+		}
+	case "status":
+		states, err := database.MigrationStatus(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, s := range states {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			if s.Unknown {
+				applied += " (by a newer release)"
+			}
+			fmt.Fprintf(e.stdout, "%04d  %-24s %s\n", s.Version, s.Name, applied)
+		}
+	}
+	return nil
+}
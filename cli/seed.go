@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/aldisaputra17/book-store/database"
+	"github.com/aldisaputra17/book-store/fixtures"
+	"github.com/aldisaputra17/book-store/repositories"
+)
+
+// seed creates the records of a fixture directory that are not there yet.
+func seed(ctx context.Context, e *env, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	dir := flags.String("fixtures", "", "directory of YAML fixture files")
+	if err := parseFlags(flags, args); err != nil || *dir == "" {
+		return errUsage
+	}
+	set, err := fixtures.Load(*dir)
+	if err != nil {
+		return err
+	}
+
+	db, err := e.openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.CloseDatabaseConnection(db)
+	res, err := fixtures.Seed(ctx, fixtures.Repositories{
+		Books:   repositories.NewBookRepository(db),
+		Authors: repositories.NewAuthorRepository(db),
+		Users:   repositories.NewUserRepository(db),
+	}, set)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "created %d authors, %d books, %d users; %d already present\n", res.Authors, res.Books, res.Users, res.Skipped)
+	return nil
+}
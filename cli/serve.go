@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/aldisaputra17/book-store/app"
+	log "github.com/sirupsen/logrus"
+)
+
+// serve runs the servers until ctx is done on SIGINT or SIGTERM, then
+// shuts them down gracefully.
+func serve(ctx context.Context, e *env, args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+	log.Info("Starting Server")
+	a, err := app.New(ctx, e.cfg)
+	if err != nil {
+		return err
+	}
+	if err := a.Start(); err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{"http": a.HTTPAddr().String(), "grpc": a.GRPCAddr().String()}).Info("Listening")
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		log.Info("Shutting down")
+	case serveErr = <-a.Errors():
+		log.WithError(serveErr).Error("Server stopped")
+	}
+	// A second signal kills the process.
+	e.stopSignals()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), e.cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := a.Stop(shutdownCtx); err != nil {
+		log.WithError(err).Error("Shutdown")
+		if serveErr == nil {
+			serveErr = err
+		}
+	}
+	log.Info("Server stopped")
+	return serveErr
+}
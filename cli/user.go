@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aldisaputra17/book-store/database"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/aldisaputra17/book-store/services"
+)
+
+// user manages accounts: create-admin and reset-password.
+func user(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 || args[0] != "create-admin" && args[0] != "reset-password" {
+		return errUsage
+	}
+	flags := flag.NewFlagSet("user "+args[0], flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user")
+	passwordFlag := flags.String("password", "", "new password, visible in the shell history and ps")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the first line of stdin")
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	password, generated, err := readPassword(e.stdin, *passwordFlag, *passwordStdin)
+	if err != nil {
+		return err
+	}
+	req := &dto.AuthRequest{Email: *email, Password: password}
+	if err := helper.ValidateStruct(req); err != nil {
+		var messages []string
+		for _, field := range helper.FieldErrors(err, helper.DefaultLocale) {
+			messages = append(messages, field.Message)
+		}
+		return errors.New(strings.Join(messages, "; "))
+	}
+
+	db, err := e.openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.CloseDatabaseConnection(db)
+	authService := services.NewAuthService(repositories.NewUserRepository(db), e.cfg.ContextTimeout)
+
+	switch args[0] {
+	case "create-admin":
+		admin, err := authService.CreateAdmin(ctx, req)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "created admin %s (%s)\n", admin.Email, admin.ID)
+	case "reset-password":
+		if err := authService.ResetPassword(ctx, req); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "password of %s reset\n", req.Email)
+	}
+	if generated {
+		fmt.Fprintf(e.stdout, "password: %s\n", req.Password)
+	}
+	return nil
+}
+
+// PasswordEnv holds the password of the user commands run without
+// --password or --password-stdin.
+const PasswordEnv = "BOOK_STORE_PASSWORD"
+
+// readPassword returns the password given by flag, else the first line of
+// stdin when fromStdin, else $BOOK_STORE_PASSWORD, else a generated one, in
+// which case generated is true.
+func readPassword(stdin io.Reader, flag string, fromStdin bool) (password string, generated bool, err error) {
+	switch {
+	case flag != "":
+		return flag, false, nil
+	case fromStdin:
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", false, err
+		}
+		if line = strings.TrimRight(line, "\r\n"); line == "" {
+			return "", false, errors.New("no password on stdin")
+		}
+		return line, false, nil
+	case os.Getenv(PasswordEnv) != "":
+		return os.Getenv(PasswordEnv), false, nil
+	}
+	password, err = generatePassword()
+	return password, true, err
+}
+
+// generatePassword returns 16 random bytes, base64url encoded.
+func generatePassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
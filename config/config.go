@@ -0,0 +1,314 @@
+// Package config loads the service configuration from defaults, an
+// optional YAML file, a .env file and the environment, in increasing order
+// of precedence, and validates it before anything is started.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+
+	// DefaultJWTSecret only exists so development works out of the box;
+	// Load refuses it in production.
+	DefaultJWTSecret = "book-store"
+
+	// FileEnv names the YAML file to load when Load is given no path.
+	FileEnv = "CONFIG_FILE"
+)
+
+type Config struct {
+	Env            string            `yaml:"env" env:"APP_ENV" validate:"oneof=development production test"`
+	Timezone       string            `yaml:"timezone" env:"APP_TIMEZONE" validate:"required"`
+	ContextTimeout time.Duration     `yaml:"context_timeout" env:"CONTEXT_TIMEOUT" validate:"gt=0"`
+	HTTP           HTTPConfig        `yaml:"http"`
+	GRPC           GRPCConfig        `yaml:"grpc"`
+	Database       DatabaseConfig    `yaml:"database"`
+	JWT            JWTConfig         `yaml:"jwt"`
+	Idempotency    IdempotencyConfig `yaml:"idempotency"`
+	Cache          CacheConfig       `yaml:"cache"`
+	Tracing        TracingConfig     `yaml:"tracing"`
+	Log            LogConfig         `yaml:"log"`
+
+	location *time.Location
+}
+
+type HTTPConfig struct {
+	Port              string        `yaml:"port" env:"PORT" validate:"required,numeric"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT" validate:"gte=0"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT" validate:"gte=0"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT" validate:"gte=0"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" validate:"gte=0"`
+	// ShutdownDelay is how long /readyz fails before the servers stop,
+	// so the orchestrator stops routing traffic first. ShutdownTimeout
+	// then bounds how long in-flight requests are drained.
+	ShutdownDelay   time.Duration `yaml:"shutdown_delay" env:"SHUTDOWN_DELAY" validate:"gte=0"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" validate:"gt=0"`
+	TLS             TLSConfig     `yaml:"tls"`
+}
+
+// TLSConfig enables TLS on the HTTP and gRPC servers when both files are
+// set.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" env:"TLS_CERT_FILE" validate:"required_with=KeyFile,omitempty,file"`
+	KeyFile  string `yaml:"key_file" env:"TLS_KEY_FILE" validate:"required_with=CertFile,omitempty,file"`
+}
+
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+type GRPCConfig struct {
+	Port string `yaml:"port" env:"GRPC_PORT" validate:"required,numeric"`
+}
+
+// DatabaseConfig connects to Postgres with URL, a postgres:// URL or a
+// key=value DSN, when set, and with the separate fields otherwise. With the
+// sqlite driver, URL (or else Name) is the database file, or ":memory:".
+type DatabaseConfig struct {
+	Driver      string `yaml:"driver" env:"DB_DRIVER" validate:"oneof=postgres sqlite"`
+	URL         string `yaml:"url" env:"DATABASE_URL"`
+	Host        string `yaml:"host" env:"DB_HOST" validate:"required_without=URL"`
+	Port        string `yaml:"port" env:"DB_PORT" validate:"required_without=URL,omitempty,numeric"`
+	User        string `yaml:"user" env:"DB_USER" validate:"required_without=URL"`
+	Password    string `yaml:"password" env:"DB_PASSWORD"`
+	Name        string `yaml:"name" env:"DB_NAME" validate:"required_without=URL"`
+	SSLMode     string `yaml:"ssl_mode" env:"DB_SSLMODE" validate:"oneof=disable allow prefer require verify-ca verify-full"`
+	SSLRootCert string `yaml:"ssl_root_cert" env:"DB_SSLROOTCERT" validate:"omitempty,file"`
+	// ConnectRetry is how long connecting is retried, with exponential
+	// backoff, before giving up; 0 tries once.
+	ConnectRetry    time.Duration `yaml:"connect_retry" env:"DB_CONNECT_RETRY" validate:"gte=0"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" validate:"gte=0"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" validate:"gte=0"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" validate:"gte=0"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" validate:"gte=0"`
+	// MigrateOnStart applies pending migrations when the server starts
+	// instead of refusing to serve.
+	MigrateOnStart bool `yaml:"migrate_on_start" env:"DB_MIGRATE_ON_START"`
+}
+
+type JWTConfig struct {
+	Secret string        `yaml:"secret" env:"JWT_SECRET" validate:"required"`
+	Issuer string        `yaml:"issuer" env:"JWT_ISSUER" validate:"required"`
+	TTL    time.Duration `yaml:"ttl" env:"JWT_TTL" validate:"gt=0"`
+}
+
+// IdempotencyConfig keeps responses for TTL. Requests carrying a key are
+// read into memory to be compared, up to MaxBody bytes.
+type IdempotencyConfig struct {
+	TTL     time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" validate:"gt=0"`
+	MaxBody int64         `yaml:"max_body" env:"IDEMPOTENCY_MAX_BODY" validate:"gt=0"`
+}
+
+// CacheConfig caches catalog reads for TTL. Backend "memory" keeps up to
+// Size entries in each replica, "none" disables the cache.
+type CacheConfig struct {
+	Backend string        `yaml:"backend" env:"CACHE_BACKEND" validate:"oneof=none memory"`
+	Size    int           `yaml:"size" env:"CACHE_SIZE" validate:"gt=0"`
+	TTL     time.Duration `yaml:"ttl" env:"CACHE_TTL" validate:"gt=0"`
+}
+
+// TracingConfig uses the standard OpenTelemetry variable names. Exporter
+// "stdout" prints spans for local runs, "otlp" sends them over gRPC.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER" validate:"oneof=none stdout otlp"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" validate:"required_if=Exporter otlp"`
+	Insecure    bool    `yaml:"insecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" validate:"required"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG" validate:"gte=0,lte=1"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" validate:"oneof=debug info warn error"`
+	Format string `yaml:"format" env:"LOG_FORMAT" validate:"oneof=json text"`
+}
+
+// Default is the configuration used for anything not set elsewhere.
+func Default() *Config {
+	return &Config{
+		Env:            EnvDevelopment,
+		Timezone:       "Asia/Jakarta",
+		ContextTimeout: 10 * time.Second,
+		HTTP: HTTPConfig{
+			Port:              "8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   15 * time.Second,
+		},
+		GRPC: GRPCConfig{Port: "9090"},
+		Database: DatabaseConfig{
+			Driver:          DriverPostgres,
+			Host:            "127.0.0.1",
+			Port:            "5432",
+			User:            "postgres",
+			Name:            "book-store",
+			SSLMode:         "disable",
+			ConnectRetry:    30 * time.Second,
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		JWT: JWTConfig{
+			Secret: DefaultJWTSecret,
+			Issuer: "book-store",
+			TTL:    365 * 24 * time.Hour,
+		},
+		Idempotency: IdempotencyConfig{TTL: 24 * time.Hour, MaxBody: 10 << 20},
+		Cache:       CacheConfig{Backend: "memory", Size: 10000, TTL: time.Minute},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4317",
+			Insecure:    true,
+			ServiceName: "book-store",
+			SampleRatio: 1,
+		},
+		Log: LogConfig{Level: "info", Format: "json"},
+	}
+}
+
+// Load reads the YAML file at path (or CONFIG_FILE when path is empty;
+// no file is fine), then .env, then the environment, and validates the
+// result.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path == "" {
+		path = os.Getenv(FileEnv)
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	// Variables already in the environment win over .env.
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("config: read .env: %w", err)
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("config: parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate checks the rules of the `validate` tags and the values that
+// must not be left at their development defaults in production.
+func (cfg *Config) Validate() error {
+	if err := validator.New().Struct(cfg); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return fmt.Errorf("config: timezone: %w", err)
+	}
+	cfg.location = loc
+	if cfg.IsProduction() && cfg.JWT.Secret == DefaultJWTSecret {
+		return errors.New("config: JWT_SECRET must be set to a non-default value in production")
+	}
+	return nil
+}
+
+func (cfg *Config) IsProduction() bool {
+	return cfg.Env == EnvProduction
+}
+
+// Location is the loaded Timezone; Validate must have succeeded.
+func (cfg *Config) Location() *time.Location {
+	if cfg.location == nil {
+		return time.Local
+	}
+	return cfg.location
+}
+
+// applyEnv overrides the fields tagged `env` with the variables that are
+// set, descending into nested structs.
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnv(value); err != nil {
+				return err
+			}
+			continue
+		}
+		name := field.Tag.Get("env")
+		raw, ok := os.LookupEnv(name)
+		if name == "" || !ok {
+			continue
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("config: %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(value reflect.Value, raw string) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv(FileEnv, "")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ContextTimeout != 10*time.Second || cfg.GRPC.Port != "9090" || cfg.JWT.Secret != DefaultJWTSecret {
+		t.Errorf("unexpected defaults %+v", cfg)
+	}
+	if cfg.Location().String() != "Asia/Jakarta" {
+		t.Errorf("location = %s", cfg.Location())
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, `
+timezone: UTC
+context_timeout: 5s
+database:
+  host: db.internal
+  name: catalog
+idempotency:
+  ttl: 1h
+`)
+	t.Setenv("DB_NAME", "from-env")
+	t.Setenv("CONTEXT_TIMEOUT", "3s")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Host != "db.internal" || cfg.Idempotency.TTL != time.Hour || cfg.Timezone != "UTC" {
+		t.Errorf("file values not applied: %+v", cfg)
+	}
+	if cfg.Database.Name != "from-env" || cfg.ContextTimeout != 3*time.Second {
+		t.Errorf("environment should win over the file: %+v", cfg)
+	}
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"default secret in production", map[string]string{"APP_ENV": EnvProduction}, "JWT_SECRET"},
+		{"unknown env", map[string]string{"APP_ENV": "staging"}, "Env"},
+		{"bad duration", map[string]string{"CONTEXT_TIMEOUT": "soon"}, "CONTEXT_TIMEOUT"},
+		{"zero timeout", map[string]string{"CONTEXT_TIMEOUT": "0s"}, "ContextTimeout"},
+		{"bad timezone", map[string]string{"APP_TIMEZONE": "Mars/Olympus"}, "timezone"},
+		{"missing db host", map[string]string{"DB_HOST": ""}, "Host"},
+		{"cert without key", map[string]string{"TLS_CERT_FILE": "config.go"}, "KeyFile"},
+		{"missing cert file", map[string]string{"TLS_CERT_FILE": "missing.pem", "TLS_KEY_FILE": "config.go"}, "CertFile"},
+		{"unknown exporter", map[string]string{"OTEL_TRACES_EXPORTER": "jaeger"}, "Exporter"},
+		{"sample ratio above one", map[string]string{"OTEL_TRACES_SAMPLER_ARG": "1.5"}, "SampleRatio"},
+		{"unknown log level", map[string]string{"LOG_LEVEL": "verbose"}, "Level"},
+		{"unknown cache backend", map[string]string{"CACHE_BACKEND": "redis"}, "Backend"},
+		{"unknown ssl mode", map[string]string{"DB_SSLMODE": "always"}, "SSLMode"},
+		{"unknown driver", map[string]string{"DB_DRIVER": "mysql"}, "Driver"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(FileEnv, "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := Load("")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want mention of %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAcceptsProductionSecret(t *testing.T) {
+	t.Setenv(FileEnv, "")
+	t.Setenv("APP_ENV", EnvProduction)
+	t.Setenv("JWT_SECRET", "s3cret")
+	if _, err := Load(""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDatabaseURLReplacesFields(t *testing.T) {
+	t.Setenv(FileEnv, "")
+	t.Setenv("DATABASE_URL", "postgres://app@db.internal/catalog")
+	t.Setenv("DB_HOST", "")
+	t.Setenv("DB_NAME", "")
+	if _, err := Load(""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/aldisaputra17/book-store/apperror"
 	"github.com/aldisaputra17/book-store/dto"
 	"github.com/aldisaputra17/book-store/entities"
 	"github.com/aldisaputra17/book-store/helper"
@@ -32,44 +33,39 @@ func (c *authController) Login(ctx *gin.Context) {
 	var reqLogin *dto.AuthRequest
 	err := ctx.ShouldBind(&reqLogin)
 	if err != nil {
-		response := helper.BuildErrorResponse("Failed to process request", err.Error(), helper.EmptyObj{})
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		abortWithError(ctx, "Failed to process request", apperror.Binding(err))
 		return
 	}
 	authResult := c.authService.VerifyCredential(reqLogin.Email, reqLogin.Password)
 	if v, ok := authResult.(entities.User); ok {
-		generatedToken := c.jwtService.GenerateToken(v.ID)
+		generatedToken := c.jwtService.GenerateToken(v.ID, v.Admin)
 		v.Token = generatedToken
 		response := helper.BuildResponse(true, "Ok!", v)
 		ctx.JSON(http.StatusOK, response)
 		return
 	}
-	response := helper.BuildErrorResponse("Please check again your credential", "Invalid Credential", helper.EmptyObj{})
-	ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+	abortWithError(ctx, "Please check again your credential", apperror.Unauthorized(apperror.CodeInvalidCredentials, "Invalid Credential"))
 }
 
 func (c *authController) Register(ctx *gin.Context) {
 	var reqRegister *dto.AuthRequest
 	errObj := ctx.ShouldBind(&reqRegister)
 	if errObj != nil {
-		response := helper.BuildErrorResponse("Failed to process request", errObj.Error(), helper.EmptyObj{})
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, response)
+		abortWithError(ctx, "Failed to process request", apperror.Binding(errObj))
 		return
 	}
 
 	if !c.authService.IsDuplicateEmail(reqRegister.Email) {
-		response := helper.BuildErrorResponse("Failed to process request", "Duplicate username", helper.EmptyObj{})
-		ctx.JSON(http.StatusConflict, response)
+		abortWithError(ctx, "Failed to process request", apperror.Conflict(apperror.CodeEmailTaken, "Duplicate username"))
 		return
 	}
 	createdUser, err := c.authService.Register(ctx, reqRegister)
 	if err != nil {
-		res := helper.BuildErrorResponse("Failed to created", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed to created", err)
 		fmt.Println("erorr", err)
 		return
 	} else {
-		token := c.jwtService.GenerateToken(createdUser.ID)
+		token := c.jwtService.GenerateToken(createdUser.ID, createdUser.Admin)
 		createdUser.Token = token
 		response := helper.BuildResponse(true, "Created!", createdUser)
 		ctx.JSON(http.StatusCreated, response)
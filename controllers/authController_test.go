@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/middleware"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/gin-gonic/gin"
+)
+
+// racingAuthService registers nobody: the email is free when checked but
+// taken by the time the user is inserted, as when two requests race.
+type racingAuthService struct {
+	services.AuthService
+}
+
+func (racingAuthService) IsDuplicateEmail(email string) bool {
+	return true
+}
+
+func (racingAuthService) Register(ctx context.Context, registerReq *dto.AuthRequest) (*entities.User, error) {
+	return nil, apperror.Wrap(apperror.KindConflict, apperror.CodeEmailTaken, "email already registered",
+		errors.New(`ERROR: duplicate key value violates unique constraint "idx_users_email" (SQLSTATE 23505)`))
+}
+
+func TestConflictHidesDriverError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.POST("/register", NewAuthController(racingAuthService{}, services.NewJWTService(config.Default().JWT)).Register)
+
+	for _, accept := range []string{"application/json", "application/problem+json"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"email":"reader@example.com","password":"secret123"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", accept)
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("%s: status %d: %s", accept, w.Code, w.Body)
+		}
+		body := w.Body.String()
+		if strings.Contains(body, "SQLSTATE") || strings.Contains(body, "idx_users_email") || !strings.Contains(body, "email already registered") {
+			t.Errorf("%s: body %s", accept, body)
+		}
+	}
+}
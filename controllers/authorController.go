@@ -4,12 +4,12 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/aldisaputra17/book-store/apperror"
 	"github.com/aldisaputra17/book-store/dto"
 	"github.com/aldisaputra17/book-store/entities"
 	"github.com/aldisaputra17/book-store/helper"
 	"github.com/aldisaputra17/book-store/services"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -19,6 +19,9 @@ type AuthorController interface {
 	Update(ctx *gin.Context)
 	Delete(ctx *gin.Context)
 	FindByID(ctx *gin.Context)
+	BulkCreate(ctx *gin.Context)
+	BulkUpdate(ctx *gin.Context)
+	BulkDelete(ctx *gin.Context)
 }
 
 type authorController struct {
@@ -42,15 +45,13 @@ func (c *authorController) Create(ctx *gin.Context) {
 	err := ctx.ShouldBind(&reqAuthor)
 	if err != nil {
 		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err json.NewEncoder")
-		res := helper.BuildErrorResponse("Failed get object post", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed get object post", apperror.Binding(err))
 		return
 	}
 	res, err := c.authorService.Create(ctx, reqAuthor)
 	if err != nil {
 		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err json.NewEncoder")
-		res := helper.BuildErrorResponse("Failed create author", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed create author", err)
 		return
 	}
 	result := helper.BuildResponse(true, "success", res)
@@ -63,19 +64,17 @@ func (c *authorController) Update(ctx *gin.Context) {
 		ctxt      = "authorHttpHandler-updateAuthor"
 	)
 
-	errObj := ctx.BindJSON(&authorReq)
+	errObj := ctx.ShouldBindJSON(&authorReq)
 	if errObj != nil {
 		helper.Log(ctx, log.ErrorLevel, errObj, ctxt, "err json.NewEncoder")
-		res := helper.BuildErrorResponse("Failed get object author", errObj.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed get object author", apperror.Binding(errObj))
 		return
 	}
 
 	res, err := c.authorService.Update(ctx, authorReq)
 	if err != nil {
 		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err update author")
-		res := helper.BuildErrorResponse("Failed updated author", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed updated author", err)
 		return
 	}
 	result := helper.BuildResponse(true, "Ok", res)
@@ -88,35 +87,34 @@ func (c *authorController) Delete(ctx *gin.Context) {
 		ctxt = "authorHttpHandler-deleteAuthor"
 		err  error
 	)
-	id := ctx.Param("id")
-	book.ID = uuid.Must(uuid.Parse(id))
-
-	if c.authorService.IsAllowedToEdit(ctx, id) {
-		err = c.authorService.Delete(ctx, book)
-		if err != nil {
-			helper.Log(ctx, log.ErrorLevel, err, ctxt, "err deleted author")
-			res := helper.BuildErrorResponse("Failed deleted author", err.Error(), helper.EmptyObj{})
-			ctx.JSON(http.StatusBadRequest, res)
-			return
-		}
-		res := helper.BuildResponse(true, "Ok", helper.EmptyObj{})
-		ctx.JSON(http.StatusOK, res)
-	} else {
-		response := helper.BuildErrorResponse("You dont have permission", "You are not the owner", helper.EmptyObj{})
-		ctx.JSON(http.StatusForbidden, response)
+	id, ok := bindID(ctx)
+	if !ok {
+		return
+	}
+	book.ID = id
+
+	err = c.authorService.Delete(ctx, book)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err deleted author")
+		abortWithError(ctx, "Failed deleted author", err)
+		return
 	}
+	res := helper.BuildResponse(true, "Ok", helper.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
 }
 
 func (c *authorController) FindByID(ctx *gin.Context) {
 	var (
 		ctxt = "authorHttpHandler-findByIDAuthor"
 	)
-	id := ctx.Param("id")
-	list, err := c.authorService.FindByID(ctx, id)
+	id, ok := bindID(ctx)
+	if !ok {
+		return
+	}
+	list, err := c.authorService.FindByID(ctx, id.String())
 	if err != nil {
 		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err fetch author")
-		res := helper.BuildErrorResponse("Failed fetch author", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed fetch author", err)
 		return
 	}
 	res := helper.BuildResponse(true, "Ok", list)
@@ -145,10 +143,78 @@ func (c *authorController) GetAuthorByCondition(ctx *gin.Context) {
 	lists, total, err := c.authorService.GetAuthorByCondition(ctx, bookID, title, page, pageSize)
 	if err != nil {
 		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err fetch author")
-		res := helper.BuildErrorResponse("Failed fetch author", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed fetch author", err)
 		return
 	}
 	res := helper.BuildReadWithPagination(true, "Ok", lists, total)
 	ctx.JSON(http.StatusOK, res)
 }
+
+func (c *authorController) BulkCreate(ctx *gin.Context) {
+	var (
+		bulkReq *dto.BulkCreateAuthorRequest
+		ctxt    = "authorHttpHandler-bulkCreateAuthor"
+	)
+
+	err := ctx.ShouldBind(&bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bind bulk request")
+		abortWithError(ctx, "Failed get object bulk", apperror.Binding(err))
+		return
+	}
+	res, err := c.authorService.BulkCreate(ctx, bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bulk create author")
+		abortWithError(ctx, "Failed bulk create author", err)
+		return
+	}
+	status, message := bulkStatus(res, http.StatusCreated)
+	result := helper.BuildResponse(res.Failed == 0, message, res)
+	ctx.JSON(status, result)
+}
+
+func (c *authorController) BulkUpdate(ctx *gin.Context) {
+	var (
+		bulkReq *dto.BulkUpdateAuthorRequest
+		ctxt    = "authorHttpHandler-bulkUpdateAuthor"
+	)
+
+	err := ctx.ShouldBind(&bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bind bulk request")
+		abortWithError(ctx, "Failed get object bulk", apperror.Binding(err))
+		return
+	}
+	res, err := c.authorService.BulkUpdate(ctx, bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bulk update author")
+		abortWithError(ctx, "Failed bulk update author", err)
+		return
+	}
+	status, message := bulkStatus(res, http.StatusOK)
+	result := helper.BuildResponse(res.Failed == 0, message, res)
+	ctx.JSON(status, result)
+}
+
+func (c *authorController) BulkDelete(ctx *gin.Context) {
+	var (
+		bulkReq *dto.BulkDeleteRequest
+		ctxt    = "authorHttpHandler-bulkDeleteAuthor"
+	)
+
+	err := ctx.ShouldBind(&bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bind bulk request")
+		abortWithError(ctx, "Failed get object bulk", apperror.Binding(err))
+		return
+	}
+	res, err := c.authorService.BulkDelete(ctx, bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bulk delete author")
+		abortWithError(ctx, "Failed bulk delete author", err)
+		return
+	}
+	status, message := bulkStatus(res, http.StatusOK)
+	result := helper.BuildResponse(res.Failed == 0, message, res)
+	ctx.JSON(status, result)
+}
@@ -4,21 +4,31 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/aldisaputra17/book-store/apperror"
 	"github.com/aldisaputra17/book-store/dto"
 	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/formats"
 	"github.com/aldisaputra17/book-store/helper"
 	"github.com/aldisaputra17/book-store/services"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 )
 
+// exportFlushRows is how many rows an export buffers before flushing them
+// to the client.
+const exportFlushRows = 100
+
 type BookController interface {
 	Create(ctx *gin.Context)
 	Update(ctx *gin.Context)
 	Delete(ctx *gin.Context)
 	FindByID(ctx *gin.Context)
 	GetBookByCondition(ctx *gin.Context)
+	BulkCreate(ctx *gin.Context)
+	BulkUpdate(ctx *gin.Context)
+	BulkDelete(ctx *gin.Context)
+	Export(ctx *gin.Context)
+	Import(ctx *gin.Context)
 }
 
 type bookController struct {
@@ -42,15 +52,13 @@ func (c *bookController) Create(ctx *gin.Context) {
 	err := ctx.ShouldBind(&reqBook)
 	if err != nil {
 		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err json.NewEncoder")
-		res := helper.BuildErrorResponse("Failed get object post", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed get object post", apperror.Binding(err))
 		return
 	}
 	res, err := c.bookService.Create(ctx, reqBook)
 	if err != nil {
 		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err json.NewEncoder")
-		res := helper.BuildErrorResponse("Failed get object post", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed get object post", err)
 		return
 	}
 	result := helper.BuildResponse(true, "Created", res)
@@ -63,19 +71,17 @@ func (c *bookController) Update(ctx *gin.Context) {
 		ctxt    = "bookHttpHandler-updateBook"
 	)
 
-	errObj := ctx.BindJSON(&bookReq)
+	errObj := ctx.ShouldBindJSON(&bookReq)
 	if errObj != nil {
 		helper.Log(ctx, log.ErrorLevel, errObj, ctxt, "err json.NewEncoder")
-		res := helper.BuildErrorResponse("Failed get object post", errObj.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed get object post", apperror.Binding(errObj))
 		return
 	}
 
 	res, err := c.bookService.Update(ctx, bookReq)
 	if err != nil {
 		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err json.NewEncoder")
-		res := helper.BuildErrorResponse("Failed get object post", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed get object post", err)
 		return
 	}
 	result := helper.BuildResponse(true, "Ok", res)
@@ -88,23 +94,20 @@ func (c *bookController) Delete(ctx *gin.Context) {
 		ctxt = "bookHttpHandler-deleteBook"
 		err  error
 	)
-	id := ctx.Param("id")
-	book.ID = uuid.Must(uuid.Parse(id))
-
-	if c.bookService.IsAllowedToEdit(ctx, id) {
-		err = c.bookService.Delete(ctx, book)
-		if err != nil {
-			helper.Log(ctx, log.ErrorLevel, err, ctxt, "err deleted book")
-			res := helper.BuildErrorResponse("Failed deleted book", err.Error(), helper.EmptyObj{})
-			ctx.JSON(http.StatusBadRequest, res)
-			return
-		}
-		res := helper.BuildResponse(true, "Ok", helper.EmptyObj{})
-		ctx.JSON(http.StatusOK, res)
-	} else {
-		response := helper.BuildErrorResponse("You dont have permission", "You are not the owner", helper.EmptyObj{})
-		ctx.JSON(http.StatusForbidden, response)
+	id, ok := bindID(ctx)
+	if !ok {
+		return
 	}
+	book.ID = id
+
+	err = c.bookService.Delete(ctx, book)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err deleted book")
+		abortWithError(ctx, "Failed deleted book", err)
+		return
+	}
+	res := helper.BuildResponse(true, "Ok", helper.EmptyObj{})
+	ctx.JSON(http.StatusOK, res)
 
 }
 
@@ -112,12 +115,14 @@ func (c *bookController) FindByID(ctx *gin.Context) {
 	var (
 		ctxt = "bookHttpHandler-findByIDBook"
 	)
-	id := ctx.Param("id")
-	list, err := c.bookService.FindByID(ctx, id)
+	id, ok := bindID(ctx)
+	if !ok {
+		return
+	}
+	list, err := c.bookService.FindByID(ctx, id.String())
 	if err != nil {
 		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err fetch book")
-		res := helper.BuildErrorResponse("Failed fetch book", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed fetch book", err)
 		return
 	}
 	res := helper.BuildResponse(true, "Ok", list)
@@ -146,10 +151,192 @@ func (c *bookController) GetBookByCondition(ctx *gin.Context) {
 	books, total, err := c.bookService.GetBookByCondition(ctx, authorID, name, page, pageSize)
 	if err != nil {
 		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err fetch book")
-		res := helper.BuildErrorResponse("Failed fecth book", err.Error(), helper.EmptyObj{})
-		ctx.JSON(http.StatusBadRequest, res)
+		abortWithError(ctx, "Failed fecth book", err)
 		return
 	}
 	res := helper.BuildReadWithPagination(true, "Ok", books, total)
 	ctx.JSON(http.StatusOK, res)
 }
+
+func (c *bookController) BulkCreate(ctx *gin.Context) {
+	var (
+		bulkReq *dto.BulkCreateBookRequest
+		ctxt    = "bookHttpHandler-bulkCreateBook"
+	)
+
+	err := ctx.ShouldBind(&bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bind bulk request")
+		abortWithError(ctx, "Failed get object bulk", apperror.Binding(err))
+		return
+	}
+	res, err := c.bookService.BulkCreate(ctx, bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bulk create book")
+		abortWithError(ctx, "Failed bulk create book", err)
+		return
+	}
+	status, message := bulkStatus(res, http.StatusCreated)
+	result := helper.BuildResponse(res.Failed == 0, message, res)
+	ctx.JSON(status, result)
+}
+
+func (c *bookController) BulkUpdate(ctx *gin.Context) {
+	var (
+		bulkReq *dto.BulkUpdateBookRequest
+		ctxt    = "bookHttpHandler-bulkUpdateBook"
+	)
+
+	err := ctx.ShouldBind(&bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bind bulk request")
+		abortWithError(ctx, "Failed get object bulk", apperror.Binding(err))
+		return
+	}
+	res, err := c.bookService.BulkUpdate(ctx, bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bulk update book")
+		abortWithError(ctx, "Failed bulk update book", err)
+		return
+	}
+	status, message := bulkStatus(res, http.StatusOK)
+	result := helper.BuildResponse(res.Failed == 0, message, res)
+	ctx.JSON(status, result)
+}
+
+func (c *bookController) BulkDelete(ctx *gin.Context) {
+	var (
+		bulkReq *dto.BulkDeleteRequest
+		ctxt    = "bookHttpHandler-bulkDeleteBook"
+	)
+
+	err := ctx.ShouldBind(&bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bind bulk request")
+		abortWithError(ctx, "Failed get object bulk", apperror.Binding(err))
+		return
+	}
+	res, err := c.bookService.BulkDelete(ctx, bulkReq)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bulk delete book")
+		abortWithError(ctx, "Failed bulk delete book", err)
+		return
+	}
+	status, message := bulkStatus(res, http.StatusOK)
+	result := helper.BuildResponse(res.Failed == 0, message, res)
+	ctx.JSON(status, result)
+}
+
+func (c *bookController) Export(ctx *gin.Context) {
+	var (
+		ctxt = "bookHttpHandler-exportBook"
+	)
+	format := ctx.DefaultQuery("format", formats.FormatCSV)
+	writer, err := formats.NewWriter(format, &exportResponse{ctx: ctx, format: format})
+	if err != nil {
+		abortWithError(ctx, "Failed export book", apperror.BadRequest(apperror.CodeUnsupportedFormat, "unsupported format", err))
+		return
+	}
+	authorID := ctx.Query("author_id")
+	name := ctx.Query("name")
+
+	rows := 0
+	err = c.bookService.Export(ctx, authorID, name, func(book *entities.Book) error {
+		if err := writer.Write(book); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			ctx.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err export book")
+		if !ctx.Writer.Written() {
+			abortWithError(ctx, "Failed export book", err)
+		}
+	}
+}
+
+// exportResponse writes an export to the client, committing the file
+// headers and the 200 status with its first bytes, so that an export failing
+// before any book was written still gets a regular error response.
+type exportResponse struct {
+	ctx    *gin.Context
+	format string
+}
+
+func (w *exportResponse) Write(p []byte) (int, error) {
+	if !w.ctx.Writer.Written() {
+		contentType, extension := formats.ContentType(w.format)
+		w.ctx.Header("Content-Type", contentType)
+		w.ctx.Header("Content-Disposition", `attachment; filename="books.`+extension+`"`)
+		w.ctx.Status(http.StatusOK)
+	}
+	return w.ctx.Writer.Write(p)
+}
+
+func (c *bookController) Import(ctx *gin.Context) {
+	var (
+		ctxt = "bookHttpHandler-importBook"
+	)
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err get import file")
+		abortWithError(ctx, "Failed get import file", apperror.BadRequest(apperror.CodeBadRequest, "file is required", err))
+		return
+	}
+	format := ctx.DefaultQuery("format", ctx.DefaultPostForm("format", formats.FormatCSV))
+	dryRun, _ := strconv.ParseBool(ctx.DefaultQuery("dry_run", ctx.PostForm("dry_run")))
+	mapping := ctx.PostFormMap("mapping")
+	for column, header := range ctx.QueryMap("mapping") {
+		mapping[column] = header
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err open import file")
+		abortWithError(ctx, "Failed open import file", err)
+		return
+	}
+	defer f.Close()
+
+	records, err := formats.Read(format, f, mapping)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err read import file")
+		abortWithError(ctx, "Failed read import file", apperror.BadRequest(apperror.CodeBadRequest, "invalid import file", err))
+		return
+	}
+	c.importRecords(ctx, records, dryRun)
+}
+
+func (c *bookController) importRecords(ctx *gin.Context, records []formats.Record, dryRun bool) {
+	var (
+		ctxt = "bookHttpHandler-importBook"
+	)
+	res, err := c.bookService.Import(ctx, records, dryRun)
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err import book")
+		abortWithError(ctx, "Failed import book", err)
+		return
+	}
+	status := http.StatusCreated
+	message := "Imported"
+	switch {
+	case dryRun:
+		status, message = http.StatusOK, "Dry run"
+	case res.Failed > 0 && res.Imported > 0:
+		status, message = http.StatusMultiStatus, "Partially imported"
+	case res.Failed > 0:
+		status, message = http.StatusBadRequest, "No row imported"
+	}
+	result := helper.BuildResponse(res.Failed == 0, message, res)
+	ctx.JSON(status, result)
+}
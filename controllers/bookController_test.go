@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/middleware"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/gin-gonic/gin"
+)
+
+// exportingBooks exports books, or fails with err before the first one.
+type exportingBooks struct {
+	services.BookService
+	books []*entities.Book
+	err   error
+}
+
+func (s exportingBooks) Export(ctx context.Context, authorID string, name string, fn func(book *entities.Book) error) error {
+	if s.err != nil {
+		return s.err
+	}
+	for _, book := range s.books {
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func export(service services.BookService) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/book/export", NewBookController(service, services.NewJWTService(config.Default().JWT)).Export)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/book/export?format=csv", nil))
+	return w
+}
+
+func TestExportHeaders(t *testing.T) {
+	w := export(exportingBooks{books: []*entities.Book{{Title: "Mort"}}})
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") ||
+		!strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment") || !strings.Contains(w.Body.String(), "Mort") {
+		t.Errorf("export: %d %v\n%s", w.Code, w.Header(), w.Body)
+	}
+
+	w = export(exportingBooks{err: errors.New("connection refused")})
+	if w.Code != http.StatusInternalServerError || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") ||
+		w.Header().Get("Content-Disposition") != "" {
+		t.Errorf("failed export: %d %v\n%s", w.Code, w.Header(), w.Body)
+	}
+}
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aldisaputra17/book-store/dto"
+)
+
+// bulkStatus picks the status code and message for a bulk response: okStatus
+// when every item was applied, 207 when only some were, 400 when none were.
+func bulkStatus(res *dto.BulkResponse, okStatus int) (int, string) {
+	switch {
+	case res.Failed == 0:
+		return okStatus, "Ok"
+	case res.Succeeded == 0 && res.Mode == dto.BulkModeAtomic:
+		return http.StatusBadRequest, "Batch rolled back"
+	case res.Succeeded == 0:
+		return http.StatusBadRequest, "No item applied"
+	default:
+		return http.StatusMultiStatus, "Partially applied"
+	}
+}
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// abortWithError hands err to middleware.ErrorHandler, which picks the
+// status from its apperror kind. message becomes the response message.
+func abortWithError(ctx *gin.Context, message string, err error) {
+	_ = ctx.Error(err).SetMeta(message)
+	ctx.Abort()
+}
+
+// bindID binds the :id path parameter, aborting with a 400 when it is not
+// a UUID.
+func bindID(ctx *gin.Context) (uuid.UUID, bool) {
+	var param dto.PathID
+	err := ctx.ShouldBindUri(&param)
+	if err == nil {
+		var id uuid.UUID
+		if id, err = uuid.Parse(param.ID); err == nil {
+			return id, true
+		}
+	}
+	abortWithError(ctx, "Invalid path parameter", apperror.BadRequest(apperror.CodeInvalidID, "id must be a UUID", err))
+	return uuid.Nil, false
+}
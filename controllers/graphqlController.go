@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/graph"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/aldisaputra17/book-store/middleware"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type GraphQLController interface {
+	Query(ctx *gin.Context)
+}
+
+type graphQLController struct {
+	schema *graph.Schema
+}
+
+func NewGraphQLController(schema *graph.Schema) GraphQLController {
+	return &graphQLController{
+		schema: schema,
+	}
+}
+
+func (c *graphQLController) Query(ctx *gin.Context) {
+	var (
+		req  dto.GraphQLRequest
+		ctxt = "graphqlHttpHandler-query"
+		err  error
+	)
+
+	if ctx.Request.Method == http.MethodGet {
+		err = ctx.ShouldBindQuery(&req)
+		if variables := ctx.Query("variables"); err == nil && variables != "" {
+			err = json.Unmarshal([]byte(variables), &req.Variables)
+		}
+	} else {
+		err = ctx.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		helper.Log(ctx, log.ErrorLevel, err, ctxt, "err bind graphql request")
+		abortWithError(ctx, "Failed get graphql request", apperror.Binding(err))
+		return
+	}
+
+	if ctx.Request.Method == http.MethodGet && !graph.IsQuery(req.Query, req.OperationName) {
+		abortWithError(ctx, "Failed get graphql request", apperror.BadRequest(apperror.CodeBadRequest, "only queries can be sent with GET, use POST for mutations", nil))
+		return
+	}
+
+	caller := graph.Caller{Authenticated: middleware.IsAuthenticated(ctx), Admin: middleware.IsAdmin(ctx)}
+	result := c.schema.Execute(ctx, req.Query, req.OperationName, req.Variables, caller)
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aldisaputra17/book-store/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController interface {
+	Live(ctx *gin.Context)
+	Ready(ctx *gin.Context)
+}
+
+type healthController struct {
+	health *health.Health
+}
+
+func NewHealthController(health *health.Health) HealthController {
+	return &healthController{
+		health: health,
+	}
+}
+
+func (c *healthController) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.health.Live())
+}
+
+func (c *healthController) Ready(ctx *gin.Context) {
+	report := c.health.Ready(ctx.Request.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, report)
+}
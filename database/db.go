@@ -1,46 +1,143 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"os"
+	"strings"
 	"time"
 
-	"github.com/aldisaputra17/book-store/entities"
-	"github.com/joho/godotenv"
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/glebarez/sqlite"
+	log "github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func ConnectionDB() *gorm.DB {
-	err := godotenv.Load()
+// Backoff between connection attempts doubles from minRetryDelay up to
+// maxRetryDelay.
+const (
+	minRetryDelay = 250 * time.Millisecond
+	maxRetryDelay = 5 * time.Second
+)
+
+// ConnectionDB opens the database of cfg and checks it answers, retrying
+// with exponential backoff for cfg.ConnectRetry or until ctx is done.
+// Timestamps are set in loc.
+func ConnectionDB(ctx context.Context, cfg config.DatabaseConfig, loc *time.Location) (*gorm.DB, error) {
+	gormConfig := &gorm.Config{
+		NowFunc: func() time.Time {
+			return time.Now().In(loc)
+		},
+	}
+	deadline := time.Now().Add(cfg.ConnectRetry)
+	delay := minRetryDelay
+	for attempt := 1; ; attempt++ {
+		db, err := open(cfg, gormConfig)
+		if err == nil {
+			return db, nil
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return nil, fmt.Errorf("database: connect after %d attempts: %w", attempt, err)
+		}
+		log.WithError(err).WithFields(log.Fields{"attempt": attempt, "retry_in": delay.String()}).Warn("database: connect failed")
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("database: connect: %w (last error: %v)", ctx.Err(), err)
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+func open(cfg config.DatabaseConfig, gormConfig *gorm.Config) (*gorm.DB, error) {
+	dialector := postgres.Open(DSN(cfg))
+	if cfg.Driver == config.DriverSQLite {
+		dialector = sqlite.Open(DSN(cfg))
+	}
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
-		log.Println("Failed connect to db")
+		// The pool is created before the failed ping.
+		if db != nil {
+			_ = CloseDatabaseConnection(db)
+		}
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Driver == config.DriverSQLite {
+		// Every connection to ":memory:" is a database of its own, so the
+		// pool keeps a single one open for good; a file takes one writer
+		// at a time anyway.
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		return db, nil
 	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return db, nil
+}
 
-	dbUser := os.Getenv("DB_USER")
-	dbPass := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
+// DSN is cfg.URL when set, and otherwise the key=value connection string
+// of the separate fields, quoted as libpq expects. SQLite DSNs are the
+// database file with foreign keys and a busy timeout turned on.
+func DSN(cfg config.DatabaseConfig) string {
+	if cfg.Driver == config.DriverSQLite {
+		return sqliteDSN(cfg)
+	}
+	if cfg.URL != "" {
+		return cfg.URL
+	}
+	params := []struct{ key, value string }{
+		{"host", cfg.Host},
+		{"port", cfg.Port},
+		{"user", cfg.User},
+		{"password", cfg.Password},
+		{"dbname", cfg.Name},
+		{"sslmode", cfg.SSLMode},
+		{"sslrootcert", cfg.SSLRootCert},
+	}
+	var parts []string
+	for _, p := range params {
+		if p.value == "" {
+			continue
+		}
+		parts = append(parts, p.key+"="+quoteDSNValue(p.value))
+	}
+	return strings.Join(parts, " ")
+}
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=disable password=%s", dbHost, dbPort, dbUser, dbName, dbPass)
+func sqliteDSN(cfg config.DatabaseConfig) string {
+	dsn := cfg.URL
+	if dsn == "" {
+		dsn = cfg.Name
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
 
-	db, _ := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		NowFunc: func() time.Time {
-			ti, _ := time.LoadLocation("Asia/Jakarta")
-			return time.Now().In(ti)
-		},
-	})
+var dsnEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
 
-	db.AutoMigrate(&entities.Book{}, &entities.Author{}, &entities.User{})
-	return db
+func quoteDSNValue(v string) string {
+	if !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	return "'" + dsnEscaper.Replace(v) + "'"
 }
 
-func CloseDatabaseConnection(db *gorm.DB) {
+// CloseDatabaseConnection closes the connection pool of db.
+func CloseDatabaseConnection(db *gorm.DB) error {
 	dbSQL, err := db.DB()
 	if err != nil {
-		panic("Failed to close connection from database")
+		return err
 	}
-	dbSQL.Close()
+	return dbSQL.Close()
 }
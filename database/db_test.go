@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/config"
+)
+
+func TestDSN(t *testing.T) {
+	cfg := config.Default().Database
+	cfg.Password = `it's \secret`
+	cfg.SSLMode = "verify-full"
+	want := `host=127.0.0.1 port=5432 user=postgres password='it\'s \\secret' dbname=book-store sslmode=verify-full`
+	if got := DSN(cfg); got != want {
+		t.Errorf("DSN = %s\nwant  %s", got, want)
+	}
+
+	cfg.URL = "postgres://app@db.internal/catalog?sslmode=require"
+	if got := DSN(cfg); got != cfg.URL {
+		t.Errorf("DSN = %s, want the URL", got)
+	}
+
+	cfg.Driver = config.DriverSQLite
+	cfg.URL = "file:demo.db?cache=shared"
+	if got, want := DSN(cfg), "file:demo.db?cache=shared&_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"; got != want {
+		t.Errorf("sqlite DSN = %s, want %s", got, want)
+	}
+	cfg.URL = ""
+	if got, want := DSN(cfg), "book-store?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"; got != want {
+		t.Errorf("sqlite DSN = %s, want %s", got, want)
+	}
+}
+
+// unusedAddr returns a local port nothing listens on.
+func unusedAddr(t *testing.T) (host, port string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	host, port, _ = net.SplitHostPort(addr)
+	return host, port
+}
+
+func TestConnectionDBRetriesThenFails(t *testing.T) {
+	cfg := config.Default().Database
+	cfg.Host, cfg.Port = unusedAddr(t)
+	cfg.ConnectRetry = time.Second
+
+	start := time.Now()
+	_, err := ConnectionDB(context.Background(), cfg, time.UTC)
+	if err == nil || !strings.Contains(err.Error(), "attempts") {
+		t.Fatalf("err = %v, want a failure after retrying", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("gave up after %s", elapsed)
+	}
+}
+
+func TestConnectionDBStopsWithContext(t *testing.T) {
+	cfg := config.Default().Database
+	cfg.Host, cfg.Port = unusedAddr(t)
+	cfg.ConnectRetry = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err := ConnectionDB(ctx, cfg, time.UTC)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the context error", err)
+	}
+}
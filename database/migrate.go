@@ -0,0 +1,301 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldisaputra17/book-store/config"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+const migrationsTable = "schema_migrations"
+
+// migrationLockKey is the Postgres advisory lock held while migrating, so
+// replicas starting together apply each migration once. SQLite databases
+// are local to one process and go without.
+const migrationLockKey = 4_207_311_958
+
+// ErrSchemaOutdated is returned by CheckSchema when migrations are pending.
+var ErrSchemaOutdated = errors.New("database schema is out of date")
+
+// Migration is a pair of migrations/<dialect>/<version>_<name>.up.sql and
+// .down.sql files. Each runs in a transaction.
+type Migration struct {
+	Version int64
+	Name    string
+	up      string
+	down    string
+}
+
+// MigrationState is a migration and when it was applied, if it was.
+// Versions applied by a newer release are listed with Unknown set.
+type MigrationState struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	Unknown   bool
+}
+
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrations lists the embedded migrations of a gorm dialect ("postgres"
+// or "sqlite") by version.
+func Migrations(dialect string) ([]Migration, error) {
+	migrations, err := parseMigrations(migrationFiles, path.Join("migrations", dialect))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("migrations: no migrations for %s", dialect)
+	}
+	return migrations, err
+}
+
+func parseMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		base, direction, ok := cutDirection(name)
+		if !ok {
+			return nil, fmt.Errorf("migrations: %s: want <version>_<name>.up.sql or .down.sql", name)
+		}
+		versionText, title, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionText, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrations: %s: invalid version", name)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		} else if m.Name != title {
+			return nil, fmt.Errorf("migrations: version %d is used by %q and %q", version, m.Name, title)
+		}
+		if direction == "up" {
+			m.up = string(data)
+		} else {
+			m.down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migrations: version %d needs both an up and a down file", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func cutDirection(name string) (base, direction string, ok bool) {
+	for _, direction := range []string{"up", "down"} {
+		if base, ok := strings.CutSuffix(name, "."+direction+".sql"); ok {
+			return base, direction, true
+		}
+	}
+	return "", "", false
+}
+
+// Migrate applies the pending migrations in order and returns them.
+func Migrate(ctx context.Context, db *gorm.DB) ([]Migration, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	err = withMigrationLock(ctx, db, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range pendingMigrations(migrations, applied) {
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(m.up).Error; err != nil {
+					return err
+				}
+				return tx.Table(migrationsTable).Create(&appliedMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Rollback reverts the last steps applied migrations, newest first, and
+// returns them.
+func Rollback(ctx context.Context, db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+
+	var done []Migration
+	err = withMigrationLock(ctx, db, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+			m, ok := known[applied[i].Version]
+			if !ok {
+				return fmt.Errorf("migration %d was applied by a newer release; roll back with that release", applied[i].Version)
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(m.down).Error; err != nil {
+					return err
+				}
+				return tx.Table(migrationsTable).Where("version = ?", m.Version).Delete(nil).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrationStatus lists the known migrations and the versions applied by
+// newer releases, by version.
+func MigrationStatus(ctx context.Context, db *gorm.DB) ([]MigrationState, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int64]time.Time, len(applied))
+	for _, a := range applied {
+		appliedAt[a.Version] = a.AppliedAt
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Version: m.Version, Name: m.Name}
+		if at, ok := appliedAt[m.Version]; ok {
+			state.AppliedAt = &at
+			delete(appliedAt, m.Version)
+		}
+		states = append(states, state)
+	}
+	for _, a := range applied {
+		if _, unknown := appliedAt[a.Version]; unknown {
+			at := a.AppliedAt
+			states = append(states, MigrationState{Version: a.Version, Name: a.Name, AppliedAt: &at, Unknown: true})
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, nil
+}
+
+// CheckSchema returns ErrSchemaOutdated when migrations of this release
+// are pending. A schema migrated by a newer release passes, so that old
+// replicas keep serving during a rolling deploy.
+func CheckSchema(ctx context.Context, db *gorm.DB) error {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	applied, err := appliedMigrations(db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	pending := pendingMigrations(migrations, applied)
+	if len(pending) == 0 {
+		return nil
+	}
+	versions := make([]string, len(pending))
+	for i, m := range pending {
+		versions[i] = fmt.Sprintf("%d_%s", m.Version, m.Name)
+	}
+	return fmt.Errorf("%w: pending %s", ErrSchemaOutdated, strings.Join(versions, ", "))
+}
+
+func pendingMigrations(migrations []Migration, applied []appliedMigration) []Migration {
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if !done[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// appliedMigrations reads schema_migrations by version; a database that
+// was never migrated has none.
+func appliedMigrations(db *gorm.DB) ([]appliedMigration, error) {
+	if !db.Migrator().HasTable(migrationsTable) {
+		return nil, nil
+	}
+	var applied []appliedMigration
+	err := db.Table(migrationsTable).Order("version").Find(&applied).Error
+	return applied, err
+}
+
+// withMigrationLock runs fn on a single connection holding the migration
+// lock, creating schema_migrations first if needed.
+func withMigrationLock(ctx context.Context, db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if conn.Dialector.Name() == config.DriverPostgres {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+				return fmt.Errorf("migration lock: %w", err)
+			}
+			// Unlock even when ctx is done: the connection goes back to
+			// the pool and would keep the lock.
+			defer conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+		}
+
+		// SQLite drivers only read back times of columns declared datetime.
+		timeType := "timestamptz"
+		if conn.Dialector.Name() == config.DriverSQLite {
+			timeType = "datetime"
+		}
+		err := conn.Exec(`CREATE TABLE IF NOT EXISTS ` + migrationsTable + ` (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at ` + timeType + ` NOT NULL
+		)`).Error
+		if err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aldisaputra17/book-store/config"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	postgres, err := Migrations(config.DriverPostgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(postgres) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range postgres {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s: versions must follow each other from 1", m.Version, m.Name)
+		}
+	}
+	if postgres[0].Name != "create_schema" {
+		t.Errorf("first migration is %q", postgres[0].Name)
+	}
+
+	sqlite, err := Migrations(config.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sqlite) != len(postgres) {
+		t.Fatalf("%d sqlite migrations for %d postgres ones", len(sqlite), len(postgres))
+	}
+	for i := range postgres {
+		if sqlite[i].Version != postgres[i].Version || sqlite[i].Name != postgres[i].Name {
+			t.Errorf("sqlite migration %d_%s, postgres %d_%s", sqlite[i].Version, sqlite[i].Name, postgres[i].Version, postgres[i].Name)
+		}
+	}
+	if _, err := Migrations("mysql"); err == nil {
+		t.Error("migrations of an unknown dialect")
+	}
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default().Database
+	cfg.Driver = config.DriverSQLite
+	cfg.URL = ":memory:"
+	db, err := ConnectionDB(ctx, cfg, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer CloseDatabaseConnection(db)
+
+	if err := CheckSchema(ctx, db); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("CheckSchema before migrating = %v", err)
+	}
+	done, err := Migrate(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckSchema(ctx, db); err != nil {
+		t.Fatalf("CheckSchema after migrating = %v", err)
+	}
+	states, err := MigrationStatus(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range states {
+		if s.AppliedAt == nil {
+			t.Errorf("migration %d_%s not applied", s.Version, s.Name)
+		}
+	}
+
+	undone, err := Rollback(ctx, db, len(done))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(undone) != len(done) || db.Migrator().HasTable("books") {
+		t.Errorf("rolled back %d of %d migrations", len(undone), len(done))
+	}
+}
+
+func TestParseMigrationsRejectsBrokenSets(t *testing.T) {
+	sql := &fstest.MapFile{Data: []byte("SELECT 1;")}
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		err   string
+	}{
+		{"missing down", fstest.MapFS{"m/0001_a.up.sql": sql}, "needs both"},
+		{"duplicate version", fstest.MapFS{"m/0001_a.up.sql": sql, "m/0001_a.down.sql": sql, "m/0001_b.up.sql": sql}, "used by"},
+		{"no direction", fstest.MapFS{"m/0001_a.sql": sql}, "want <version>"},
+		{"no version", fstest.MapFS{"m/init.up.sql": sql}, "invalid version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseMigrations(tt.files, "m")
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseMigrationsSortsByVersion(t *testing.T) {
+	sql := &fstest.MapFile{Data: []byte("SELECT 1;")}
+	migrations, err := parseMigrations(fstest.MapFS{
+		"m/0010_later.up.sql": sql, "m/0010_later.down.sql": sql,
+		"m/0002_sooner.up.sql": sql, "m/0002_sooner.down.sql": sql,
+	}, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 2 || migrations[1].Name != "later" {
+		t.Errorf("migrations %+v", migrations)
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+	pending := pendingMigrations(migrations, []appliedMigration{{Version: 1}, {Version: 3}, {Version: 4}})
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Errorf("pending %+v", pending)
+	}
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS author_books;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS authors;
+DROP TABLE IF EXISTS books;
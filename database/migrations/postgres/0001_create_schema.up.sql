@@ -0,0 +1,32 @@
+-- The schema AutoMigrate used to create; IF NOT EXISTS adopts databases
+-- it already set up.
+CREATE TABLE IF NOT EXISTS books (
+    id text PRIMARY KEY,
+    title varchar(255),
+    published_year timestamptz,
+    isbn text
+);
+
+CREATE TABLE IF NOT EXISTS authors (
+    id text PRIMARY KEY,
+    name varchar(255),
+    country text
+);
+
+CREATE TABLE IF NOT EXISTS author_books (
+    book_id text,
+    author_id text,
+    PRIMARY KEY (book_id, author_id),
+    CONSTRAINT fk_author_books_book FOREIGN KEY (book_id) REFERENCES books (id),
+    CONSTRAINT fk_author_books_author FOREIGN KEY (author_id) REFERENCES authors (id)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id text PRIMARY KEY,
+    email varchar(255),
+    password text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
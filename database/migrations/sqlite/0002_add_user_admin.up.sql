@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN admin boolean NOT NULL DEFAULT false;
@@ -0,0 +1,74 @@
+package dto
+
+import "github.com/aldisaputra17/book-store/helper"
+
+const (
+	BulkModeAtomic     = "atomic"
+	BulkModeBestEffort = "best_effort"
+)
+
+type BulkCreateBookRequest struct {
+	Mode  string              `json:"mode" form:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Items []CreateBookRequest `json:"items" form:"items" binding:"required,min=1,max=1000"`
+}
+
+type BulkUpdateBookRequest struct {
+	Mode  string              `json:"mode" form:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Items []UpdateBookRequest `json:"items" form:"items" binding:"required,min=1,max=1000"`
+}
+
+type BulkCreateAuthorRequest struct {
+	Mode  string                `json:"mode" form:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Items []CreateAuthorRequest `json:"items" form:"items" binding:"required,min=1,max=1000"`
+}
+
+type BulkUpdateAuthorRequest struct {
+	Mode  string                `json:"mode" form:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Items []UpdateAuthorRequest `json:"items" form:"items" binding:"required,min=1,max=1000"`
+}
+
+type BulkDeleteRequest struct {
+	Mode string   `json:"mode" form:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	IDs  []string `json:"ids" form:"ids" binding:"required,min=1,max=1000"`
+}
+
+type BulkItemResult struct {
+	Index   int                 `json:"index"`
+	ID      string              `json:"id,omitempty"`
+	Success bool                `json:"success"`
+	Error   string              `json:"error,omitempty"`
+	Fields  []helper.FieldError `json:"fields,omitempty"`
+	Data    interface{}         `json:"data,omitempty"`
+}
+
+type BulkResponse struct {
+	Mode      string           `json:"mode"`
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// BulkMode returns the requested mode, defaulting to atomic.
+func BulkMode(mode string) string {
+	if mode == BulkModeBestEffort {
+		return BulkModeBestEffort
+	}
+	return BulkModeAtomic
+}
+
+func NewBulkResponse(mode string, results []BulkItemResult) *BulkResponse {
+	res := &BulkResponse{
+		Mode:    mode,
+		Total:   len(results),
+		Results: results,
+	}
+	for _, result := range results {
+		if result.Success {
+			res.Succeeded++
+		} else {
+			res.Failed++
+		}
+	}
+	return res
+}
@@ -0,0 +1,18 @@
+package dto
+
+type ImportRowResult struct {
+	Row     int         `json:"row"`
+	ID      string      `json:"id,omitempty"`
+	Success bool        `json:"success"`
+	Error   string      `json:"error,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type ImportResponse struct {
+	DryRun         bool              `json:"dry_run"`
+	Total          int               `json:"total"`
+	Imported       int               `json:"imported"`
+	Failed         int               `json:"failed"`
+	AuthorsCreated []*AuthorResponse `json:"authors_created"`
+	Results        []ImportRowResult `json:"results"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+// PathID binds the :id segment of routes like /api/book/:id.
+type PathID struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/apperror"
+)
+
+func TestRegisterAndLogin(t *testing.T) {
+	for _, prefix := range apiPrefixes {
+		t.Run(prefix, func(t *testing.T) {
+			h := New(t, "testdata")
+			anonymous := h.Anonymous()
+			credentials := map[string]string{"email": "new@example.com", "password": "new-secret"}
+
+			var user struct {
+				Email string `json:"email"`
+				Token string `json:"token"`
+			}
+			anonymous.Post(prefix+"/user/register", credentials).Expect(http.StatusCreated).Data(&user)
+			if user.Email != credentials["email"] || user.Token == "" {
+				t.Fatalf("registered %+v", user)
+			}
+			// The token of the registration is valid at once, but only an
+			// administrator can write.
+			registered := h.WithToken(user.Token).Post(prefix+"/author", map[string]string{"name": "Iain M. Banks", "country": "GB"})
+			if code := registered.Expect(http.StatusForbidden).ErrorCode(); code != apperror.CodeForbidden {
+				t.Errorf("write by a registered user: code %s", code)
+			}
+
+			if code := anonymous.Post(prefix+"/user/register", credentials).Expect(http.StatusConflict).ErrorCode(); code != apperror.CodeEmailTaken {
+				t.Errorf("duplicate registration: code %s", code)
+			}
+			short := map[string]string{"email": "short@example.com", "password": "123"}
+			if code := anonymous.Post(prefix+"/user/register", short).Expect(http.StatusUnprocessableEntity).ErrorCode(); code != apperror.CodeValidation {
+				t.Errorf("short password: code %s", code)
+			}
+
+			anonymous.Post(prefix+"/user/login", credentials).Expect(http.StatusOK).Data(&user)
+			if user.Token == "" {
+				t.Error("login returned no token")
+			}
+			for _, wrong := range []map[string]string{
+				{"email": "new@example.com", "password": "wrong-secret"},
+				{"email": "nobody@example.com", "password": "new-secret"},
+			} {
+				if code := anonymous.Post(prefix+"/user/login", wrong).Expect(http.StatusUnauthorized).ErrorCode(); code != apperror.CodeInvalidCredentials {
+					t.Errorf("login as %v: code %s", wrong, code)
+				}
+			}
+			if code := anonymous.Post(prefix+"/user/login", map[string]string{}).Expect(http.StatusUnprocessableEntity).ErrorCode(); code != apperror.CodeValidation {
+				t.Errorf("empty login: code %s", code)
+			}
+		})
+	}
+}
+
+func TestFixtureUsersCanLogIn(t *testing.T) {
+	h := New(t, "testdata")
+	for _, user := range h.Fixtures.Users {
+		if h.LoginAs(user.Email).token == "" {
+			t.Errorf("%s got no token", user.Email)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+)
+
+func TestAuthorCRUD(t *testing.T) {
+	for _, prefix := range apiPrefixes {
+		t.Run(prefix, func(t *testing.T) {
+			h := New(t, "testdata")
+			admin := h.LoginAs("admin@example.com")
+			reader := h.LoginAs("reader@example.com")
+			anonymous := h.Anonymous()
+
+			if code := reader.Post(prefix+"/author", dto.CreateAuthorRequest{Name: "Octavia E. Butler", Country: "US"}).
+				Expect(http.StatusForbidden).ErrorCode(); code != apperror.CodeForbidden {
+				t.Errorf("create by a reader: code %s", code)
+			}
+			var created dto.AuthorResponse
+			admin.Post(prefix+"/author", dto.CreateAuthorRequest{Name: "Octavia E. Butler", Country: "US"}).
+				Expect(http.StatusCreated).Data(&created)
+			if created.ID == "" || created.Name != "Octavia E. Butler" {
+				t.Fatalf("created %+v", created)
+			}
+
+			admin.Put(prefix+"/author", map[string]string{"id": created.ID, "country": "USA"}).Expect(http.StatusOK)
+			var author dto.ReadAuthorResponse
+			anonymous.Get(prefix + "/author/" + created.ID).Expect(http.StatusOK).Data(&author)
+			if author.Name != "Octavia E. Butler" || author.Country != "USA" {
+				t.Errorf("updated %+v", author)
+			}
+
+			admin.Delete(prefix+"/author/"+created.ID, nil).Expect(http.StatusOK)
+			if code := anonymous.Get(prefix + "/author/" + created.ID).Expect(http.StatusNotFound).ErrorCode(); code != apperror.CodeAuthorNotFound {
+				t.Errorf("deleted author: code %s", code)
+			}
+		})
+	}
+}
+
+func TestAuthorErrors(t *testing.T) {
+	for _, prefix := range apiPrefixes {
+		t.Run(prefix, func(t *testing.T) {
+			h := New(t, "testdata")
+			admin := h.LoginAs("admin@example.com")
+			anonymous := h.Anonymous()
+
+			cases := []struct {
+				name   string
+				res    *Response
+				status int
+				code   string
+			}{
+				{"find missing", anonymous.Get(prefix + "/author/" + missingID), http.StatusNotFound, apperror.CodeAuthorNotFound},
+				{"find invalid id", anonymous.Get(prefix + "/author/42"), http.StatusBadRequest, apperror.CodeInvalidID},
+				{"delete invalid id", admin.Delete(prefix+"/author/42", nil), http.StatusBadRequest, apperror.CodeInvalidID},
+				{"update missing", admin.Put(prefix+"/author", map[string]string{"id": missingID, "name": "Nobody"}), http.StatusNotFound, apperror.CodeAuthorNotFound},
+				{"create without country", admin.Post(prefix+"/author", map[string]string{"name": "Nobody"}), http.StatusUnprocessableEntity, apperror.CodeValidation},
+			}
+			for _, c := range cases {
+				if c.res.Code != c.status {
+					t.Errorf("%s: status %d, want %d: %s", c.name, c.res.Code, c.status, c.res.Body)
+					continue
+				}
+				if code := c.res.ErrorCode(); code != c.code {
+					t.Errorf("%s: code %s, want %s", c.name, code, c.code)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorList(t *testing.T) {
+	for _, prefix := range apiPrefixes {
+		t.Run(prefix, func(t *testing.T) {
+			h := New(t, "testdata")
+
+			var page struct {
+				Data  []dto.ReadAuthorResponse `json:"data"`
+				Total entities.Pagination      `json:"total"`
+			}
+			h.Anonymous().Get(prefix + "/author?book_id=" + goodOmensID + "&page_size=1&page=2").Expect(http.StatusOK).Decode(&page)
+			if len(page.Data) != 1 || page.Total.TotalRecords != 2 || page.Total.CurrentPage != 2 {
+				t.Errorf("second page %d authors, pagination %+v", len(page.Data), page.Total)
+			}
+			if books := page.Data[0].Book; len(books) != 1 || books[0].ID != goodOmensID {
+				t.Errorf("books of %s %+v", page.Data[0].Name, books)
+			}
+			h.Anonymous().Get(prefix + "/author?title=gOoD%20OmEnS").Expect(http.StatusOK).Decode(&page)
+			ids := make([]string, len(page.Data))
+			for i, author := range page.Data {
+				ids[i] = author.ID
+			}
+			if !sameIDs(ids, pratchettID, gaimanID) {
+				t.Errorf("authors of Good Omens %v", ids)
+			}
+		})
+	}
+}
+
+func TestAuthorBulk(t *testing.T) {
+	for _, prefix := range apiPrefixes {
+		t.Run(prefix, func(t *testing.T) {
+			h := New(t, "testdata")
+			admin := h.LoginAs("admin@example.com")
+
+			var res dto.BulkResponse
+			admin.Post(prefix+"/author/bulk", dto.BulkCreateAuthorRequest{Items: []dto.CreateAuthorRequest{
+				{Name: "Ann Leckie", Country: "US"},
+				{Name: "China Miéville", Country: "GB"},
+			}}).Expect(http.StatusCreated).Data(&res)
+			if res.Succeeded != 2 || res.Results[0].ID == "" {
+				t.Fatalf("bulk create %+v", res)
+			}
+			ids := []string{res.Results[0].ID, res.Results[1].ID}
+
+			admin.Put(prefix+"/author/bulk", map[string]interface{}{
+				"items": []map[string]string{
+					{"id": ids[0], "country": "USA"},
+					{"id": ids[1], "country": "UK"},
+				},
+			}).Expect(http.StatusOK).Data(&res)
+			if res.Succeeded != 2 {
+				t.Errorf("bulk update %+v", res)
+			}
+
+			admin.Delete(prefix+"/author/bulk", dto.BulkDeleteRequest{Mode: dto.BulkModeBestEffort, IDs: []string{ids[0], ids[1], missingID}}).
+				Expect(http.StatusMultiStatus).Data(&res)
+			if res.Succeeded != 2 || res.Failed != 1 {
+				t.Errorf("partial bulk delete %+v", res)
+			}
+			for _, id := range ids {
+				h.Anonymous().Get(prefix + "/author/" + id).Expect(http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestAuthorRenameReachesCachedBooks(t *testing.T) {
+	h := New(t, "testdata")
+	var page struct {
+		Data []dto.ReadBookResponse `json:"data"`
+	}
+	h.Anonymous().Get("/api/v1/book?author_id=" + pratchettID).Expect(http.StatusOK).Decode(&page)
+
+	h.LoginAs("admin@example.com").Put("/api/v1/author", map[string]string{"id": pratchettID, "name": "Sir Terry Pratchett"}).
+		Expect(http.StatusOK)
+	h.Anonymous().Get("/api/v1/book?author_id=" + pratchettID).Expect(http.StatusOK).Decode(&page)
+	names := map[string]bool{}
+	for _, author := range page.Data[0].Author {
+		names[author.Name] = true
+	}
+	if !names["Sir Terry Pratchett"] || names["Terry Pratchett"] {
+		t.Errorf("authors of %s after the rename %v", page.Data[0].Title, names)
+	}
+}
@@ -0,0 +1,253 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/csv"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+)
+
+func TestBookCRUD(t *testing.T) {
+	for _, prefix := range apiPrefixes {
+		t.Run(prefix, func(t *testing.T) {
+			h := New(t, "testdata")
+			admin := h.LoginAs("admin@example.com")
+			anonymous := h.Anonymous()
+
+			var created dto.CreateBookResponse
+			admin.Post(prefix+"/book", dto.CreateBookRequest{Title: "Lathe of Heaven", AuthorID: []string{leGuinID}}).
+				Expect(http.StatusCreated).Data(&created)
+			if created.ID == "" || created.Title != "Lathe of Heaven" {
+				t.Fatalf("created %+v", created)
+			}
+
+			var book dto.ReadBookResponse
+			anonymous.Get(prefix + "/book/" + created.ID).Expect(http.StatusOK).Data(&book)
+			if book.Title != "Lathe of Heaven" {
+				t.Fatalf("read %+v", book)
+			}
+			var page struct {
+				Data []dto.ReadBookResponse `json:"data"`
+			}
+			anonymous.Get(prefix + "/book?author_id=" + leGuinID).Expect(http.StatusOK).Decode(&page)
+			if got := bookIDs(page.Data); !sameIDs(got, dispossessedID, leftHandID, created.ID) {
+				t.Errorf("books of Le Guin %v", got)
+			}
+
+			admin.Put(prefix+"/book", map[string]string{"id": created.ID, "title": "The Lathe of Heaven"}).Expect(http.StatusOK)
+			anonymous.Get(prefix + "/book/" + created.ID).Expect(http.StatusOK).Data(&book)
+			if book.Title != "The Lathe of Heaven" {
+				t.Errorf("updated title %q", book.Title)
+			}
+
+			admin.Delete(prefix+"/book/"+created.ID, nil).Expect(http.StatusOK)
+			if code := anonymous.Get(prefix + "/book/" + created.ID).Expect(http.StatusNotFound).ErrorCode(); code != apperror.CodeBookNotFound {
+				t.Errorf("deleted book: code %s", code)
+			}
+		})
+	}
+}
+
+func TestBookErrors(t *testing.T) {
+	for _, prefix := range apiPrefixes {
+		t.Run(prefix, func(t *testing.T) {
+			h := New(t, "testdata")
+			admin := h.LoginAs("admin@example.com")
+			anonymous := h.Anonymous()
+
+			cases := []struct {
+				name   string
+				res    *Response
+				status int
+				code   string
+			}{
+				{"find missing", anonymous.Get(prefix + "/book/" + missingID), http.StatusNotFound, apperror.CodeBookNotFound},
+				{"find invalid id", anonymous.Get(prefix + "/book/42"), http.StatusBadRequest, apperror.CodeInvalidID},
+				{"delete invalid id", admin.Delete(prefix+"/book/42", nil), http.StatusBadRequest, apperror.CodeInvalidID},
+				{"update missing", admin.Put(prefix+"/book", map[string]string{"id": missingID, "title": "Nothing"}), http.StatusNotFound, apperror.CodeBookNotFound},
+				{"create without title", admin.Post(prefix+"/book", map[string][]string{"author_id": {leGuinID}}), http.StatusUnprocessableEntity, apperror.CodeValidation},
+				{"create by unknown author", admin.Post(prefix+"/book", dto.CreateBookRequest{Title: "Orphan", AuthorID: []string{missingID}}), http.StatusUnprocessableEntity, apperror.CodeUnknownReference},
+			}
+			for _, c := range cases {
+				if c.res.Code != c.status {
+					t.Errorf("%s: status %d, want %d: %s", c.name, c.res.Code, c.status, c.res.Body)
+					continue
+				}
+				if code := c.res.ErrorCode(); code != c.code {
+					t.Errorf("%s: code %s, want %s", c.name, code, c.code)
+				}
+			}
+		})
+	}
+}
+
+func TestBookList(t *testing.T) {
+	for _, prefix := range apiPrefixes {
+		t.Run(prefix, func(t *testing.T) {
+			h := New(t, "testdata")
+			anonymous := h.Anonymous()
+
+			var page struct {
+				Data  []dto.ReadBookResponse `json:"data"`
+				Total entities.Pagination    `json:"total"`
+			}
+			anonymous.Get(prefix + "/book?page_size=2").Expect(http.StatusOK).Decode(&page)
+			if len(page.Data) != 2 || page.Total.TotalPages < 2 || page.Total.PageSize != 2 {
+				t.Errorf("first page %d books, pagination %+v", len(page.Data), page.Total)
+			}
+
+			anonymous.Get(prefix + "/book?author_id=" + leGuinID).Expect(http.StatusOK).Decode(&page)
+			if got := bookIDs(page.Data); !sameIDs(got, dispossessedID, leftHandID) {
+				t.Errorf("books of Le Guin %v", got)
+			}
+			// name searches the names of the authors.
+			anonymous.Get(prefix + "/book?name=pRaTcH").Expect(http.StatusOK).Decode(&page)
+			if got := bookIDs(page.Data); !sameIDs(got, goodOmensID) || len(page.Data[0].Author) != 2 {
+				t.Errorf("books by pratch %+v", page.Data)
+			}
+			anonymous.Get(prefix + "/book?name=no-such-book").Expect(http.StatusOK).Decode(&page)
+			if len(page.Data) != 0 || page.Total.TotalRecords != 0 {
+				t.Errorf("search without match returned %d books", len(page.Data))
+			}
+		})
+	}
+}
+
+func TestBookBulk(t *testing.T) {
+	for _, prefix := range apiPrefixes {
+		t.Run(prefix, func(t *testing.T) {
+			h := New(t, "testdata")
+			admin := h.LoginAs("admin@example.com")
+
+			var res dto.BulkResponse
+			admin.Post(prefix+"/book/bulk", dto.BulkCreateBookRequest{Items: []dto.CreateBookRequest{
+				{Title: "Mort", AuthorID: []string{pratchettID}},
+				{Title: "Coraline", AuthorID: []string{gaimanID}},
+			}}).Expect(http.StatusCreated).Data(&res)
+			if res.Succeeded != 2 || res.Results[0].ID == "" {
+				t.Fatalf("bulk create %+v", res)
+			}
+			ids := []string{res.Results[0].ID, res.Results[1].ID}
+
+			admin.Post(prefix+"/book/bulk", dto.BulkCreateBookRequest{Items: []dto.CreateBookRequest{
+				{Title: "Sourcery", AuthorID: []string{pratchettID}},
+				{Title: "Orphan", AuthorID: []string{missingID}},
+			}}).Expect(http.StatusBadRequest).Data(&res)
+			if res.Mode != dto.BulkModeAtomic || res.Succeeded != 0 || res.Failed == 0 {
+				t.Errorf("rolled back bulk create %+v", res)
+			}
+
+			admin.Put(prefix+"/book/bulk", map[string]interface{}{
+				"mode": dto.BulkModeBestEffort,
+				"items": []map[string]string{
+					{"id": ids[0], "title": "Mort (revised)"},
+					{"id": missingID, "title": "Nothing"},
+				},
+			}).Expect(http.StatusMultiStatus).Data(&res)
+			if res.Succeeded != 1 || res.Failed != 1 || !res.Results[0].Success {
+				t.Errorf("partial bulk update %+v", res)
+			}
+			var book dto.ReadBookResponse
+			h.Anonymous().Get(prefix + "/book/" + ids[0]).Expect(http.StatusOK).Data(&book)
+			if book.Title != "Mort (revised)" {
+				t.Errorf("bulk updated title %q", book.Title)
+			}
+
+			admin.Delete(prefix+"/book/bulk", dto.BulkDeleteRequest{IDs: ids}).Expect(http.StatusOK).Data(&res)
+			if res.Succeeded != 2 {
+				t.Errorf("bulk delete %+v", res)
+			}
+			for _, id := range ids {
+				h.Anonymous().Get(prefix + "/book/" + id).Expect(http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestBookExportImport(t *testing.T) {
+	for _, prefix := range apiPrefixes {
+		t.Run(prefix, func(t *testing.T) {
+			h := New(t, "testdata")
+			admin := h.LoginAs("admin@example.com")
+
+			res := h.Anonymous().Get(prefix + "/book/export?author_id=" + leGuinID).Expect(http.StatusOK)
+			if !strings.HasPrefix(res.Header.Get("Content-Type"), "text/csv") {
+				t.Errorf("export content type %q", res.Header.Get("Content-Type"))
+			}
+			rows, err := csv.NewReader(bytes.NewReader(res.Body)).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 3 || rows[0][0] != "id" {
+				t.Fatalf("export rows %v", rows)
+			}
+
+			body, contentType := csvUpload(t, "title,authors\nThe Word for World Is Forest,Ursula K. Le Guin\nNeverwhere,Neil Gaiman\n")
+			var imported dto.ImportResponse
+			admin.Post(prefix+"/book/import", body, Header("Content-Type", contentType)).
+				Expect(http.StatusCreated).Data(&imported)
+			if imported.Imported != 2 || len(imported.AuthorsCreated) != 0 {
+				t.Fatalf("import %+v", imported)
+			}
+
+			res = h.Anonymous().Get(prefix + "/book/export?author_id=" + leGuinID).Expect(http.StatusOK)
+			if rows, _ = csv.NewReader(bytes.NewReader(res.Body)).ReadAll(); len(rows) != 4 {
+				t.Errorf("export after import has %d rows", len(rows))
+			}
+
+			body, contentType = csvUpload(t, "name\nNo title column\n")
+			if code := admin.Post(prefix+"/book/import", body, Header("Content-Type", contentType)).Expect(http.StatusBadRequest).ErrorCode(); code == "" {
+				t.Error("import of an invalid file returned no error code")
+			}
+		})
+	}
+}
+
+// csvUpload returns a multipart form holding content as the file field.
+func csvUpload(t *testing.T, content string) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile("file", "books.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body, form.FormDataContentType()
+}
+
+func bookIDs(books []dto.ReadBookResponse) []string {
+	ids := make([]string, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+	return ids
+}
+
+// sameIDs reports whether got holds exactly want, in any order.
+func sameIDs(got []string, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, id := range got {
+		seen[id] = true
+	}
+	for _, id := range want {
+		if !seen[id] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package e2e
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	log "github.com/sirupsen/logrus"
+)
+
+// Ids of testdata.
+const (
+	leGuinID       = "9a1c0e52-7f4b-4d2e-8c61-0b3e5d7a9f11"
+	pratchettID    = "9a1c0e52-7f4b-4d2e-8c61-0b3e5d7a9f12"
+	gaimanID       = "9a1c0e52-7f4b-4d2e-8c61-0b3e5d7a9f13"
+	dispossessedID = "4d7e2b90-1c3a-4f5e-9b82-6a0c1d2e3f01"
+	leftHandID     = "4d7e2b90-1c3a-4f5e-9b82-6a0c1d2e3f02"
+	goodOmensID    = "4d7e2b90-1c3a-4f5e-9b82-6a0c1d2e3f03"
+
+	missingID = "00000000-0000-4000-8000-000000000000"
+)
+
+// apiPrefixes are the versioned API and its unversioned alias, which
+// serve the same routes.
+var apiPrefixes = []string{"/api/v1", "/api"}
+
+// TestMain fails a full run of the package when a registered route has no
+// test.
+func TestMain(m *testing.M) {
+	flag.Parse()
+	log.SetOutput(io.Discard)
+	code := m.Run()
+	if code == 0 && flag.Lookup("test.run").Value.String() == "" {
+		if missing := Uncovered(); len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "routes without an end-to-end test:\n\t%s\n", strings.Join(missing, "\n\t"))
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	h := New(t, "testdata")
+	routes := []struct{ method, path string }{
+		{http.MethodPost, "/book"},
+		{http.MethodPut, "/book"},
+		{http.MethodDelete, "/book/" + dispossessedID},
+		{http.MethodPost, "/book/import"},
+		{http.MethodPost, "/book/bulk"},
+		{http.MethodPut, "/book/bulk"},
+		{http.MethodDelete, "/book/bulk"},
+		{http.MethodPost, "/author"},
+		{http.MethodPut, "/author"},
+		{http.MethodDelete, "/author/" + leGuinID},
+		{http.MethodPost, "/author/bulk"},
+		{http.MethodPut, "/author/bulk"},
+		{http.MethodDelete, "/author/bulk"},
+	}
+	for _, prefix := range apiPrefixes {
+		for _, route := range routes {
+			path := prefix + route.path
+			if code := h.Anonymous().Do(route.method, path, "{}").Expect(http.StatusUnauthorized).ErrorCode(); code != apperror.CodeMissingToken {
+				t.Errorf("%s %s without token: code %s", route.method, path, code)
+			}
+			if code := h.WithToken("not-a-jwt").Do(route.method, path, "{}").Expect(http.StatusUnauthorized).ErrorCode(); code != apperror.CodeInvalidToken {
+				t.Errorf("%s %s with an invalid token: code %s", route.method, path, code)
+			}
+		}
+	}
+
+	// Nothing was deleted.
+	h.Anonymous().Get("/api/v1/book/" + dispossessedID).Expect(http.StatusOK)
+	h.Anonymous().Get("/api/v1/author/" + leGuinID).Expect(http.StatusOK)
+}
+
+func TestDeprecatedAliasIsAnnounced(t *testing.T) {
+	h := New(t, "testdata")
+	res := h.Anonymous().Get("/api/book/" + dispossessedID).Expect(http.StatusOK)
+	if res.Header.Get("Deprecation") == "" || !strings.Contains(res.Header.Get("Link"), "/api/v1") {
+		t.Errorf("alias headers %v", res.Header)
+	}
+	if res := h.Anonymous().Get("/api/v1/book/" + dispossessedID).Expect(http.StatusOK); res.Header.Get("Deprecation") != "" {
+		t.Errorf("v1 announced deprecated: %v", res.Header)
+	}
+}
@@ -0,0 +1,347 @@
+// Package e2e tests the HTTP API end to end: the whole router of the app
+// package, on an isolated and migrated database seeded from YAML
+// fixtures, driven through helpers that log in and send authenticated
+// requests.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/app"
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/database"
+	"github.com/aldisaputra17/book-store/fixtures"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PostgresURLEnv names the variable pointing the harness at a Postgres
+// server, where every test gets a schema of its own. Without it every
+// test gets an in-memory SQLite database.
+const PostgresURLEnv = "E2E_DATABASE_URL"
+
+// Harness is the service of one test, with its database.
+type Harness struct {
+	t       *testing.T
+	handler http.Handler
+	DB      *gorm.DB
+	// Fixtures is the seeded data, including the plain passwords of the
+	// users.
+	Fixtures *fixtures.Set
+}
+
+// New boots the service on a new database holding the fixtures of dir.
+// The database is dropped when the test ends.
+func New(t *testing.T, dir string) *Harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	set, err := fixtures.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := openDatabase(t)
+	if _, err := database.Migrate(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	_, err = fixtures.Seed(ctx, fixtures.Repositories{
+		Books:   repositories.NewBookRepository(db),
+		Authors: repositories.NewAuthorRepository(db),
+		Users:   repositories.NewUserRepository(db),
+	}, set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Env = config.EnvTest
+	a, err := app.NewWithDB(cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coverage.register(a.Handler().(*gin.Engine).Routes())
+	return &Harness{t: t, handler: a.Handler(), DB: db, Fixtures: set}
+}
+
+// openDatabase returns an empty database for the test.
+func openDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+	cfg := config.Default().Database
+	cfg.ConnectRetry = 5 * time.Second
+	postgresURL := os.Getenv(PostgresURLEnv)
+	if postgresURL == "" {
+		cfg.Driver, cfg.URL = config.DriverSQLite, ":memory:"
+		return connect(t, cfg)
+	}
+
+	cfg.URL = postgresURL
+	admin := connect(t, cfg)
+	schema := "e2e_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("drop schema %s: %v", schema, err)
+		}
+	})
+	cfg.URL = withSearchPath(postgresURL, schema)
+	return connect(t, cfg)
+}
+
+func connect(t *testing.T, cfg config.DatabaseConfig) *gorm.DB {
+	t.Helper()
+	db, err := database.ConnectionDB(context.Background(), cfg, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.CloseDatabaseConnection(db) })
+	return db
+}
+
+// withSearchPath sets the schema of a postgres:// URL or key=value DSN.
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return dsn + " search_path=" + schema
+}
+
+// Anonymous returns a client sending no token.
+func (h *Harness) Anonymous() *Client {
+	return &Client{h: h}
+}
+
+// WithToken returns a client sending token as is in Authorization.
+func (h *Harness) WithToken(token string) *Client {
+	return &Client{h: h, token: token}
+}
+
+// Login logs in through the API and returns a client sending the token
+// it got.
+func (h *Harness) Login(email, password string) *Client {
+	h.t.Helper()
+	var user struct {
+		Token string `json:"token"`
+	}
+	h.Anonymous().Post("/api/v1/user/login", map[string]string{"email": email, "password": password}).
+		Expect(http.StatusOK).
+		Data(&user)
+	return h.WithToken(user.Token)
+}
+
+// LoginAs logs in as the fixture user with email.
+func (h *Harness) LoginAs(email string) *Client {
+	h.t.Helper()
+	for _, user := range h.Fixtures.Users {
+		if user.Email == email {
+			return h.Login(user.Email, user.Password)
+		}
+	}
+	h.t.Fatalf("no fixture user %s", email)
+	return nil
+}
+
+// Client sends requests to the service of a harness.
+type Client struct {
+	h     *Harness
+	token string
+}
+
+// RequestOption changes a request before it is sent.
+type RequestOption func(r *http.Request)
+
+// Header sets a request header.
+func Header(key, value string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set(key, value)
+	}
+}
+
+// Do sends a request. A body other than nil, an io.Reader, a string or a
+// []byte is sent as JSON; the others are sent as they are, declared JSON
+// unless an option sets another Content-Type.
+func (c *Client) Do(method, path string, body interface{}, opts ...RequestOption) *Response {
+	c.h.t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	case string:
+		reader = strings.NewReader(b)
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			c.h.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", c.token)
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	w := httptest.NewRecorder()
+	c.h.handler.ServeHTTP(w, req)
+	coverage.hit(method, req.URL.Path)
+	return &Response{t: c.h.t, request: method + " " + path, Code: w.Code, Header: w.Header(), Body: w.Body.Bytes()}
+}
+
+func (c *Client) Get(path string, opts ...RequestOption) *Response {
+	c.h.t.Helper()
+	return c.Do(http.MethodGet, path, nil, opts...)
+}
+
+func (c *Client) Post(path string, body interface{}, opts ...RequestOption) *Response {
+	c.h.t.Helper()
+	return c.Do(http.MethodPost, path, body, opts...)
+}
+
+func (c *Client) Put(path string, body interface{}, opts ...RequestOption) *Response {
+	c.h.t.Helper()
+	return c.Do(http.MethodPut, path, body, opts...)
+}
+
+func (c *Client) Delete(path string, body interface{}, opts ...RequestOption) *Response {
+	c.h.t.Helper()
+	return c.Do(http.MethodDelete, path, body, opts...)
+}
+
+// Response is a recorded response.
+type Response struct {
+	t       *testing.T
+	request string
+	Code    int
+	Header  http.Header
+	Body    []byte
+}
+
+// Expect fails the test unless the status is code.
+func (r *Response) Expect(code int) *Response {
+	r.t.Helper()
+	if r.Code != code {
+		r.t.Fatalf("%s: status %d, want %d: %s", r.request, r.Code, code, r.Body)
+	}
+	return r
+}
+
+// Decode unmarshals the JSON body into v.
+func (r *Response) Decode(v interface{}) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("%s: %v: %s", r.request, err, r.Body)
+	}
+}
+
+// Data unmarshals the data of the response envelope into v.
+func (r *Response) Data(v interface{}) {
+	r.t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	r.Decode(&envelope)
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		r.t.Fatalf("%s: data: %v: %s", r.request, err, r.Body)
+	}
+}
+
+// ErrorCode is the apperror code of an error response.
+func (r *Response) ErrorCode() string {
+	r.t.Helper()
+	var envelope struct {
+		Code string `json:"code"`
+	}
+	r.Decode(&envelope)
+	return envelope.Code
+}
+
+// coverage records which registered routes the requests of the package
+// reached.
+var coverage = &routeCoverage{hits: map[string]bool{}}
+
+type routeCoverage struct {
+	mu     sync.Mutex
+	routes []gin.RouteInfo
+	hits   map[string]bool
+}
+
+func (c *routeCoverage) register(routes []gin.RouteInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.routes == nil {
+		c.routes = routes
+	}
+}
+
+// hit records the route serving path, preferring static segments over
+// parameters as gin does.
+func (c *routeCoverage) hit(method, path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	parts := strings.Split(path, "/")
+	best, bestStatic := "", -1
+	for _, route := range c.routes {
+		pattern := strings.Split(route.Path, "/")
+		if route.Method != method || len(pattern) != len(parts) {
+			continue
+		}
+		static, ok := 0, true
+		for i := range pattern {
+			switch {
+			case strings.HasPrefix(pattern[i], ":"):
+			case pattern[i] == parts[i]:
+				static++
+			default:
+				ok = false
+			}
+		}
+		if ok && static > bestStatic {
+			best, bestStatic = route.Method+" "+route.Path, static
+		}
+	}
+	if best != "" {
+		c.hits[best] = true
+	}
+}
+
+// Uncovered lists the registered routes no request of the package
+// reached, once a harness has been created.
+func Uncovered() []string {
+	coverage.mu.Lock()
+	defer coverage.mu.Unlock()
+	var missing []string
+	for _, route := range coverage.routes {
+		if key := route.Method + " " + route.Path; !coverage.hits[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
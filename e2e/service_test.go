@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHealthAndMetrics(t *testing.T) {
+	h := New(t, "testdata")
+	anonymous := h.Anonymous()
+	anonymous.Get("/healthz").Expect(http.StatusOK)
+	anonymous.Get("/readyz").Expect(http.StatusOK)
+
+	anonymous.Get("/api/v1/book/" + dispossessedID).Expect(http.StatusOK)
+	if res := anonymous.Get("/metrics").Expect(http.StatusOK); !strings.Contains(string(res.Body), "/api/v1/book/:id") {
+		t.Errorf("metrics miss the route just served:\n%s", res.Body)
+	}
+}
+
+func TestAPIDocumentation(t *testing.T) {
+	h := New(t, "testdata")
+	var spec struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	h.Anonymous().Get("/api/openapi.json").Expect(http.StatusOK).Decode(&spec)
+	if spec.OpenAPI == "" || spec.Paths["/api/v1/book/{id}"] == nil {
+		t.Errorf("spec %s with paths %v", spec.OpenAPI, len(spec.Paths))
+	}
+	page := string(h.Anonymous().Get("/api/docs").Expect(http.StatusOK).Body)
+	if !strings.Contains(page, "/api/openapi.json") || !strings.Contains(page, "/api/v1/book/{id}") {
+		t.Errorf("docs do not render the spec:\n%s", page)
+	}
+	if strings.Contains(page, "<script") {
+		t.Errorf("docs run a script:\n%s", page)
+	}
+}
+
+// graphQLResponse is the body of a GraphQL response.
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func TestGraphQLQueries(t *testing.T) {
+	h := New(t, "testdata")
+	query := `{ book(id: "` + goodOmensID + `") { title authors { name } } }`
+
+	var res graphQLResponse
+	h.Anonymous().Get("/graphql?query=" + url.QueryEscape(query)).Expect(http.StatusOK).Decode(&res)
+	if len(res.Errors) != 0 || !strings.Contains(string(res.Data["book"]), "Good Omens") {
+		t.Errorf("GET query: %+v", res)
+	}
+
+	var posted graphQLResponse
+	h.Anonymous().Post("/graphql", map[string]string{"query": `{ authors(title: "omens") { items { name } pagination { totalRecords } } }`}).
+		Expect(http.StatusOK).Decode(&posted)
+	if len(posted.Errors) != 0 || !strings.Contains(string(posted.Data["authors"]), `"totalRecords":2`) {
+		t.Errorf("POST query: %+v", posted)
+	}
+}
+
+func TestGraphQLMutationsRequireToken(t *testing.T) {
+	h := New(t, "testdata")
+	mutation := map[string]string{"query": `mutation { createAuthor(name: "Jo Walton", country: "CA") { id name } }`}
+
+	var res graphQLResponse
+	h.Anonymous().Post("/graphql", mutation).Expect(http.StatusOK).Decode(&res)
+	if len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, "unauthorized") {
+		t.Errorf("anonymous mutation: %+v", res)
+	}
+
+	var authorized graphQLResponse
+	h.LoginAs("admin@example.com").Post("/graphql", mutation).Expect(http.StatusOK).Decode(&authorized)
+	if len(authorized.Errors) != 0 || !strings.Contains(string(authorized.Data["createAuthor"]), "Jo Walton") {
+		t.Errorf("authenticated mutation: %+v", authorized)
+	}
+
+	h.LoginAs("admin@example.com").Get("/graphql?query=" + url.QueryEscape(mutation["query"])).Expect(http.StatusBadRequest)
+}
@@ -6,10 +6,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// User is an account. Only administrators can write books and authors.
 type User struct {
 	ID        uuid.UUID `gorm:"primaryKey" json:"id"`
 	Email     string    `gorm:"uniqueIndex;type:varchar(255)" json:"email" `
 	Password  string    `gorm:"->;<-;not null" json:"-" validate:"required, min=6"`
+	Admin     bool      `gorm:"not null;default:false" json:"admin"`
 	Token     string    `gorm:"-" json:"token,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
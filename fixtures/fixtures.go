@@ -0,0 +1,179 @@
+// Package fixtures loads sample data from YAML files and seeds it through
+// the repositories, for local environments and end-to-end tests.
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Set is the content of one or more fixture files. Books refer to their
+// authors by ID.
+type Set struct {
+	Authors []Author `yaml:"authors"`
+	Books   []Book   `yaml:"books"`
+	Users   []User   `yaml:"users"`
+}
+
+type Author struct {
+	ID      string `yaml:"id"`
+	Name    string `yaml:"name"`
+	Country string `yaml:"country"`
+}
+
+type Book struct {
+	ID            string    `yaml:"id"`
+	Title         string    `yaml:"title"`
+	PublishedYear time.Time `yaml:"published_year"`
+	Isbn          string    `yaml:"isbn"`
+	Authors       []string  `yaml:"authors"`
+}
+
+type User struct {
+	Email    string `yaml:"email"`
+	Password string `yaml:"password"`
+	Admin    bool   `yaml:"admin"`
+}
+
+// Load merges the .yaml and .yml files of dir in name order.
+func Load(dir string) (*Set, error) {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("fixtures: no .yaml files in %s", dir)
+	}
+	sort.Strings(paths)
+
+	set := &Set{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var file Set
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("fixtures: parse %s: %w", path, err)
+		}
+		set.Authors = append(set.Authors, file.Authors...)
+		set.Books = append(set.Books, file.Books...)
+		set.Users = append(set.Users, file.Users...)
+	}
+	return set, nil
+}
+
+// Repositories are the stores Seed writes to.
+type Repositories struct {
+	Books   repositories.BookRepository
+	Authors repositories.AuthorRepository
+	Users   repositories.UserRepository
+}
+
+// Result counts the records Seed created and the ones already present.
+type Result struct {
+	Authors int
+	Books   int
+	Users   int
+	Skipped int
+}
+
+// Seed creates the records of set that do not exist yet, so seeding the
+// same fixtures twice is harmless. Authors and books are matched by ID,
+// users by email.
+func Seed(ctx context.Context, repos Repositories, set *Set) (*Result, error) {
+	res := &Result{}
+
+	ids := make([]string, len(set.Authors))
+	for i, a := range set.Authors {
+		ids[i] = a.ID
+	}
+	existing, err := existingIDs(ctx, repos.Authors.ExistingIDs, "author", ids)
+	if err != nil {
+		return nil, err
+	}
+	var authors []*entities.Author
+	for _, a := range set.Authors {
+		id := uuid.MustParse(a.ID)
+		if existing[id.String()] {
+			continue
+		}
+		authors = append(authors, &entities.Author{ID: id, Name: a.Name, Country: a.Country})
+	}
+	if err := repos.Authors.CreateBatch(ctx, authors); err != nil {
+		return nil, fmt.Errorf("fixtures: authors: %w", err)
+	}
+	res.Authors = len(authors)
+	res.Skipped += len(set.Authors) - len(authors)
+
+	ids = make([]string, len(set.Books))
+	for i, b := range set.Books {
+		ids[i] = b.ID
+	}
+	existing, err = existingIDs(ctx, repos.Books.ExistingIDs, "book", ids)
+	if err != nil {
+		return nil, err
+	}
+	var books []*entities.Book
+	for _, b := range set.Books {
+		id := uuid.MustParse(b.ID)
+		if existing[id.String()] {
+			continue
+		}
+		books = append(books, &entities.Book{ID: id, Title: b.Title, PublishedYear: b.PublishedYear, Isbn: b.Isbn, AuthorID: b.Authors})
+	}
+	if err := repos.Books.CreateBatch(ctx, books); err != nil {
+		return nil, fmt.Errorf("fixtures: books: %w", err)
+	}
+	res.Books = len(books)
+	res.Skipped += len(set.Books) - len(books)
+
+	for _, u := range set.Users {
+		if repos.Users.IsDuplicateEmail(u.Email).Error == nil {
+			res.Skipped++
+			continue
+		}
+		_, err := repos.Users.Create(ctx, &entities.User{
+			ID:        uuid.New(),
+			Email:     u.Email,
+			Password:  u.Password,
+			Admin:     u.Admin,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fixtures: user %s: %w", u.Email, err)
+		}
+		res.Users++
+	}
+	return res, nil
+}
+
+// existingIDs checks that ids are UUIDs and returns the ones the store
+// already has, in canonical form.
+func existingIDs(ctx context.Context, lookup func(context.Context, []string) (map[string]bool, error), kind string, ids []string) (map[string]bool, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	canonical := make([]string, len(ids))
+	for i, id := range ids {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("fixtures: %s id %q: %w", kind, id, err)
+		}
+		canonical[i] = parsed.String()
+	}
+	return lookup(ctx, canonical)
+}
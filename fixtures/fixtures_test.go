@@ -0,0 +1,100 @@
+package fixtures
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/google/uuid"
+)
+
+func TestLoadMergesFiles(t *testing.T) {
+	set, err := Load("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Authors) != 2 || len(set.Books) != 2 || len(set.Users) != 2 {
+		t.Fatalf("loaded %d authors, %d books, %d users", len(set.Authors), len(set.Books), len(set.Users))
+	}
+	book := set.Books[0]
+	if book.PublishedYear.Year() != 1980 || book.Isbn != "9789799731234" || len(book.Authors) != 1 {
+		t.Errorf("unexpected book %+v", book)
+	}
+	if !set.Users[0].Admin || set.Users[1].Admin {
+		t.Errorf("unexpected users %+v", set.Users)
+	}
+}
+
+func TestLoadEmptyDirectory(t *testing.T) {
+	if _, err := Load(t.TempDir()); err == nil {
+		t.Fatal("expected an error for a directory without fixtures")
+	}
+}
+
+// newMemoryRepositories returns in-memory repositories holding the first
+// author and the admin user of testdata.
+func newMemoryRepositories(t *testing.T, set *Set) Repositories {
+	t.Helper()
+	store := repositories.NewMemoryStore()
+	repos := Repositories{
+		Books:   repositories.NewMemoryBookRepository(store),
+		Authors: repositories.NewMemoryAuthorRepository(store),
+		Users:   repositories.NewMemoryUserRepository(store),
+	}
+	ctx := context.Background()
+	author := &entities.Author{ID: uuid.MustParse(set.Authors[0].ID), Name: set.Authors[0].Name}
+	if _, err := repos.Authors.Create(ctx, author); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repos.Users.Create(ctx, &entities.User{ID: uuid.New(), Email: "admin@example.com", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	return repos
+}
+
+func TestSeedSkipsExistingRecords(t *testing.T) {
+	ctx := context.Background()
+	set, err := Load("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos := newMemoryRepositories(t, set)
+
+	res, err := Seed(ctx, repos, set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *res != (Result{Authors: 1, Books: 2, Users: 1, Skipped: 2}) {
+		t.Errorf("result %+v", *res)
+	}
+	created, err := repos.Authors.FindByNames(ctx, []string{"Ursula K. Le Guin"})
+	if err != nil || len(created) != 1 {
+		t.Fatalf("created authors %+v (%v)", created, err)
+	}
+	books, err := repos.Books.FindByAuthorIDs(ctx, []string{set.Authors[1].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := books[set.Authors[1].ID]; len(got) != 1 || got[0].ID != set.Books[1].ID {
+		t.Errorf("books of %s: %+v", set.Authors[1].Name, got)
+	}
+
+	res, err = Seed(ctx, repos, set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *res != (Result{Skipped: 6}) {
+		t.Errorf("second seed %+v", *res)
+	}
+}
+
+func TestSeedRejectsInvalidIDs(t *testing.T) {
+	set := &Set{Authors: []Author{{ID: "42", Name: "Nobody"}}}
+	store := repositories.NewMemoryStore()
+	_, err := Seed(context.Background(), Repositories{Authors: repositories.NewMemoryAuthorRepository(store)}, set)
+	if err == nil || !strings.Contains(err.Error(), `author id "42"`) {
+		t.Fatalf("err = %v", err)
+	}
+}
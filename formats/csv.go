@@ -0,0 +1,298 @@
+package formats
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
+)
+
+// Canonical CSV columns, written by export and read by import. Import files
+// may use other header names by passing a mapping from these names to the
+// header used in the file.
+const (
+	ColumnID            = "id"
+	ColumnTitle         = "title"
+	ColumnIsbn          = "isbn"
+	ColumnPublishedYear = "published_year"
+	ColumnAuthors       = "authors"
+	ColumnAuthorIDs     = "author_ids"
+	ColumnAuthorCountry = "author_country"
+)
+
+// authorSeparator separates several authors inside one CSV cell. The
+// authors, author_ids and author_country cells list the authors in the
+// same order.
+const authorSeparator = ";"
+
+// formulaEscape prefixes exported cells that spreadsheets would evaluate as
+// a formula; import removes it.
+const formulaEscape = "'"
+
+var csvHeader = []string{
+	ColumnID,
+	ColumnTitle,
+	ColumnIsbn,
+	ColumnPublishedYear,
+	ColumnAuthors,
+	ColumnAuthorIDs,
+	ColumnAuthorCountry,
+}
+
+var dateLayouts = []string{"2006", "2006-01-02", time.RFC3339}
+
+// CSVWriter streams books as CSV rows, writing the header before the first
+// book.
+type CSVWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *CSVWriter) Write(book *entities.Book) error {
+	if !cw.wroteHeader {
+		if err := cw.w.Write(csvHeader); err != nil {
+			return err
+		}
+		cw.wroteHeader = true
+	}
+	names := make([]string, len(book.Authors))
+	ids := make([]string, len(book.Authors))
+	countries := make([]string, len(book.Authors))
+	for i, author := range book.Authors {
+		names[i] = author.Name
+		ids[i] = csvID(author.ID)
+		countries[i] = author.Country
+	}
+	var published string
+	if !book.PublishedYear.IsZero() {
+		published = book.PublishedYear.Format("2006-01-02")
+	}
+	return cw.w.Write([]string{
+		csvID(book.ID),
+		escapeFormula(book.Title),
+		escapeFormula(book.Isbn),
+		published,
+		escapeFormula(strings.Join(names, authorSeparator)),
+		strings.Join(ids, authorSeparator),
+		escapeFormula(strings.Join(countries, authorSeparator)),
+	})
+}
+
+func (cw *CSVWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// Close flushes the remaining rows, writing the header of an empty export.
+func (cw *CSVWriter) Close() error {
+	if !cw.wroteHeader {
+		if err := cw.w.Write(csvHeader); err != nil {
+			return err
+		}
+		cw.wroteHeader = true
+	}
+	return cw.Flush()
+}
+
+// ReadCSV parses an import file. mapping renames canonical columns to the
+// header names used by the file; unmapped columns are matched by their
+// canonical name, case-insensitively. A file without a title or authors
+// column is rejected, other problems are reported per row.
+func ReadCSV(r io.Reader, mapping map[string]string) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	index := func(column string) int {
+		name := column
+		if mapped, ok := mapping[column]; ok && mapped != "" {
+			name = mapped
+		}
+		if i, ok := columns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			return i
+		}
+		return -1
+	}
+
+	titleCol := index(ColumnTitle)
+	authorsCol := index(ColumnAuthors)
+	if titleCol < 0 {
+		return nil, fmt.Errorf("csv header has no %q column", ColumnTitle)
+	}
+	if authorsCol < 0 {
+		return nil, fmt.Errorf("csv header has no %q column", ColumnAuthors)
+	}
+	cols := csvColumns{
+		id:        index(ColumnID),
+		title:     titleCol,
+		isbn:      index(ColumnIsbn),
+		published: index(ColumnPublishedYear),
+		authors:   authorsCol,
+		authorIDs: index(ColumnAuthorIDs),
+		country:   index(ColumnAuthorCountry),
+	}
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				records = append(records, Record{Line: parseErr.StartLine, Err: err})
+				continue
+			}
+			return nil, err
+		}
+		if isBlank(row) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		book, err := csvBook(row, cols)
+		records = append(records, Record{Line: line, Book: book, Err: err})
+	}
+	return records, nil
+}
+
+// csvColumns are the indexes of the columns in a file, -1 when absent.
+type csvColumns struct {
+	id, title, isbn, published, authors, authorIDs, country int
+}
+
+func csvBook(row []string, cols csvColumns) (*entities.Book, error) {
+	book := &entities.Book{
+		Title: unescapeFormula(cell(row, cols.title)),
+		Isbn:  unescapeFormula(cell(row, cols.isbn)),
+	}
+	if book.Title == "" {
+		return nil, fmt.Errorf("%s is required", ColumnTitle)
+	}
+	if id := cell(row, cols.id); id != "" {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", ColumnID, id)
+		}
+		book.ID = parsed
+	}
+	if published := cell(row, cols.published); published != "" {
+		year, err := parseDate(published)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", ColumnPublishedYear, published)
+		}
+		book.PublishedYear = year
+	}
+	ids := splitAuthors(cell(row, cols.authorIDs))
+	// A single country applies to every author.
+	countries := splitAuthors(unescapeFormula(cell(row, cols.country)))
+	for i, name := range splitAuthors(unescapeFormula(cell(row, cols.authors))) {
+		if name == "" {
+			continue
+		}
+		author := &entities.Author{Name: name}
+		if i < len(ids) && ids[i] != "" {
+			id, err := uuid.Parse(ids[i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", ColumnAuthorIDs, ids[i])
+			}
+			author.ID = id
+		}
+		switch {
+		case i < len(countries):
+			author.Country = countries[i]
+		case len(countries) == 1:
+			author.Country = countries[0]
+		}
+		book.Authors = append(book.Authors, author)
+	}
+	if len(book.Authors) == 0 {
+		return nil, fmt.Errorf("%s is required", ColumnAuthors)
+	}
+	return book, nil
+}
+
+// splitAuthors splits a cell listing one value per author.
+func splitAuthors(value string) []string {
+	if value == "" {
+		return nil
+	}
+	values := strings.Split(value, authorSeparator)
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+func parseDate(value string) (time.Time, error) {
+	var err error
+	for _, layout := range dateLayouts {
+		var t time.Time
+		t, err = time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func isBlank(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// csvID leaves the cell of a missing id empty.
+func csvID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+// escapeFormula keeps spreadsheets from running a cell starting with a
+// formula character as a formula (CSV injection).
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return formulaEscape + value
+	}
+	return value
+}
+
+// unescapeFormula reverts escapeFormula.
+func unescapeFormula(value string) string {
+	if rest := strings.TrimPrefix(value, formulaEscape); rest != value && escapeFormula(rest) == value {
+		return rest
+	}
+	return value
+}
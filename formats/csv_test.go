@@ -0,0 +1,101 @@
+package formats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
+)
+
+var csvFixtureBooks = []*entities.Book{
+	{
+		ID:            uuid.MustParse("3f2c9a1e-5b7d-4c8e-9a0b-1d2e3f4a5b6c"),
+		Title:         "Bumi Manusia",
+		Isbn:          "9780306406157",
+		PublishedYear: date(2001, 3, 15),
+		Authors: []*entities.Author{{
+			ID:      uuid.MustParse("9d0c2b7a-1e4f-4a3b-8c5d-6e7f8a9b0c1d"),
+			Name:    "Pramoedya Ananta Toer",
+			Country: "ID",
+		}},
+	},
+	{
+		Title:         "Good Omens",
+		PublishedYear: date(1990, 1, 1),
+		Authors: []*entities.Author{
+			{Name: "Terry Pratchett", Country: "GB"},
+			{Name: "Neil Gaiman", Country: "GB"},
+		},
+	},
+	{
+		Title:         `=HYPERLINK("http://example.com")`,
+		PublishedYear: date(2020, 1, 1),
+		Authors:       []*entities.Author{{Name: "@Anonymous", Country: "+62"}},
+	},
+}
+
+func TestReadCSV(t *testing.T) {
+	records, err := ReadCSV(bytes.NewReader(readFixture(t, "books.csv")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBooks(t, csvFixtureBooks, validBooks(t, records, 5, 6, 7))
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	records, err := ReadCSV(bytes.NewReader(readFixture(t, "books.csv")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	books := validBooks(t, records, 5, 6, 7)
+	books[1].Authors[1].Country = "UK"
+
+	out := writeAll(t, FormatCSV, books)
+	records, err = ReadCSV(bytes.NewReader(out), nil)
+	if err != nil {
+		t.Fatalf("re-reading exported file: %v\n%s", err, out)
+	}
+	assertBooks(t, books, validBooks(t, records))
+
+	if again := writeAll(t, FormatCSV, validBooks(t, records)); !bytes.Equal(out, again) {
+		t.Errorf("second export differs from the first:\n%s\n---\n%s", out, again)
+	}
+}
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	out := writeAll(t, FormatCSV, []*entities.Book{{
+		Title:   "-1 Minus One",
+		Isbn:    "+62",
+		Authors: []*entities.Author{{Name: "=cmd()", Country: "@ID"}},
+	}})
+	row := strings.Split(strings.TrimSpace(string(out)), "\n")[1]
+	for _, value := range []string{"'-1 Minus One", "'+62", "'=cmd()", "'@ID"} {
+		if !strings.Contains(row, value) {
+			t.Errorf("%q is not escaped in %s", value[1:], row)
+		}
+	}
+}
+
+func TestReadCSVMapping(t *testing.T) {
+	file := "Judul,Penulis,Negara\nBumi Manusia,Pramoedya Ananta Toer,ID\n"
+	records, err := ReadCSV(strings.NewReader(file), map[string]string{
+		ColumnTitle:         "judul",
+		ColumnAuthors:       "Penulis",
+		ColumnAuthorCountry: "negara",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBooks(t, []*entities.Book{{
+		Title:   "Bumi Manusia",
+		Authors: []*entities.Author{{Name: "Pramoedya Ananta Toer", Country: "ID"}},
+	}}, validBooks(t, records))
+
+	for _, file := range []string{"", "title\nDune\n", "authors\nFrank Herbert\n"} {
+		if _, err := ReadCSV(strings.NewReader(file), nil); err == nil {
+			t.Errorf("%q: expected an error", file)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package formats converts catalog records between entities and the file
+// formats used to exchange them with spreadsheets, publishers and libraries.
+package formats
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/aldisaputra17/book-store/entities"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatONIX    = "onix"
+	FormatMARC    = "marc"
+	FormatMARCXML = "marcxml"
+)
+
+// Record is one book read from an import file. Line is the 1-based position
+// of the record in the file (a CSV line, a record number for other formats)
+// and Err is set when the record could not be parsed.
+type Record struct {
+	Line int
+	Book *entities.Book
+	Err  error
+}
+
+// Writer serializes books one at a time. Flush pushes buffered output to the
+// underlying writer, Close finishes the document and must be called once
+// after the last book, even when no book was written.
+type Writer interface {
+	Write(book *entities.Book) error
+	Flush() error
+	Close() error
+}
+
+// NewWriter returns a Writer for format.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatONIX:
+		return NewONIXWriter(w), nil
+	case FormatMARC:
+		return NewMARCWriter(w), nil
+	case FormatMARCXML:
+		return NewMARCXMLWriter(w), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// Read parses an import file in format. mapping is only used by CSV, see
+// ReadCSV.
+func Read(format string, r io.Reader, mapping map[string]string) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return ReadCSV(r, mapping)
+	case FormatONIX:
+		return ReadONIX(r)
+	case FormatMARC:
+		return ReadMARC(r)
+	case FormatMARCXML:
+		return ReadMARCXML(r)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// ContentType returns the media type and file extension used when
+// exporting format.
+func ContentType(format string) (string, string) {
+	switch format {
+	case FormatONIX:
+		return "application/xml; charset=utf-8", "xml"
+	case FormatMARC:
+		return "application/marc", "mrc"
+	case FormatMARCXML:
+		return "application/marcxml+xml; charset=utf-8", "xml"
+	}
+	return "text/csv; charset=utf-8", "csv"
+}
+
+var yearPattern = regexp.MustCompile(`\d{4}`)
+
+// parseYear extracts the first four digit year of a free text date such as
+// "c2001." or "[2001?]".
+func parseYear(value string) (time.Time, bool) {
+	match := yearPattern.FindString(value)
+	if match == "" {
+		return time.Time{}, false
+	}
+	year, _ := strconv.Atoi(match)
+	return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), true
+}
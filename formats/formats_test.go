@@ -0,0 +1,104 @@
+package formats
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/entities"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// validBooks returns the books of records that parsed, failing the test on
+// any record error unless its line is listed in allowedErrors.
+func validBooks(t *testing.T, records []Record, allowedErrors ...int) []*entities.Book {
+	t.Helper()
+	allowed := make(map[int]bool)
+	for _, line := range allowedErrors {
+		allowed[line] = true
+	}
+	var books []*entities.Book
+	for _, record := range records {
+		if record.Err != nil {
+			if !allowed[record.Line] {
+				t.Errorf("record %d: unexpected error: %v", record.Line, record.Err)
+			}
+			continue
+		}
+		if allowed[record.Line] {
+			t.Errorf("record %d: expected an error", record.Line)
+		}
+		books = append(books, record.Book)
+	}
+	return books
+}
+
+// writeAll serializes books with the writer for format and returns the
+// document.
+func writeAll(t *testing.T, format string, books []*entities.Book) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixedClock(w)
+	for _, book := range books {
+		if err := w.Write(book); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func fixedClock(w Writer) {
+	now := func() time.Time { return time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC) }
+	switch w := w.(type) {
+	case *ONIXWriter:
+		w.now = now
+	case *MARCWriter:
+		w.now = now
+	case *MARCXMLWriter:
+		w.now = now
+	}
+}
+
+func assertBooks(t *testing.T, want, got []*entities.Book) {
+	t.Helper()
+	if len(want) != len(got) {
+		t.Fatalf("got %d books, want %d", len(got), len(want))
+	}
+	for i := range want {
+		w, g := want[i], got[i]
+		if w.ID != g.ID || w.Title != g.Title || w.Isbn != g.Isbn || !w.PublishedYear.Equal(g.PublishedYear) {
+			t.Errorf("book %d: got {%s %q %q %s}, want {%s %q %q %s}", i,
+				g.ID, g.Title, g.Isbn, g.PublishedYear, w.ID, w.Title, w.Isbn, w.PublishedYear)
+		}
+		if len(w.Authors) != len(g.Authors) {
+			t.Errorf("book %d: got %d authors, want %d", i, len(g.Authors), len(w.Authors))
+			continue
+		}
+		for j := range w.Authors {
+			if w.Authors[j].ID != g.Authors[j].ID || w.Authors[j].Name != g.Authors[j].Name || w.Authors[j].Country != g.Authors[j].Country {
+				t.Errorf("book %d author %d: got %+v, want %+v", i, j, *g.Authors[j], *w.Authors[j])
+			}
+		}
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,453 @@
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
+)
+
+// MARC21 bibliographic records, as ISO 2709 binary or MARCXML. The catalog
+// maps to: 001 control number (book id), 008/07-10 date 1, 020 $a ISBN,
+// 100 $a first author, 700 $a further authors, 245 $a title and 264 $c
+// (or 260 $c) publication date. MARC has no place for an author's country,
+// it is not carried.
+const (
+	marcSubfieldDelimiter = 0x1F
+	marcFieldTerminator   = 0x1E
+	marcRecordTerminator  = 0x1D
+
+	marcLeaderLength = 24
+	marcEntryLength  = 12
+
+	marcXMLNamespace = "http://www.loc.gov/MARC21/slim"
+)
+
+type marcRecord struct {
+	Leader string
+	Fields []marcField
+}
+
+// marcField is a control field (tag 001-009, Value set) or a data field
+// (indicators and subfields set).
+type marcField struct {
+	Tag       string
+	Value     string
+	Ind1      byte
+	Ind2      byte
+	Subfields []marcSubfield
+}
+
+type marcSubfield struct {
+	Code  byte
+	Value string
+}
+
+func isControlTag(tag string) bool {
+	return strings.HasPrefix(tag, "00")
+}
+
+func (r marcRecord) field(tag string) *marcField {
+	for i := range r.Fields {
+		if r.Fields[i].Tag == tag {
+			return &r.Fields[i]
+		}
+	}
+	return nil
+}
+
+func (f *marcField) subfield(code byte) string {
+	if f == nil {
+		return ""
+	}
+	for _, subfield := range f.Subfields {
+		if subfield.Code == code {
+			return subfield.Value
+		}
+	}
+	return ""
+}
+
+func marcBook(record marcRecord) (*entities.Book, error) {
+	book := &entities.Book{
+		Title: trimISBD(record.field("245").subfield('a')),
+	}
+	if book.Title == "" {
+		return nil, errors.New("record has no title (245 $a)")
+	}
+	if control := record.field("001"); control != nil {
+		if id, err := uuid.Parse(strings.TrimSpace(control.Value)); err == nil {
+			book.ID = id
+		}
+	}
+	if isbn := strings.Fields(record.field("020").subfield('a')); len(isbn) > 0 {
+		book.Isbn = isbn[0]
+	}
+	for _, field := range record.Fields {
+		if field.Tag != "100" && field.Tag != "700" {
+			continue
+		}
+		if name := trimISBD(field.subfield('a')); name != "" {
+			book.Authors = append(book.Authors, &entities.Author{Name: name})
+		}
+	}
+	if len(book.Authors) == 0 {
+		return nil, errors.New("record has no author (100/700 $a)")
+	}
+
+	published := record.field("264").subfield('c')
+	if published == "" {
+		published = record.field("260").subfield('c')
+	}
+	if year, ok := parseYear(published); ok {
+		book.PublishedYear = year
+	} else if fixed := record.field("008"); fixed != nil && len(fixed.Value) >= 11 {
+		if year, ok := parseYear(fixed.Value[7:11]); ok {
+			book.PublishedYear = year
+		}
+	}
+	return book, nil
+}
+
+// trimISBD drops the trailing ISBD punctuation cataloguers put before the
+// next subfield, e.g. "A title /".
+func trimISBD(value string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(value), " /:;,="))
+}
+
+func marcRecordOf(book *entities.Book, now time.Time) marcRecord {
+	date1 := "    "
+	if !book.PublishedYear.IsZero() {
+		date1 = fmt.Sprintf("%04d", book.PublishedYear.Year())
+	}
+	// 008 for books: date entered, single known date, date 1, then blank
+	// or fill positions up to language "und", unmodified, source "d".
+	fixed := now.Format("060102") + "s" + date1 + "    " + "xx " + strings.Repeat(" ", 17) + "und" + " " + "d"
+
+	record := marcRecord{
+		Leader: "00000nam a2200000 i 4500",
+		Fields: []marcField{
+			{Tag: "001", Value: book.ID.String()},
+			{Tag: "008", Value: fixed},
+		},
+	}
+	if book.Isbn != "" {
+		record.Fields = append(record.Fields, marcField{Tag: "020", Ind1: ' ', Ind2: ' ', Subfields: []marcSubfield{{Code: 'a', Value: book.Isbn}}})
+	}
+	for i, author := range book.Authors {
+		tag := "700"
+		if i == 0 {
+			tag = "100"
+		}
+		record.Fields = append(record.Fields, marcField{Tag: tag, Ind1: '1', Ind2: ' ', Subfields: []marcSubfield{{Code: 'a', Value: author.Name}}})
+	}
+	titleInd1 := byte('0')
+	if len(book.Authors) > 0 {
+		titleInd1 = '1'
+	}
+	record.Fields = append(record.Fields, marcField{Tag: "245", Ind1: titleInd1, Ind2: '0', Subfields: []marcSubfield{{Code: 'a', Value: book.Title}}})
+	if !book.PublishedYear.IsZero() {
+		record.Fields = append(record.Fields, marcField{Tag: "264", Ind1: ' ', Ind2: '1', Subfields: []marcSubfield{{Code: 'c', Value: date1}}})
+	}
+	sortFields(record.Fields)
+	return record
+}
+
+// sortFields orders fields by tag, keeping the order of repeated tags.
+func sortFields(fields []marcField) {
+	for i := 1; i < len(fields); i++ {
+		for j := i; j > 0 && fields[j].Tag < fields[j-1].Tag; j-- {
+			fields[j], fields[j-1] = fields[j-1], fields[j]
+		}
+	}
+}
+
+// ReadMARC parses ISO 2709 records. Character data is expected in UTF-8
+// (leader/09 "a"), MARC-8 records are read as is.
+func ReadMARC(r io.Reader) ([]Record, error) {
+	reader := bufio.NewReader(r)
+	var records []Record
+	for n := 1; ; n++ {
+		raw, err := reader.ReadBytes(marcRecordTerminator)
+		if err == io.EOF {
+			if len(bytes.TrimSpace(raw)) > 0 {
+				records = append(records, Record{Line: n, Err: errors.New("record is missing its terminator")})
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		record, err := decodeMARC(raw)
+		if err != nil {
+			records = append(records, Record{Line: n, Err: err})
+			continue
+		}
+		book, err := marcBook(record)
+		records = append(records, Record{Line: n, Book: book, Err: err})
+	}
+	return records, nil
+}
+
+func decodeMARC(raw []byte) (marcRecord, error) {
+	raw = bytes.TrimLeft(raw, "\r\n ")
+	if len(raw) < marcLeaderLength+1 {
+		return marcRecord{}, errors.New("record is shorter than its leader")
+	}
+	leader := string(raw[:marcLeaderLength])
+	base, ok := marcNumber(leader[12:17])
+	if !ok || base <= marcLeaderLength || base > len(raw) {
+		return marcRecord{}, fmt.Errorf("invalid base address %q", leader[12:17])
+	}
+	directory := raw[marcLeaderLength : base-1]
+	if len(directory)%marcEntryLength != 0 {
+		return marcRecord{}, errors.New("invalid directory length")
+	}
+
+	record := marcRecord{Leader: leader}
+	for i := 0; i < len(directory); i += marcEntryLength {
+		entry := string(directory[i : i+marcEntryLength])
+		length, okLen := marcNumber(entry[3:7])
+		start, okStart := marcNumber(entry[7:12])
+		if !okLen || !okStart || length < 1 || start < 0 || base+start < base || base+start+length > len(raw) {
+			return marcRecord{}, fmt.Errorf("invalid directory entry %q", entry)
+		}
+		data := raw[base+start : base+start+length-1]
+		field := marcField{Tag: entry[:3]}
+		if isControlTag(field.Tag) {
+			field.Value = string(data)
+		} else {
+			if len(data) < 2 {
+				return marcRecord{}, fmt.Errorf("field %s has no indicators", field.Tag)
+			}
+			field.Ind1, field.Ind2 = data[0], data[1]
+			for _, chunk := range bytes.Split(data[2:], []byte{marcSubfieldDelimiter}) {
+				if len(chunk) == 0 {
+					continue
+				}
+				field.Subfields = append(field.Subfields, marcSubfield{Code: chunk[0], Value: string(chunk[1:])})
+			}
+		}
+		record.Fields = append(record.Fields, field)
+	}
+	return record, nil
+}
+
+// marcNumber parses a fixed-width number of the leader or directory, which
+// holds ASCII digits only: no sign or spaces.
+func marcNumber(value string) (int, bool) {
+	if value == "" {
+		return 0, false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(value)
+	return n, err == nil
+}
+
+func encodeMARC(record marcRecord) []byte {
+	var directory, data bytes.Buffer
+	for _, field := range record.Fields {
+		start := data.Len()
+		if isControlTag(field.Tag) {
+			data.WriteString(field.Value)
+		} else {
+			data.WriteByte(field.Ind1)
+			data.WriteByte(field.Ind2)
+			for _, subfield := range field.Subfields {
+				data.WriteByte(marcSubfieldDelimiter)
+				data.WriteByte(subfield.Code)
+				data.WriteString(subfield.Value)
+			}
+		}
+		data.WriteByte(marcFieldTerminator)
+		fmt.Fprintf(&directory, "%s%04d%05d", field.Tag, data.Len()-start, start)
+	}
+	directory.WriteByte(marcFieldTerminator)
+	data.WriteByte(marcRecordTerminator)
+
+	base := marcLeaderLength + directory.Len()
+	leader := []byte(record.Leader)
+	copy(leader[0:5], fmt.Sprintf("%05d", base+data.Len()))
+	leader[9] = 'a'
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+
+	out := make([]byte, 0, base+data.Len())
+	out = append(out, leader...)
+	out = append(out, directory.Bytes()...)
+	return append(out, data.Bytes()...)
+}
+
+// MARCWriter streams books as ISO 2709 records.
+type MARCWriter struct {
+	w   *bufio.Writer
+	now func() time.Time
+}
+
+func NewMARCWriter(w io.Writer) *MARCWriter {
+	return &MARCWriter{w: bufio.NewWriter(w), now: time.Now}
+}
+
+func (mw *MARCWriter) Write(book *entities.Book) error {
+	_, err := mw.w.Write(encodeMARC(marcRecordOf(book, mw.now())))
+	return err
+}
+
+func (mw *MARCWriter) Flush() error {
+	return mw.w.Flush()
+}
+
+func (mw *MARCWriter) Close() error {
+	return mw.w.Flush()
+}
+
+type marcXMLRecord struct {
+	XMLName       xml.Name              `xml:"record"`
+	Leader        string                `xml:"leader"`
+	ControlFields []marcXMLControlField `xml:"controlfield"`
+	DataFields    []marcXMLDataField    `xml:"datafield"`
+}
+
+type marcXMLControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcXMLDataField struct {
+	Tag       string            `xml:"tag,attr"`
+	Ind1      string            `xml:"ind1,attr"`
+	Ind2      string            `xml:"ind2,attr"`
+	Subfields []marcXMLSubfield `xml:"subfield"`
+}
+
+type marcXMLSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ReadMARCXML parses every <record> of a MARCXML document, whether it is a
+// <collection> or a single record.
+func ReadMARCXML(r io.Reader) ([]Record, error) {
+	dec := xml.NewDecoder(r)
+	var records []Record
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+		var xmlRecord marcXMLRecord
+		if err := dec.DecodeElement(&xmlRecord, &start); err != nil {
+			return nil, err
+		}
+		book, err := marcBook(xmlRecord.marc())
+		records = append(records, Record{Line: len(records) + 1, Book: book, Err: err})
+	}
+	return records, nil
+}
+
+func (x marcXMLRecord) marc() marcRecord {
+	record := marcRecord{Leader: x.Leader}
+	for _, control := range x.ControlFields {
+		record.Fields = append(record.Fields, marcField{Tag: control.Tag, Value: control.Value})
+	}
+	for _, data := range x.DataFields {
+		field := marcField{Tag: data.Tag, Ind1: indicator(data.Ind1), Ind2: indicator(data.Ind2)}
+		for _, subfield := range data.Subfields {
+			if subfield.Code == "" {
+				continue
+			}
+			field.Subfields = append(field.Subfields, marcSubfield{Code: subfield.Code[0], Value: subfield.Value})
+		}
+		record.Fields = append(record.Fields, field)
+	}
+	return record
+}
+
+func indicator(value string) byte {
+	if value == "" {
+		return ' '
+	}
+	return value[0]
+}
+
+func marcXMLRecordOf(record marcRecord) marcXMLRecord {
+	x := marcXMLRecord{Leader: record.Leader}
+	for _, field := range record.Fields {
+		if isControlTag(field.Tag) {
+			x.ControlFields = append(x.ControlFields, marcXMLControlField{Tag: field.Tag, Value: field.Value})
+			continue
+		}
+		data := marcXMLDataField{Tag: field.Tag, Ind1: string(field.Ind1), Ind2: string(field.Ind2)}
+		for _, subfield := range field.Subfields {
+			data.Subfields = append(data.Subfields, marcXMLSubfield{Code: string(subfield.Code), Value: subfield.Value})
+		}
+		x.DataFields = append(x.DataFields, data)
+	}
+	return x
+}
+
+// MARCXMLWriter streams books as the records of a MARCXML <collection>.
+type MARCXMLWriter struct {
+	enc     *xml.Encoder
+	started bool
+	now     func() time.Time
+}
+
+func NewMARCXMLWriter(w io.Writer) *MARCXMLWriter {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &MARCXMLWriter{enc: enc, now: time.Now}
+}
+
+func (mw *MARCXMLWriter) start() error {
+	if mw.started {
+		return nil
+	}
+	mw.started = true
+	if err := mw.enc.EncodeToken(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)}); err != nil {
+		return err
+	}
+	return mw.enc.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "collection"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: marcXMLNamespace}},
+	})
+}
+
+func (mw *MARCXMLWriter) Write(book *entities.Book) error {
+	if err := mw.start(); err != nil {
+		return err
+	}
+	return mw.enc.Encode(marcXMLRecordOf(marcRecordOf(book, mw.now())))
+}
+
+func (mw *MARCXMLWriter) Flush() error {
+	return mw.enc.Flush()
+}
+
+func (mw *MARCXMLWriter) Close() error {
+	if err := mw.start(); err != nil {
+		return err
+	}
+	if err := mw.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "collection"}}); err != nil {
+		return err
+	}
+	return mw.enc.Flush()
+}
@@ -0,0 +1,111 @@
+package formats
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
+)
+
+var marcFixtureBooks = []*entities.Book{
+	{
+		ID:            uuid.MustParse("3f2c9a1e-5b7d-4c8e-9a0b-1d2e3f4a5b6c"),
+		Title:         "Bumi manusia",
+		Isbn:          "9780306406157",
+		PublishedYear: date(2001, 1, 1),
+		Authors:       []*entities.Author{{Name: "Toer, Pramoedya Ananta"}},
+	},
+	{
+		ID:            uuid.MustParse("7b1e0c4d-2a3f-4e5d-8c6b-9a0f1e2d3c4b"),
+		Title:         "The rainbow troops",
+		Isbn:          "979-8-6024-7725-6",
+		PublishedYear: date(1999, 1, 1),
+		Authors: []*entities.Author{
+			{Name: "Hirata, Andrea"},
+			{Name: "Kilbane, Angie"},
+		},
+	},
+}
+
+func TestReadMARC(t *testing.T) {
+	records, err := ReadMARC(bytes.NewReader(readFixture(t, "marc.mrc")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBooks(t, marcFixtureBooks, validBooks(t, records))
+}
+
+func TestReadMARCXML(t *testing.T) {
+	records, err := ReadMARCXML(bytes.NewReader(readFixture(t, "marc.xml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBooks(t, marcFixtureBooks, validBooks(t, records))
+}
+
+func TestMARCRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatMARC, FormatMARCXML} {
+		t.Run(format, func(t *testing.T) {
+			fixture := "marc.mrc"
+			if format == FormatMARCXML {
+				fixture = "marc.xml"
+			}
+			records, err := Read(format, bytes.NewReader(readFixture(t, fixture)), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			books := validBooks(t, records)
+
+			out := writeAll(t, format, books)
+			records, err = Read(format, bytes.NewReader(out), nil)
+			if err != nil {
+				t.Fatalf("re-reading exported records: %v", err)
+			}
+			assertBooks(t, books, validBooks(t, records))
+
+			if again := writeAll(t, format, validBooks(t, records)); !bytes.Equal(out, again) {
+				t.Errorf("second export differs from the first:\n%q\n---\n%q", out, again)
+			}
+		})
+	}
+}
+
+func TestReadMARCReportsBrokenRecords(t *testing.T) {
+	fixture := readFixture(t, "marc.mrc")
+	broken := append([]byte("00042nam a2200025 i 4500garbage\x1d"), fixture...)
+
+	records, err := ReadMARC(bytes.NewReader(broken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBooks(t, marcFixtureBooks, validBooks(t, records, 1))
+}
+
+func TestReadMARCRejectsSignedDirectoryNumbers(t *testing.T) {
+	fixture := readFixture(t, "marc.mrc")
+	first := fixture[:bytes.IndexByte(fixture, 0x1d)+1]
+	for _, bad := range []struct{ from, value string }{
+		{"start", "-0099"},
+		{"start", "-9999"},
+		{"start", "+0001"},
+		{"start", " 0001"},
+		{"length", "-001"},
+		{"length", "+099"},
+	} {
+		record := append([]byte(nil), first...)
+		offset := marcLeaderLength + 7
+		if bad.from == "length" {
+			offset = marcLeaderLength + 3
+		}
+		copy(record[offset:], bad.value)
+
+		records, err := ReadMARC(bytes.NewReader(record))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Err == nil {
+			t.Errorf("%s %q: expected an error, got %+v", bad.from, bad.value, records)
+		}
+	}
+}
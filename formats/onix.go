@@ -0,0 +1,371 @@
+package formats
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
+)
+
+// ONIX for Books 3.0, reference tag names. Only the elements the catalog
+// stores are read or written: record reference, ISBN, distinctive title,
+// authors (contributor role A01) with their country and publication date.
+const (
+	onixNamespace = "http://ns.editeur.org/onix/3.0/reference"
+	onixSender    = "book-store"
+
+	onixIDTypeProprietary = "01"
+	onixIDTypeISBN10      = "02"
+	onixIDTypeGTIN13      = "03"
+	onixIDTypeISBN13      = "15"
+
+	onixTitleTypeDistinctive = "01"
+	onixTitleLevelProduct    = "01"
+	onixRoleAuthor           = "A01"
+	onixPlaceCitizenOf       = "04"
+	onixDateRolePublication  = "01"
+)
+
+type onixMessage struct {
+	Products []onixProduct `xml:"Product"`
+}
+
+type onixHeader struct {
+	XMLName      xml.Name `xml:"Header"`
+	SenderName   string   `xml:"Sender>SenderName"`
+	SentDateTime string   `xml:"SentDateTime"`
+}
+
+type onixProduct struct {
+	XMLName            xml.Name                `xml:"Product"`
+	RecordReference    string                  `xml:"RecordReference"`
+	NotificationType   string                  `xml:"NotificationType"`
+	ProductIdentifiers []onixProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  onixDescriptiveDetail   `xml:"DescriptiveDetail"`
+	PublishingDetail   *onixPublishingDetail   `xml:"PublishingDetail,omitempty"`
+}
+
+type onixProductIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDTypeName    string `xml:"IDTypeName,omitempty"`
+	IDValue       string `xml:"IDValue"`
+}
+
+type onixDescriptiveDetail struct {
+	ProductComposition string            `xml:"ProductComposition"`
+	ProductForm        string            `xml:"ProductForm"`
+	TitleDetails       []onixTitleDetail `xml:"TitleDetail"`
+	Contributors       []onixContributor `xml:"Contributor"`
+}
+
+type onixTitleDetail struct {
+	TitleType     string             `xml:"TitleType"`
+	TitleElements []onixTitleElement `xml:"TitleElement"`
+}
+
+type onixTitleElement struct {
+	TitleElementLevel  string `xml:"TitleElementLevel"`
+	TitleText          string `xml:"TitleText,omitempty"`
+	TitlePrefix        string `xml:"TitlePrefix,omitempty"`
+	TitleWithoutPrefix string `xml:"TitleWithoutPrefix,omitempty"`
+}
+
+type onixContributor struct {
+	SequenceNumber     int                    `xml:"SequenceNumber,omitempty"`
+	ContributorRoles   []string               `xml:"ContributorRole"`
+	PersonName         string                 `xml:"PersonName,omitempty"`
+	PersonNameInverted string                 `xml:"PersonNameInverted,omitempty"`
+	NamesBeforeKey     string                 `xml:"NamesBeforeKey,omitempty"`
+	KeyNames           string                 `xml:"KeyNames,omitempty"`
+	CorporateName      string                 `xml:"CorporateName,omitempty"`
+	ContributorPlaces  []onixContributorPlace `xml:"ContributorPlace"`
+}
+
+type onixContributorPlace struct {
+	ContributorPlaceRelator string `xml:"ContributorPlaceRelator"`
+	CountryCode             string `xml:"CountryCode"`
+}
+
+type onixPublishingDetail struct {
+	PublishingDates []onixPublishingDate `xml:"PublishingDate"`
+}
+
+type onixPublishingDate struct {
+	PublishingDateRole string `xml:"PublishingDateRole"`
+	Date               string `xml:"Date"`
+}
+
+// ReadONIX parses an ONIX 3.0 message. Line is the position of the
+// <Product> in the message.
+func ReadONIX(r io.Reader) ([]Record, error) {
+	var message onixMessage
+	if err := xml.NewDecoder(r).Decode(&message); err != nil {
+		return nil, err
+	}
+	records := make([]Record, len(message.Products))
+	for i, product := range message.Products {
+		book, err := onixBook(product)
+		records[i] = Record{Line: i + 1, Book: book, Err: err}
+	}
+	return records, nil
+}
+
+func onixBook(product onixProduct) (*entities.Book, error) {
+	book := &entities.Book{
+		Isbn: onixISBN(product.ProductIdentifiers),
+	}
+	if id, err := uuid.Parse(product.RecordReference); err == nil {
+		book.ID = id
+	}
+	for _, detail := range product.DescriptiveDetail.TitleDetails {
+		if detail.TitleType != onixTitleTypeDistinctive {
+			continue
+		}
+		for _, element := range detail.TitleElements {
+			if element.TitleElementLevel == onixTitleLevelProduct {
+				book.Title = element.title()
+				break
+			}
+		}
+	}
+	if book.Title == "" {
+		return nil, errors.New("product has no distinctive title")
+	}
+	for _, contributor := range product.DescriptiveDetail.Contributors {
+		if !contributor.isAuthor() {
+			continue
+		}
+		name := contributor.name()
+		if name == "" {
+			continue
+		}
+		author := &entities.Author{Name: name}
+		for _, place := range contributor.ContributorPlaces {
+			if place.ContributorPlaceRelator == onixPlaceCitizenOf {
+				author.Country = place.CountryCode
+			}
+		}
+		book.Authors = append(book.Authors, author)
+	}
+	if len(book.Authors) == 0 {
+		return nil, errors.New("product has no author")
+	}
+	if product.PublishingDetail != nil {
+		for _, date := range product.PublishingDetail.PublishingDates {
+			if date.PublishingDateRole != onixDateRolePublication {
+				continue
+			}
+			published, err := parseONIXDate(date.Date)
+			if err != nil {
+				return nil, fmt.Errorf("invalid publication date %q", date.Date)
+			}
+			book.PublishedYear = published
+		}
+	}
+	return book, nil
+}
+
+func onixISBN(identifiers []onixProductIdentifier) string {
+	var isbn10 string
+	for _, identifier := range identifiers {
+		switch identifier.ProductIDType {
+		case onixIDTypeISBN13, onixIDTypeGTIN13:
+			return identifier.IDValue
+		case onixIDTypeISBN10:
+			isbn10 = identifier.IDValue
+		}
+	}
+	if isbn10 != "" {
+		return isbn10
+	}
+	for _, identifier := range identifiers {
+		if identifier.ProductIDType == onixIDTypeProprietary {
+			return identifier.IDValue
+		}
+	}
+	return ""
+}
+
+func (e onixTitleElement) title() string {
+	if e.TitleText != "" {
+		return strings.TrimSpace(e.TitleText)
+	}
+	return strings.TrimSpace(e.TitlePrefix + " " + e.TitleWithoutPrefix)
+}
+
+func (c onixContributor) isAuthor() bool {
+	for _, role := range c.ContributorRoles {
+		if role == onixRoleAuthor {
+			return true
+		}
+	}
+	return false
+}
+
+func (c onixContributor) name() string {
+	switch {
+	case c.PersonName != "":
+		return strings.TrimSpace(c.PersonName)
+	case c.KeyNames != "":
+		return strings.TrimSpace(c.NamesBeforeKey + " " + c.KeyNames)
+	case c.PersonNameInverted != "":
+		return strings.TrimSpace(c.PersonNameInverted)
+	}
+	return strings.TrimSpace(c.CorporateName)
+}
+
+func parseONIXDate(value string) (time.Time, error) {
+	var err error
+	for _, layout := range []string{"20060102", "200601", "2006"} {
+		var t time.Time
+		t, err = time.Parse(layout, strings.TrimSpace(value))
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// ONIXWriter streams books as the <Product> records of one ONIX 3.0
+// message.
+type ONIXWriter struct {
+	enc     *xml.Encoder
+	started bool
+	now     func() time.Time
+}
+
+func NewONIXWriter(w io.Writer) *ONIXWriter {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &ONIXWriter{enc: enc, now: time.Now}
+}
+
+func (ow *ONIXWriter) start() error {
+	if ow.started {
+		return nil
+	}
+	ow.started = true
+	if err := ow.enc.EncodeToken(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)}); err != nil {
+		return err
+	}
+	root := xml.StartElement{
+		Name: xml.Name{Local: "ONIXMessage"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "release"}, Value: "3.0"},
+			{Name: xml.Name{Local: "xmlns"}, Value: onixNamespace},
+		},
+	}
+	if err := ow.enc.EncodeToken(root); err != nil {
+		return err
+	}
+	return ow.enc.Encode(onixHeader{
+		SenderName:   onixSender,
+		SentDateTime: ow.now().UTC().Format("20060102T1504Z0700"),
+	})
+}
+
+func (ow *ONIXWriter) Write(book *entities.Book) error {
+	if err := ow.start(); err != nil {
+		return err
+	}
+	return ow.enc.Encode(onixProductOf(book))
+}
+
+func (ow *ONIXWriter) Flush() error {
+	return ow.enc.Flush()
+}
+
+func (ow *ONIXWriter) Close() error {
+	if err := ow.start(); err != nil {
+		return err
+	}
+	if err := ow.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "ONIXMessage"}}); err != nil {
+		return err
+	}
+	return ow.enc.Flush()
+}
+
+func onixProductOf(book *entities.Book) onixProduct {
+	product := onixProduct{
+		RecordReference:  book.ID.String(),
+		NotificationType: "03",
+		DescriptiveDetail: onixDescriptiveDetail{
+			ProductComposition: "00",
+			ProductForm:        "00",
+			TitleDetails: []onixTitleDetail{{
+				TitleType: onixTitleTypeDistinctive,
+				TitleElements: []onixTitleElement{{
+					TitleElementLevel: onixTitleLevelProduct,
+					TitleText:         book.Title,
+				}},
+			}},
+		},
+	}
+	if book.Isbn != "" {
+		product.ProductIdentifiers = []onixProductIdentifier{onixIdentifierOf(book.Isbn)}
+	}
+	for i, author := range book.Authors {
+		contributor := onixContributor{
+			SequenceNumber:   i + 1,
+			ContributorRoles: []string{onixRoleAuthor},
+			PersonName:       author.Name,
+		}
+		if isCountryCode(author.Country) {
+			contributor.ContributorPlaces = []onixContributorPlace{{
+				ContributorPlaceRelator: onixPlaceCitizenOf,
+				CountryCode:             author.Country,
+			}}
+		}
+		product.DescriptiveDetail.Contributors = append(product.DescriptiveDetail.Contributors, contributor)
+	}
+	if !book.PublishedYear.IsZero() {
+		product.PublishingDetail = &onixPublishingDetail{
+			PublishingDates: []onixPublishingDate{{
+				PublishingDateRole: onixDateRolePublication,
+				Date:               book.PublishedYear.Format("20060102"),
+			}},
+		}
+	}
+	return product
+}
+
+// onixIdentifierOf picks the identifier type from the shape of isbn, ISBNs
+// generated by the catalog are hyphenated so separators are dropped first.
+func onixIdentifierOf(isbn string) onixProductIdentifier {
+	digits := strings.NewReplacer("-", "", " ", "").Replace(isbn)
+	switch {
+	case len(digits) == 13 && isDigits(digits):
+		return onixProductIdentifier{ProductIDType: onixIDTypeISBN13, IDValue: digits}
+	case len(digits) == 10 && isDigits(digits[:9]):
+		return onixProductIdentifier{ProductIDType: onixIDTypeISBN10, IDValue: digits}
+	}
+	return onixProductIdentifier{ProductIDType: onixIDTypeProprietary, IDTypeName: "ISBN", IDValue: isbn}
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// isCountryCode reports whether country looks like an ISO 3166-1 alpha-2
+// code, the only form ONIX accepts; free text countries are not exported.
+func isCountryCode(country string) bool {
+	if len(country) != 2 {
+		return false
+	}
+	for _, r := range country {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package formats
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
+)
+
+func TestReadONIX(t *testing.T) {
+	records, err := ReadONIX(bytes.NewReader(readFixture(t, "onix.xml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := validBooks(t, records, 3)
+
+	want := []*entities.Book{
+		{
+			ID:            uuid.MustParse("3f2c9a1e-5b7d-4c8e-9a0b-1d2e3f4a5b6c"),
+			Title:         "Bumi Manusia",
+			Isbn:          "9780306406157",
+			PublishedYear: date(2001, 3, 15),
+			Authors:       []*entities.Author{{Name: "Pramoedya Ananta Toer", Country: "ID"}},
+		},
+		{
+			ID:    uuid.MustParse("7b1e0c4d-2a3f-4e5d-8c6b-9a0f1e2d3c4b"),
+			Title: "The Rainbow Troops",
+			Isbn:  "030640615X",
+			Authors: []*entities.Author{
+				{Name: "Andrea Hirata"},
+				{Name: "Kilbane, Angie"},
+			},
+		},
+	}
+	assertBooks(t, want, got)
+}
+
+func TestONIXRoundTrip(t *testing.T) {
+	records, err := ReadONIX(bytes.NewReader(readFixture(t, "onix.xml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	books := validBooks(t, records, 3)
+
+	out := writeAll(t, FormatONIX, books)
+	records, err = ReadONIX(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("re-reading exported message: %v\n%s", err, out)
+	}
+	assertBooks(t, books, validBooks(t, records))
+
+	if again := writeAll(t, FormatONIX, validBooks(t, records)); !bytes.Equal(out, again) {
+		t.Errorf("second export differs from the first:\n%s\n---\n%s", out, again)
+	}
+}
+
+func TestONIXIdentifierOf(t *testing.T) {
+	tests := []struct {
+		isbn, idType, value string
+	}{
+		{"978-0-306-40615-7", onixIDTypeISBN13, "9780306406157"},
+		{"0-306-40615-X", onixIDTypeISBN10, "030640615X"},
+		{"978-123456789-5", onixIDTypeISBN13, "9781234567895"},
+		{"HM-0001", onixIDTypeProprietary, "HM-0001"},
+	}
+	for _, tt := range tests {
+		got := onixIdentifierOf(tt.isbn)
+		if got.ProductIDType != tt.idType || got.IDValue != tt.value {
+			t.Errorf("onixIdentifierOf(%q) = %s %q, want %s %q", tt.isbn, got.ProductIDType, got.IDValue, tt.idType, tt.value)
+		}
+	}
+}
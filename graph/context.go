@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/aldisaputra17/book-store/apperror"
+)
+
+type contextKey int
+
+const (
+	loadersKey contextKey = iota
+	callerKey
+)
+
+var (
+	errUnauthorized = apperror.Unauthorized(apperror.CodeUnauthorized, "unauthorized: a valid token is required")
+	errForbidden    = apperror.Forbidden(apperror.CodeForbidden, "forbidden: an administrator token is required")
+)
+
+// Caller is who sends an operation: whether they presented a valid token,
+// and whether it is the token of an administrator.
+type Caller struct {
+	Authenticated bool
+	Admin         bool
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey).(*loaders)
+	return l
+}
+
+// requireAdmin lets through mutations sent by an administrator.
+func requireAdmin(ctx context.Context) error {
+	caller, _ := ctx.Value(callerKey).(Caller)
+	switch {
+	case !caller.Authenticated:
+		return errUnauthorized
+	case !caller.Admin:
+		return errForbidden
+	}
+	return nil
+}
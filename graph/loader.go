@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/services"
+)
+
+// loader batches the keys requested while one level of the query is being
+// resolved and fetches them with a single call once the first result is
+// needed. graphql-go resolves every field of a level before running the
+// thunks they return, so a list of N books costs one query for their
+// authors instead of N.
+type loader struct {
+	fetch func(ctx context.Context, keys []string) (map[string]interface{}, error)
+
+	mu      sync.Mutex
+	pending []string
+	queued  map[string]bool
+	results map[string]interface{}
+	errs    map[string]error
+}
+
+func newLoader(fetch func(ctx context.Context, keys []string) (map[string]interface{}, error)) *loader {
+	return &loader{
+		fetch:   fetch,
+		queued:  make(map[string]bool),
+		results: make(map[string]interface{}),
+		errs:    make(map[string]error),
+	}
+}
+
+func (l *loader) load(ctx context.Context, key string) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+			values, err := l.fetch(ctx, keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+					continue
+				}
+				l.results[k] = values[k]
+			}
+		}
+		if err := l.errs[key]; err != nil {
+			return nil, err
+		}
+		return l.results[key], nil
+	}
+}
+
+// loaders are created per request so results are never shared between
+// callers.
+type loaders struct {
+	authorsByBook *loader
+	booksByAuthor *loader
+}
+
+func newLoaders(bookService services.BookService, authorService services.AuthorService) *loaders {
+	return &loaders{
+		authorsByBook: newLoader(func(ctx context.Context, bookIDs []string) (map[string]interface{}, error) {
+			authors, err := authorService.FindByBookIDs(ctx, bookIDs)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[string]interface{}, len(bookIDs))
+			for _, id := range bookIDs {
+				list := authors[id]
+				if list == nil {
+					list = []*dto.AuthorResponse{}
+				}
+				values[id] = list
+			}
+			return values, nil
+		}),
+		booksByAuthor: newLoader(func(ctx context.Context, authorIDs []string) (map[string]interface{}, error) {
+			books, err := bookService.FindByAuthorIDs(ctx, authorIDs)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[string]interface{}, len(authorIDs))
+			for _, id := range authorIDs {
+				list := books[id]
+				if list == nil {
+					list = []*dto.CreateBookResponse{}
+				}
+				values[id] = list
+			}
+			return values, nil
+		}),
+	}
+}
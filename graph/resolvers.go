@@ -0,0 +1,189 @@
+package graph
+
+import (
+	"errors"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+)
+
+type resolver struct {
+	bookService   services.BookService
+	authorService services.AuthorService
+}
+
+func (r *resolver) bookAuthors(p graphql.ResolveParams) (interface{}, error) {
+	return loadersFrom(p.Context).authorsByBook.load(p.Context, idOf(p.Source)), nil
+}
+
+func (r *resolver) authorBooks(p graphql.ResolveParams) (interface{}, error) {
+	return loadersFrom(p.Context).booksByAuthor.load(p.Context, idOf(p.Source)), nil
+}
+
+func (r *resolver) book(p graphql.ResolveParams) (interface{}, error) {
+	id, _ := p.Args["id"].(string)
+	if _, err := parseID(id); err != nil {
+		return nil, err
+	}
+	book, err := r.bookService.FindByID(p.Context, id)
+	if errors.Is(err, repositories.ErrBookNotFound) {
+		return nil, nil
+	}
+	return book, err
+}
+
+func (r *resolver) books(p graphql.ResolveParams) (interface{}, error) {
+	authorID, _ := p.Args["authorId"].(string)
+	name, _ := p.Args["name"].(string)
+	pageNum, pageSize := pageArgs(p.Args)
+	books, total, err := r.bookService.GetBookByCondition(p.Context, authorID, name, pageNum, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return page{Items: books, Pagination: total}, nil
+}
+
+func (r *resolver) author(p graphql.ResolveParams) (interface{}, error) {
+	id, _ := p.Args["id"].(string)
+	if _, err := parseID(id); err != nil {
+		return nil, err
+	}
+	author, err := r.authorService.FindByID(p.Context, id)
+	if errors.Is(err, repositories.ErrAuthorNotFound) {
+		return nil, nil
+	}
+	return author, err
+}
+
+func (r *resolver) authors(p graphql.ResolveParams) (interface{}, error) {
+	bookID, _ := p.Args["bookId"].(string)
+	title, _ := p.Args["title"].(string)
+	pageNum, pageSize := pageArgs(p.Args)
+	authors, total, err := r.authorService.GetAuthorByCondition(p.Context, bookID, title, pageNum, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return page{Items: authors, Pagination: total}, nil
+}
+
+func (r *resolver) createBook(p graphql.ResolveParams) (interface{}, error) {
+	if err := requireAdmin(p.Context); err != nil {
+		return nil, err
+	}
+	bookReq := &dto.CreateBookRequest{}
+	bookReq.Title, _ = p.Args["title"].(string)
+	for _, id := range p.Args["authorIds"].([]interface{}) {
+		bookReq.AuthorID = append(bookReq.AuthorID, id.(string))
+	}
+	if err := helper.ValidateStruct(bookReq); err != nil {
+		return nil, err
+	}
+	return r.bookService.Create(p.Context, bookReq)
+}
+
+func (r *resolver) updateBook(p graphql.ResolveParams) (interface{}, error) {
+	if err := requireAdmin(p.Context); err != nil {
+		return nil, err
+	}
+	id, err := parseID(p.Args["id"].(string))
+	if err != nil {
+		return nil, err
+	}
+	bookReq := &dto.UpdateBookRequest{ID: id}
+	bookReq.Title, _ = p.Args["title"].(string)
+	if err := helper.ValidateStruct(bookReq); err != nil {
+		return nil, err
+	}
+	if _, err := r.bookService.Update(p.Context, bookReq); err != nil {
+		return nil, err
+	}
+	return r.bookService.FindByID(p.Context, id.String())
+}
+
+func (r *resolver) deleteBook(p graphql.ResolveParams) (interface{}, error) {
+	if err := requireAdmin(p.Context); err != nil {
+		return nil, err
+	}
+	id, err := parseID(p.Args["id"].(string))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.bookService.Delete(p.Context, entities.Book{ID: id}); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func (r *resolver) createAuthor(p graphql.ResolveParams) (interface{}, error) {
+	if err := requireAdmin(p.Context); err != nil {
+		return nil, err
+	}
+	authorReq := &dto.CreateAuthorRequest{}
+	authorReq.Name, _ = p.Args["name"].(string)
+	authorReq.Country, _ = p.Args["country"].(string)
+	if err := helper.ValidateStruct(authorReq); err != nil {
+		return nil, err
+	}
+	return r.authorService.Create(p.Context, authorReq)
+}
+
+func (r *resolver) updateAuthor(p graphql.ResolveParams) (interface{}, error) {
+	if err := requireAdmin(p.Context); err != nil {
+		return nil, err
+	}
+	id, err := parseID(p.Args["id"].(string))
+	if err != nil {
+		return nil, err
+	}
+	authorReq := &dto.UpdateAuthorRequest{ID: id}
+	authorReq.Name, _ = p.Args["name"].(string)
+	authorReq.Country, _ = p.Args["country"].(string)
+	if err := helper.ValidateStruct(authorReq); err != nil {
+		return nil, err
+	}
+	if _, err := r.authorService.Update(p.Context, authorReq); err != nil {
+		return nil, err
+	}
+	return r.authorService.FindByID(p.Context, id.String())
+}
+
+func (r *resolver) deleteAuthor(p graphql.ResolveParams) (interface{}, error) {
+	if err := requireAdmin(p.Context); err != nil {
+		return nil, err
+	}
+	id, err := parseID(p.Args["id"].(string))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.authorService.Delete(p.Context, entities.Author{ID: id}); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func parseID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, apperror.BadRequest(apperror.CodeInvalidID, "invalid id", err)
+	}
+	return parsed, nil
+}
+
+// pageArgs applies the same defaults as the REST list endpoints.
+func pageArgs(args map[string]interface{}) (int, int) {
+	pageNum, _ := args["page"].(int)
+	if pageNum < 1 {
+		pageNum = 1
+	}
+	pageSize, _ := args["pageSize"].(int)
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	return pageNum, pageSize
+}
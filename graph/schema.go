@@ -0,0 +1,232 @@
+// Package graph exposes books and authors over GraphQL, resolving through
+// the same services as the REST controllers.
+package graph
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// Schema is the executable GraphQL schema.
+type Schema struct {
+	schema        graphql.Schema
+	bookService   services.BookService
+	authorService services.AuthorService
+}
+
+// page is the source of the BookPage and AuthorPage types.
+type page struct {
+	Items      interface{}
+	Pagination entities.Pagination
+}
+
+func NewSchema(bookService services.BookService, authorService services.AuthorService) (*Schema, error) {
+	r := &resolver{bookService: bookService, authorService: authorService}
+
+	var bookType, authorType *graphql.Object
+	bookType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Book",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":            &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"title":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"isbn":          &graphql.Field{Type: graphql.String},
+				"publishedYear": &graphql.Field{Type: graphql.DateTime},
+				"authors": &graphql.Field{
+					Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(authorType))),
+					Resolve: r.bookAuthors,
+				},
+			}
+		}),
+	})
+	authorType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Author",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"country": &graphql.Field{Type: graphql.String},
+				"books": &graphql.Field{
+					Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(bookType))),
+					Resolve: r.authorBooks,
+				},
+			}
+		}),
+	})
+	paginationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Pagination",
+		Fields: graphql.Fields{
+			"totalRecords": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"totalPages":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"currentPage":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"pageSize":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+	pageOf := func(name string, item *graphql.Object) *graphql.Object {
+		return graphql.NewObject(graphql.ObjectConfig{
+			Name: name,
+			Fields: graphql.Fields{
+				"items":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(item)))},
+				"pagination": &graphql.Field{Type: graphql.NewNonNull(paginationType)},
+			},
+		})
+	}
+	pageArgs := func(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args["page"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1}
+		args["pageSize"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 10}
+		return args
+	}
+	idArg := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"book": &graphql.Field{
+				Type:    bookType,
+				Args:    idArg,
+				Resolve: r.book,
+			},
+			"books": &graphql.Field{
+				Type: graphql.NewNonNull(pageOf("BookPage", bookType)),
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"authorId": &graphql.ArgumentConfig{Type: graphql.ID},
+					"name":     &graphql.ArgumentConfig{Type: graphql.String},
+				}),
+				Resolve: r.books,
+			},
+			"author": &graphql.Field{
+				Type:    authorType,
+				Args:    idArg,
+				Resolve: r.author,
+			},
+			"authors": &graphql.Field{
+				Type: graphql.NewNonNull(pageOf("AuthorPage", authorType)),
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"bookId": &graphql.ArgumentConfig{Type: graphql.ID},
+					"title":  &graphql.ArgumentConfig{Type: graphql.String},
+				}),
+				Resolve: r.authors,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createBook": &graphql.Field{
+				Type: graphql.NewNonNull(bookType),
+				Args: graphql.FieldConfigArgument{
+					"title":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"authorIds": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+				},
+				Resolve: r.createBook,
+			},
+			"updateBook": &graphql.Field{
+				Type: graphql.NewNonNull(bookType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"title": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.updateBook,
+			},
+			"deleteBook": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    idArg,
+				Resolve: r.deleteBook,
+			},
+			"createAuthor": &graphql.Field{
+				Type: graphql.NewNonNull(authorType),
+				Args: graphql.FieldConfigArgument{
+					"name":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"country": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.createAuthor,
+			},
+			"updateAuthor": &graphql.Field{
+				Type: graphql.NewNonNull(authorType),
+				Args: graphql.FieldConfigArgument{
+					"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"name":    &graphql.ArgumentConfig{Type: graphql.String},
+					"country": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.updateAuthor,
+			},
+			"deleteAuthor": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    idArg,
+				Resolve: r.deleteAuthor,
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{schema: schema, bookService: bookService, authorService: authorService}, nil
+}
+
+// Execute runs one GraphQL operation for caller. Mutations require an
+// administrator. Resolver errors report their apperror code under
+// extensions.code.
+func (s *Schema) Execute(ctx context.Context, query string, operationName string, variables map[string]interface{}, caller Caller) *graphql.Result {
+	ctx = context.WithValue(ctx, loadersKey, newLoaders(s.bookService, s.authorService))
+	ctx = context.WithValue(ctx, callerKey, caller)
+	res := graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  query,
+		OperationName:  operationName,
+		VariableValues: variables,
+		Context:        ctx,
+	})
+	for i := range res.Errors {
+		if gqlErr, ok := res.Errors[i].OriginalError().(*gqlerrors.Error); ok && gqlErr.OriginalError != nil {
+			res.Errors[i].Extensions = map[string]interface{}{"code": apperror.From(gqlErr.OriginalError).Code}
+		}
+	}
+	return res
+}
+
+// IsQuery reports whether the operation of query selected by operationName
+// is a query, which is all that may be sent with GET. A document that does
+// not parse or has no such operation is left for Execute to reject.
+func IsQuery(query string, operationName string) bool {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return true
+	}
+	var operations []*ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		if op, ok := definition.(*ast.OperationDefinition); ok {
+			if operationName == "" || (op.Name != nil && op.Name.Value == operationName) {
+				operations = append(operations, op)
+			}
+		}
+	}
+	if len(operations) != 1 {
+		return true
+	}
+	return operations[0].Operation == ast.OperationTypeQuery
+}
+
+// idOf reads the ID field of the dto a Book or Author was resolved from.
+func idOf(source interface{}) string {
+	v := reflect.Indirect(reflect.ValueOf(source))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if id := v.FieldByName("ID"); id.IsValid() && id.Kind() == reflect.String {
+		return id.String()
+	}
+	return ""
+}
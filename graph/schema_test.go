@@ -0,0 +1,211 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/google/uuid"
+)
+
+// countingAuthors counts the batched author lookups.
+type countingAuthors struct {
+	services.AuthorService
+	batches int
+}
+
+func (s *countingAuthors) FindByBookIDs(ctx context.Context, bookIDs []string) (map[string][]*dto.AuthorResponse, error) {
+	s.batches++
+	return s.AuthorService.FindByBookIDs(ctx, bookIDs)
+}
+
+// failingBooks fails every lookup the way a broken database would.
+type failingBooks struct {
+	services.BookService
+}
+
+func (failingBooks) FindByID(ctx context.Context, id string) (*dto.ReadBookResponse, error) {
+	return nil, errors.New("connection refused")
+}
+
+type fixture struct {
+	schema  *Schema
+	books   services.BookService
+	authors *countingAuthors
+}
+
+// newFixture returns a schema on in-memory repositories. wrap, if given,
+// replaces the book service the schema uses.
+func newFixture(t *testing.T, wrap func(services.BookService) services.BookService) *fixture {
+	t.Helper()
+	store := repositories.NewMemoryStore()
+	authorRepo := repositories.NewMemoryAuthorRepository(store)
+	f := &fixture{
+		books:   services.NewBookService(repositories.NewMemoryBookRepository(store), authorRepo, time.Second),
+		authors: &countingAuthors{AuthorService: services.NewAuthorService(authorRepo, time.Second)},
+	}
+	books := f.books
+	if wrap != nil {
+		books = wrap(books)
+	}
+	schema, err := NewSchema(books, f.authors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.schema = schema
+	return f
+}
+
+func (f *fixture) createAuthor(t *testing.T, name string) string {
+	t.Helper()
+	res, err := f.authors.Create(context.Background(), &dto.CreateAuthorRequest{Name: name, Country: "GB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.ID
+}
+
+func (f *fixture) createBook(t *testing.T, title string, authorIDs ...string) string {
+	t.Helper()
+	res, err := f.books.Create(context.Background(), &dto.CreateBookRequest{Title: title, AuthorID: authorIDs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.ID
+}
+
+// execute runs query for caller and decodes its data into v, returning the
+// error codes.
+func (f *fixture) execute(t *testing.T, query string, caller Caller, v interface{}) []string {
+	t.Helper()
+	res := f.schema.Execute(context.Background(), query, "", nil, caller)
+	var codes []string
+	for _, err := range res.Errors {
+		code, _ := err.Extensions["code"].(string)
+		codes = append(codes, code)
+	}
+	if v != nil {
+		data, err := json.Marshal(res.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return codes
+}
+
+func TestAuthorsAreBatched(t *testing.T) {
+	f := newFixture(t, nil)
+	pratchett, gaiman := f.createAuthor(t, "Terry Pratchett"), f.createAuthor(t, "Neil Gaiman")
+	f.createBook(t, "Mort", pratchett)
+	f.createBook(t, "Coraline", gaiman)
+	f.createBook(t, "Good Omens", pratchett, gaiman)
+
+	var data struct {
+		Books struct {
+			Items []struct {
+				Title   string
+				Authors []struct{ Name string }
+			}
+		}
+	}
+	if codes := f.execute(t, `{ books { items { title authors { name } } } }`, Caller{}, &data); codes != nil {
+		t.Fatalf("errors %v", codes)
+	}
+	if len(data.Books.Items) < 3 {
+		t.Fatalf("books %+v", data.Books.Items)
+	}
+	for _, book := range data.Books.Items {
+		if len(book.Authors) == 0 {
+			t.Errorf("%s has no authors", book.Title)
+		}
+	}
+	if f.authors.batches != 1 {
+		t.Errorf("authors looked up %d times, want once", f.authors.batches)
+	}
+}
+
+func TestMissingRecordIsNull(t *testing.T) {
+	f := newFixture(t, nil)
+	var data map[string]interface{}
+	query := `{ book(id: "` + uuid.NewString() + `") { id } author(id: "` + uuid.NewString() + `") { id } }`
+	if codes := f.execute(t, query, Caller{}, &data); codes != nil {
+		t.Fatalf("errors %v", codes)
+	}
+	if data["book"] != nil || data["author"] != nil {
+		t.Errorf("data %+v", data)
+	}
+}
+
+func TestLookupFailureIsReported(t *testing.T) {
+	f := newFixture(t, func(books services.BookService) services.BookService {
+		return failingBooks{BookService: books}
+	})
+	codes := f.execute(t, `{ book(id: "`+uuid.NewString()+`") { id } }`, Caller{}, nil)
+	if len(codes) != 1 || codes[0] != apperror.CodeInternal {
+		t.Errorf("codes %v, want %s", codes, apperror.CodeInternal)
+	}
+}
+
+func TestMutations(t *testing.T) {
+	f := newFixture(t, nil)
+	author := f.createAuthor(t, "Terry Pratchett")
+	book := f.createBook(t, "Mort", author)
+	missing := uuid.NewString()
+
+	admin := Caller{Authenticated: true, Admin: true}
+	cases := []struct {
+		name, query string
+		caller      Caller
+		code        string
+	}{
+		{"anonymous", `mutation { deleteBook(id: "` + book + `") }`, Caller{}, apperror.CodeUnauthorized},
+		{"not an administrator", `mutation { deleteBook(id: "` + book + `") }`, Caller{Authenticated: true}, apperror.CodeForbidden},
+		{"invalid id", `mutation { deleteBook(id: "42") }`, admin, apperror.CodeInvalidID},
+		{"update missing book", `mutation { updateBook(id: "` + missing + `", title: "Mort") { id } }`, admin, apperror.CodeBookNotFound},
+		{"delete missing book", `mutation { deleteBook(id: "` + missing + `") }`, admin, apperror.CodeBookNotFound},
+		{"update missing author", `mutation { updateAuthor(id: "` + missing + `", name: "Nobody") { id } }`, admin, apperror.CodeAuthorNotFound},
+		{"delete missing author", `mutation { deleteAuthor(id: "` + missing + `") }`, admin, apperror.CodeAuthorNotFound},
+		{"update book", `mutation { updateBook(id: "` + book + `", title: "Mort (revised)") { title } }`, admin, ""},
+		{"delete book", `mutation { deleteBook(id: "` + book + `") }`, admin, ""},
+		{"delete author", `mutation { deleteAuthor(id: "` + author + `") }`, admin, ""},
+	}
+	for _, c := range cases {
+		codes := f.execute(t, c.query, c.caller, nil)
+		switch {
+		case c.code == "" && codes != nil:
+			t.Errorf("%s: errors %v", c.name, codes)
+		case c.code != "" && (len(codes) != 1 || codes[0] != c.code):
+			t.Errorf("%s: codes %v, want %s", c.name, codes, c.code)
+		}
+	}
+}
+
+func TestIsQuery(t *testing.T) {
+	tests := []struct {
+		query, operationName string
+		want                 bool
+	}{
+		{`{ books { items { id } } }`, "", true},
+		{`query Books { books { items { id } } }`, "", true},
+		{`mutation { deleteBook(id: "1") }`, "", false},
+		{`query A { books { items { id } } } mutation B { deleteBook(id: "1") }`, "A", true},
+		{`query A { books { items { id } } } mutation B { deleteBook(id: "1") }`, "B", false},
+		// Left for Execute to reject.
+		{`query A { books { items { id } } } mutation B { deleteBook(id: "1") }`, "", true},
+		{`mutation {`, "", true},
+	}
+	for _, tt := range tests {
+		if got := IsQuery(tt.query, tt.operationName); got != tt.want {
+			t.Errorf("IsQuery(%q, %q) = %v, want %v", tt.query, tt.operationName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/helper"
+	pb "github.com/aldisaputra17/book-store/proto/bookstore/v1"
+	"github.com/aldisaputra17/book-store/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const authorizationKey = "authorization"
+
+// publicMethods are the RPCs that, like their REST counterparts, do not
+// need a token. The others are writes and need the token of an
+// administrator.
+var publicMethods = map[string]bool{
+	pb.AuthService_Register_FullMethodName:      true,
+	pb.AuthService_Login_FullMethodName:         true,
+	pb.BookService_GetBook_FullMethodName:       true,
+	pb.BookService_ListBooks_FullMethodName:     true,
+	pb.AuthorService_GetAuthor_FullMethodName:   true,
+	pb.AuthorService_ListAuthors_FullMethodName: true,
+}
+
+func isPublic(fullMethod string) bool {
+	return publicMethods[fullMethod] || strings.HasPrefix(fullMethod, "/grpc.reflection.")
+}
+
+func AuthUnaryInterceptor(jwtService services.JWTService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !isPublic(info.FullMethod) {
+			userID, err := authorize(ctx, jwtService)
+			if err != nil {
+				return nil, err
+			}
+			ctx = helper.WithLogger(ctx, helper.Logger(ctx).WithField("user_id", userID))
+		}
+		return handler(ctx, req)
+	}
+}
+
+func AuthStreamInterceptor(jwtService services.JWTService) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !isPublic(info.FullMethod) {
+			if _, err := authorize(ss.Context(), jwtService); err != nil {
+				return err
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authorize validates the token carried in the "authorization" metadata,
+// with or without a "Bearer " prefix, checks that it is the token of an
+// administrator and returns its user ID.
+func authorize(ctx context.Context, jwtService services.JWTService) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationKey)
+	if len(values) == 0 || values[0] == "" {
+		return "", apperror.Unauthorized(apperror.CodeMissingToken, "no token found")
+	}
+	token, err := jwtService.ValidateToken(strings.TrimPrefix(values[0], "Bearer "))
+	if err != nil || !token.Valid {
+		return "", apperror.Unauthorized(apperror.CodeInvalidToken, "token is not valid")
+	}
+	if !services.TokenAdmin(token) {
+		return "", apperror.Forbidden(apperror.CodeForbidden, "administrator token required")
+	}
+	return services.TokenUserID(token), nil
+}
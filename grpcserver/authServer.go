@@ -0,0 +1,64 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/helper"
+	pb "github.com/aldisaputra17/book-store/proto/bookstore/v1"
+	"github.com/aldisaputra17/book-store/services"
+)
+
+type authServer struct {
+	pb.UnimplementedAuthServiceServer
+	authService services.AuthService
+	jwtService  services.JWTService
+}
+
+func NewAuthServer(authService services.AuthService, jwtService services.JWTService) pb.AuthServiceServer {
+	return &authServer{
+		authService: authService,
+		jwtService:  jwtService,
+	}
+}
+
+func (s *authServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	registerReq := &dto.AuthRequest{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	}
+	if err := helper.ValidateStruct(registerReq); err != nil {
+		return nil, err
+	}
+	if !s.authService.IsDuplicateEmail(registerReq.Email) {
+		return nil, apperror.Conflict(apperror.CodeEmailTaken, "duplicate email")
+	}
+	user, err := s.authService.Register(ctx, registerReq)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RegisterResponse{
+		User:  toUser(user),
+		Token: s.jwtService.GenerateToken(user.ID, user.Admin),
+	}, nil
+}
+
+func (s *authServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	loginReq := &dto.AuthRequest{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	}
+	if err := helper.ValidateStruct(loginReq); err != nil {
+		return nil, err
+	}
+	user, ok := s.authService.VerifyCredential(loginReq.Email, loginReq.Password).(entities.User)
+	if !ok {
+		return nil, apperror.Unauthorized(apperror.CodeInvalidCredentials, "invalid credential")
+	}
+	return &pb.LoginResponse{
+		User:  toUser(&user),
+		Token: s.jwtService.GenerateToken(user.ID, user.Admin),
+	}, nil
+}
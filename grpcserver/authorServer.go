@@ -0,0 +1,100 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/helper"
+	pb "github.com/aldisaputra17/book-store/proto/bookstore/v1"
+	"github.com/aldisaputra17/book-store/services"
+)
+
+type authorServer struct {
+	pb.UnimplementedAuthorServiceServer
+	authorService services.AuthorService
+}
+
+func NewAuthorServer(authorService services.AuthorService) pb.AuthorServiceServer {
+	return &authorServer{
+		authorService: authorService,
+	}
+}
+
+func (s *authorServer) CreateAuthor(ctx context.Context, req *pb.CreateAuthorRequest) (*pb.CreateAuthorResponse, error) {
+	authorReq := &dto.CreateAuthorRequest{
+		Name:    req.GetName(),
+		Country: req.GetCountry(),
+	}
+	if err := helper.ValidateStruct(authorReq); err != nil {
+		return nil, err
+	}
+	res, err := s.authorService.Create(ctx, authorReq)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateAuthorResponse{Author: toAuthorSummary(res)}, nil
+}
+
+func (s *authorServer) UpdateAuthor(ctx context.Context, req *pb.UpdateAuthorRequest) (*pb.UpdateAuthorResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	authorReq := &dto.UpdateAuthorRequest{
+		ID:      id,
+		Name:    req.GetName(),
+		Country: req.GetCountry(),
+	}
+	if err := helper.ValidateStruct(authorReq); err != nil {
+		return nil, err
+	}
+	res, err := s.authorService.Update(ctx, authorReq)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateAuthorResponse{Author: &pb.AuthorSummary{
+		Id:      res.ID,
+		Name:    res.Name,
+		Country: res.Country,
+	}}, nil
+}
+
+func (s *authorServer) DeleteAuthor(ctx context.Context, req *pb.DeleteAuthorRequest) (*pb.DeleteAuthorResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorService.Delete(ctx, entities.Author{ID: id}); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteAuthorResponse{}, nil
+}
+
+func (s *authorServer) GetAuthor(ctx context.Context, req *pb.GetAuthorRequest) (*pb.GetAuthorResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.authorService.FindByID(ctx, id.String())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetAuthorResponse{Author: toAuthor(res)}, nil
+}
+
+func (s *authorServer) ListAuthors(ctx context.Context, req *pb.ListAuthorsRequest) (*pb.ListAuthorsResponse, error) {
+	page, pageSize := pageArgs(req.GetPage(), req.GetPageSize())
+	authors, total, err := s.authorService.GetAuthorByCondition(ctx, req.GetBookId(), req.GetTitle(), page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.ListAuthorsResponse{
+		Authors:    make([]*pb.Author, 0, len(authors)),
+		Pagination: toPagination(total),
+	}
+	for i := range authors {
+		res.Authors = append(res.Authors, toAuthor(&authors[i]))
+	}
+	return res, nil
+}
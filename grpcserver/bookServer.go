@@ -0,0 +1,95 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/helper"
+	pb "github.com/aldisaputra17/book-store/proto/bookstore/v1"
+	"github.com/aldisaputra17/book-store/services"
+)
+
+type bookServer struct {
+	pb.UnimplementedBookServiceServer
+	bookService services.BookService
+}
+
+func NewBookServer(bookService services.BookService) pb.BookServiceServer {
+	return &bookServer{
+		bookService: bookService,
+	}
+}
+
+func (s *bookServer) CreateBook(ctx context.Context, req *pb.CreateBookRequest) (*pb.CreateBookResponse, error) {
+	bookReq := &dto.CreateBookRequest{
+		Title:    req.GetTitle(),
+		AuthorID: req.GetAuthorIds(),
+	}
+	if err := helper.ValidateStruct(bookReq); err != nil {
+		return nil, err
+	}
+	res, err := s.bookService.Create(ctx, bookReq)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateBookResponse{Book: toBookSummary(res)}, nil
+}
+
+func (s *bookServer) UpdateBook(ctx context.Context, req *pb.UpdateBookRequest) (*pb.UpdateBookResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	bookReq := &dto.UpdateBookRequest{
+		ID:    id,
+		Title: req.GetTitle(),
+	}
+	if err := helper.ValidateStruct(bookReq); err != nil {
+		return nil, err
+	}
+	res, err := s.bookService.Update(ctx, bookReq)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateBookResponse{Id: res.ID, Title: res.Title}, nil
+}
+
+func (s *bookServer) DeleteBook(ctx context.Context, req *pb.DeleteBookRequest) (*pb.DeleteBookResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.bookService.Delete(ctx, entities.Book{ID: id}); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteBookResponse{}, nil
+}
+
+func (s *bookServer) GetBook(ctx context.Context, req *pb.GetBookRequest) (*pb.GetBookResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.bookService.FindByID(ctx, id.String())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetBookResponse{Book: toBook(res)}, nil
+}
+
+func (s *bookServer) ListBooks(ctx context.Context, req *pb.ListBooksRequest) (*pb.ListBooksResponse, error) {
+	page, pageSize := pageArgs(req.GetPage(), req.GetPageSize())
+	books, total, err := s.bookService.GetBookByCondition(ctx, req.GetAuthorId(), req.GetName(), page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	res := &pb.ListBooksResponse{
+		Books:      make([]*pb.Book, 0, len(books)),
+		Pagination: toPagination(total),
+	}
+	for i := range books {
+		res.Books = append(res.Books, toBook(&books[i]))
+	}
+	return res, nil
+}
@@ -0,0 +1,96 @@
+package grpcserver
+
+import (
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	pb "github.com/aldisaputra17/book-store/proto/bookstore/v1"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPage     = 1
+	defaultPageSize = 10
+)
+
+func parseID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, apperror.BadRequest(apperror.CodeInvalidID, "invalid id", err)
+	}
+	return parsed, nil
+}
+
+// pageArgs applies the same defaults as the REST list endpoints.
+func pageArgs(page, pageSize int32) (int, int) {
+	if page < 1 {
+		page = defaultPage
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	return int(page), int(pageSize)
+}
+
+func toPagination(p entities.Pagination) *pb.Pagination {
+	return &pb.Pagination{
+		TotalRecords: int32(p.TotalRecords),
+		TotalPages:   int32(p.TotalPages),
+		CurrentPage:  int32(p.CurrentPage),
+		PageSize:     int32(p.PageSize),
+	}
+}
+
+func toBookSummary(book *dto.CreateBookResponse) *pb.BookSummary {
+	return &pb.BookSummary{
+		Id:            book.ID,
+		Title:         book.Title,
+		PublishedYear: timestamppb.New(book.PublishedYear),
+		Isbn:          book.Isbn,
+	}
+}
+
+func toAuthorSummary(author *dto.AuthorResponse) *pb.AuthorSummary {
+	return &pb.AuthorSummary{
+		Id:      author.ID,
+		Name:    author.Name,
+		Country: author.Country,
+	}
+}
+
+func toBook(book *dto.ReadBookResponse) *pb.Book {
+	authors := make([]*pb.AuthorSummary, 0, len(book.Author))
+	for _, author := range book.Author {
+		authors = append(authors, toAuthorSummary(author))
+	}
+	return &pb.Book{
+		Id:            book.ID,
+		Title:         book.Title,
+		PublishedYear: timestamppb.New(book.PublishedYear),
+		Isbn:          book.Isbn,
+		Authors:       authors,
+	}
+}
+
+func toAuthor(author *dto.ReadAuthorResponse) *pb.Author {
+	books := make([]*pb.BookSummary, 0, len(author.Book))
+	for _, book := range author.Book {
+		books = append(books, toBookSummary(book))
+	}
+	return &pb.Author{
+		Id:      author.ID,
+		Name:    author.Name,
+		Country: author.Country,
+		Books:   books,
+	}
+}
+
+func toUser(user *entities.User) *pb.User {
+	return &pb.User{
+		Id:        user.ID.String(),
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}
+}
@@ -0,0 +1,110 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/helper"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var kindCode = map[apperror.Kind]codes.Code{
+	apperror.KindInternal:     codes.Internal,
+	apperror.KindBadRequest:   codes.InvalidArgument,
+	apperror.KindValidation:   codes.InvalidArgument,
+	apperror.KindNotFound:     codes.NotFound,
+	apperror.KindConflict:     codes.AlreadyExists,
+	apperror.KindForbidden:    codes.PermissionDenied,
+	apperror.KindUnauthorized: codes.Unauthenticated,
+	apperror.KindTooLarge:     codes.ResourceExhausted,
+}
+
+// ErrorUnaryInterceptor turns errors returned by handlers into gRPC
+// statuses, logging the ones that end up as Internal.
+func ErrorUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		res, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatus(ctx, info.FullMethod, err)
+		}
+		return res, nil
+	}
+}
+
+func ErrorStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return toStatus(ss.Context(), info.FullMethod, err)
+		}
+		return nil
+	}
+}
+
+// internalMessage is all clients learn of an internal error; the details
+// are logged.
+const internalMessage = "internal error"
+
+// toStatus sends the message of the apperror, never the error itself, whose
+// causes may be driver errors.
+func toStatus(ctx context.Context, fullMethod string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := statusCode(err)
+	switch code {
+	case codes.Internal:
+		helper.Log(ctx, log.ErrorLevel, err, fullMethod, "err grpc handler")
+		return status.Error(code, internalMessage)
+	case codes.DeadlineExceeded:
+		return status.Error(code, "deadline exceeded")
+	case codes.Canceled:
+		return status.Error(code, "request canceled")
+	}
+	message := apperror.From(err).Message
+	if fields := helper.FieldErrors(err, acceptLanguage(ctx)); fields != nil {
+		return validationStatus(code, message, fields)
+	}
+	return status.Error(code, message)
+}
+
+// validationStatus carries failed validation rules as a BadRequest detail.
+func validationStatus(code codes.Code, message string, fields []helper.FieldError) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+	for _, field := range fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: field.Message,
+		})
+	}
+	st, err := status.New(code, message).WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(code, message)
+	}
+	return st.Err()
+}
+
+func acceptLanguage(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return strings.Join(md.Get("accept-language"), ",")
+}
+
+// statusCode maps context errors and apperror kinds to gRPC codes.
+func statusCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	}
+	if code, ok := kindCode[apperror.KindOf(err)]; ok {
+		return code
+	}
+	return codes.Internal
+}
@@ -0,0 +1,45 @@
+package grpcserver
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/aldisaputra17/book-store/helper"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryUnaryInterceptor turns a panic in a later handler into an
+// Internal status, and logs the panic with its stack trace, like the HTTP
+// Recovery middleware. Without it a panic takes the whole server down.
+func RecoveryUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = recovered(ctx, info.FullMethod, rec)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func RecoveryStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = recovered(ss.Context(), info.FullMethod, rec)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, fullMethod string, rec interface{}) error {
+	helper.Logger(ctx).WithFields(log.Fields{
+		"method": fullMethod,
+		"stack":  string(debug.Stack()),
+	}).Errorf("panic: %v", rec)
+	return status.Error(codes.Internal, internalMessage)
+}
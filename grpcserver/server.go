@@ -0,0 +1,23 @@
+package grpcserver
+
+import (
+	pb "github.com/aldisaputra17/book-store/proto/bookstore/v1"
+	"github.com/aldisaputra17/book-store/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer returns a gRPC server exposing the book, author and auth
+// services behind the recovery, error-mapping and JWT interceptors, with
+// server reflection registered for tools like grpcurl.
+func NewServer(bookService services.BookService, authorService services.AuthorService, authService services.AuthService, jwtService services.JWTService, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(RecoveryUnaryInterceptor(), ErrorUnaryInterceptor(), AuthUnaryInterceptor(jwtService)),
+		grpc.ChainStreamInterceptor(RecoveryStreamInterceptor(), ErrorStreamInterceptor(), AuthStreamInterceptor(jwtService)),
+	}, opts...)...)
+	pb.RegisterBookServiceServer(server, NewBookServer(bookService))
+	pb.RegisterAuthorServiceServer(server, NewAuthorServer(authorService))
+	pb.RegisterAuthServiceServer(server, NewAuthServer(authService, jwtService))
+	reflection.Register(server)
+	return server
+}
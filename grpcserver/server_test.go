@@ -0,0 +1,229 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	pb "github.com/aldisaputra17/book-store/proto/bookstore/v1"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type stubBookService struct {
+	services.BookService
+	created *dto.CreateBookRequest
+}
+
+func (s *stubBookService) FindByID(ctx context.Context, id string) (*dto.ReadBookResponse, error) {
+	return nil, repositories.ErrBookNotFound
+}
+
+func (s *stubBookService) Update(ctx context.Context, bookReq *dto.UpdateBookRequest) (*dto.UpdateBookResponse, error) {
+	return nil, repositories.ErrBookNotFound
+}
+
+func (s *stubBookService) Delete(ctx context.Context, book entities.Book) error {
+	return repositories.ErrBookNotFound
+}
+
+func (s *stubBookService) Create(ctx context.Context, bookReq *dto.CreateBookRequest) (*dto.CreateBookResponse, error) {
+	s.created = bookReq
+	return &dto.CreateBookResponse{ID: uuid.NewString(), Title: bookReq.Title}, nil
+}
+
+func newTestClient(t *testing.T, bookService services.BookService) (*grpc.ClientConn, services.JWTService) {
+	t.Helper()
+	jwtService := services.NewJWTService(config.Default().JWT)
+	server := NewServer(bookService, nil, nil, jwtService)
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, jwtService
+}
+
+func TestCreateBookRequiresAdminToken(t *testing.T) {
+	books := &stubBookService{}
+	conn, jwtService := newTestClient(t, books)
+	client := pb.NewBookServiceClient(conn)
+	req := &pb.CreateBookRequest{Title: "Dune", AuthorIds: []string{uuid.NewString()}}
+
+	_, err := client.CreateBook(context.Background(), req)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("without token: got %v, want Unauthenticated", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer "+jwtService.GenerateToken(uuid.New(), false))
+	if _, err := client.CreateBook(ctx, req); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("without admin token: got %v, want PermissionDenied", err)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer "+jwtService.GenerateToken(uuid.New(), true))
+	res, err := client.CreateBook(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetBook().GetTitle() != "Dune" || books.created == nil {
+		t.Fatalf("book not created: %v", res)
+	}
+}
+
+func TestCreateBookValidatesRequest(t *testing.T) {
+	conn, jwtService := newTestClient(t, &stubBookService{})
+	client := pb.NewBookServiceClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), authorizationKey, jwtService.GenerateToken(uuid.New(), true))
+	_, err := client.CreateBook(ctx, &pb.CreateBookRequest{AuthorIds: []string{uuid.NewString()}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("got %v, want InvalidArgument", err)
+	}
+	details := status.Convert(err).Details()
+	if len(details) != 1 {
+		t.Fatalf("got details %v, want one BadRequest", details)
+	}
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	if !ok || len(badRequest.FieldViolations) == 0 || badRequest.FieldViolations[0].Field == "" {
+		t.Errorf("unexpected details %v", details[0])
+	}
+}
+
+func TestGetBookMapsErrors(t *testing.T) {
+	conn, _ := newTestClient(t, &stubBookService{})
+	client := pb.NewBookServiceClient(conn)
+
+	_, err := client.GetBook(context.Background(), &pb.GetBookRequest{Id: "abc"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("bad id: got %v, want InvalidArgument", err)
+	}
+	_, err = client.GetBook(context.Background(), &pb.GetBookRequest{Id: uuid.NewString()})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("missing book: got %v, want NotFound", err)
+	}
+}
+
+func TestWritesToMissingBooksAreNotFound(t *testing.T) {
+	conn, jwtService := newTestClient(t, &stubBookService{})
+	client := pb.NewBookServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), authorizationKey, jwtService.GenerateToken(uuid.New(), true))
+
+	_, err := client.UpdateBook(ctx, &pb.UpdateBookRequest{Id: uuid.NewString(), Title: "Dune"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("update: got %v, want NotFound", err)
+	}
+	_, err = client.DeleteBook(ctx, &pb.DeleteBookRequest{Id: uuid.NewString()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("delete: got %v, want NotFound", err)
+	}
+}
+
+// brokenBookService fails lookups with a driver error, or panics.
+type brokenBookService struct {
+	stubBookService
+	panics bool
+}
+
+func (s *brokenBookService) FindByID(ctx context.Context, id string) (*dto.ReadBookResponse, error) {
+	if s.panics {
+		var books map[string]*dto.ReadBookResponse
+		books[id].Title = "boom"
+	}
+	return nil, errors.New(`pq: relation "books" does not exist`)
+}
+
+func TestInternalErrorsAreHidden(t *testing.T) {
+	for _, panics := range []bool{false, true} {
+		conn, _ := newTestClient(t, &brokenBookService{panics: panics})
+		client := pb.NewBookServiceClient(conn)
+
+		_, err := client.GetBook(context.Background(), &pb.GetBookRequest{Id: uuid.NewString()})
+		if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != internalMessage {
+			t.Errorf("panics %v: got %v, want Internal %q", panics, err, internalMessage)
+		}
+		// The server is still serving.
+		_, err = client.GetBook(context.Background(), &pb.GetBookRequest{Id: "abc"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("panics %v: next call got %v", panics, err)
+		}
+	}
+}
+
+func TestClientErrorsSendTheirMessage(t *testing.T) {
+	err := toStatus(context.Background(), "/test", apperror.Wrap(apperror.KindConflict, apperror.CodeConflict, "record already exists",
+		errors.New(`ERROR: duplicate key value violates unique constraint "books_pkey"`)))
+	if st := status.Convert(err); st.Code() != codes.AlreadyExists || st.Message() != "record already exists" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestReflectionListsServices(t *testing.T) {
+	conn, _ := newTestClient(t, &stubBookService{})
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, service := range res.GetListServicesResponse().GetService() {
+		found[service.GetName()] = true
+	}
+	for _, name := range []string{pb.BookService_ServiceDesc.ServiceName, pb.AuthorService_ServiceDesc.ServiceName, pb.AuthService_ServiceDesc.ServiceName} {
+		if !found[name] {
+			t.Errorf("reflection does not list %s", name)
+		}
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{repositories.ErrBookNotFound, codes.NotFound},
+		{apperror.Conflict(apperror.CodeEmailTaken, "taken"), codes.AlreadyExists},
+		{apperror.Validation(apperror.CodeValidation, "invalid", nil), codes.InvalidArgument},
+		{apperror.Unauthorized(apperror.CodeInvalidToken, "invalid"), codes.Unauthenticated},
+		{apperror.Forbidden(apperror.CodeForbidden, "forbidden"), codes.PermissionDenied},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{context.Canceled, codes.Canceled},
+		{errors.New("boom"), codes.Internal},
+	}
+	for _, tt := range tests {
+		if got := statusCode(tt.err); got != tt.want {
+			t.Errorf("statusCode(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package health reports whether the process is alive and whether it is
+// ready to serve traffic, from a set of component checks.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFailing  = "failing"
+
+	// DefaultTimeout bounds a readiness check of all components.
+	DefaultTimeout = 2 * time.Second
+)
+
+var errShuttingDown = errors.New("shutting down")
+
+// Check is a component readiness depends on. A failing Optional component
+// degrades the report without making the service unready.
+type Check struct {
+	Name     string
+	Optional bool
+	Func     func(ctx context.Context) error
+}
+
+type Component struct {
+	Status   string `json:"status"`
+	Optional bool   `json:"optional,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     string               `json:"status"`
+	Components map[string]Component `json:"components,omitempty"`
+}
+
+// Ready reports whether the service can take traffic.
+func (r Report) Ready() bool {
+	return r.Status != StatusFailing
+}
+
+type Health struct {
+	checks       []Check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func New(timeout time.Duration, checks ...Check) *Health {
+	return &Health{checks: checks, timeout: timeout}
+}
+
+// Add registers another component check.
+func (h *Health) Add(check Check) {
+	h.checks = append(h.checks, check)
+}
+
+// ShutDown makes readiness fail from now on, so that traffic is moved away
+// while in-flight requests drain.
+func (h *Health) ShutDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Live reports the process as alive; it checks no dependencies so that a
+// database outage does not get the process restarted.
+func (h *Health) Live() Report {
+	return Report{Status: StatusOK}
+}
+
+// Ready runs every check concurrently within the timeout.
+func (h *Health) Ready(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Components: make(map[string]Component, len(h.checks)+1)}
+	if h.shuttingDown.Load() {
+		report.Status = StatusFailing
+		report.Components["server"] = Component{Status: StatusFailing, Error: errShuttingDown.Error()}
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			component := Component{Status: StatusOK, Optional: check.Optional}
+			if err := check.Func(ctx); err != nil {
+				component.Status = StatusFailing
+				component.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[check.Name] = component
+			switch {
+			case component.Status == StatusOK:
+			case !check.Optional:
+				report.Status = StatusFailing
+			case report.Status == StatusOK:
+				report.Status = StatusDegraded
+			}
+		}(check)
+	}
+	wg.Wait()
+	return report
+}
+
+// Database pings the connection pool of db.
+func Database(db *gorm.DB) Check {
+	return Check{
+		Name: "database",
+		Func: func(ctx context.Context) error {
+			if db == nil {
+				return errors.New("not configured")
+			}
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func check(name string, optional bool, err error) Check {
+	return Check{Name: name, Optional: optional, Func: func(context.Context) error { return err }}
+}
+
+func TestReady(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		want   string
+	}{
+		{"no checks", nil, StatusOK},
+		{"all ok", []Check{check("database", false, nil), check("cache", true, nil)}, StatusOK},
+		{"optional failing", []Check{check("database", false, nil), check("cache", true, errors.New("down"))}, StatusDegraded},
+		{"required failing", []Check{check("database", false, errors.New("down")), check("cache", true, errors.New("down"))}, StatusFailing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := New(time.Second, tt.checks...).Ready(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("status %s, want %s: %+v", report.Status, tt.want, report)
+			}
+			if len(report.Components) != len(tt.checks) {
+				t.Errorf("components %+v", report.Components)
+			}
+			if report.Ready() != (tt.want != StatusFailing) {
+				t.Errorf("Ready() = %v", report.Ready())
+			}
+		})
+	}
+}
+
+func TestReadyFailsAfterShutDown(t *testing.T) {
+	h := New(time.Second, check("database", false, nil))
+	if !h.Ready(context.Background()).Ready() {
+		t.Fatal("not ready before shutdown")
+	}
+	h.ShutDown()
+	report := h.Ready(context.Background())
+	if report.Ready() || report.Components["server"].Status != StatusFailing {
+		t.Errorf("report after shutdown %+v", report)
+	}
+	if h.Live().Status != StatusOK {
+		t.Error("liveness should not depend on shutdown")
+	}
+}
+
+func TestReadyTimesOutChecks(t *testing.T) {
+	slow := Check{Name: "database", Func: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	start := time.Now()
+	report := New(50*time.Millisecond, slow).Ready(context.Background())
+	if report.Ready() || time.Since(start) > time.Second {
+		t.Errorf("report %+v after %s", report, time.Since(start))
+	}
+}
+
+func TestDatabaseNotConfigured(t *testing.T) {
+	if err := Database(nil).Func(context.Background()); err == nil {
+		t.Error("expected an error without a database")
+	}
+}
@@ -3,11 +3,13 @@ package helper
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
-	"github.com/labstack/echo"
+	"github.com/aldisaputra17/book-store/config"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -23,8 +25,50 @@ type stackTracer interface {
 	StackTrace() errors.StackTrace
 }
 
+type loggerKey struct{}
+
+// ConfigureLogger sets the level and format of the standard logger once at
+// startup. Entries are written unbuffered to stdout.
+func ConfigureLogger(cfg config.LogConfig, serviceName string) error {
+	level, err := log.ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(level)
+	log.SetOutput(os.Stdout)
+	if cfg.Format == "text" {
+		log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	} else {
+		log.SetFormatter(&log.JSONFormatter{})
+	}
+	service = serviceName
+	return nil
+}
+
+// WithLogger returns a copy of ctx carrying entry, whose fields are added
+// to everything logged through ctx.
+func WithLogger(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, entry)
+}
+
+// Logger returns the entry stored by WithLogger, or one of the standard
+// logger, with the trace and span IDs of ctx.
+func Logger(ctx context.Context) *log.Entry {
+	entry, ok := ctx.Value(loggerKey{}).(*log.Entry)
+	if !ok {
+		entry = log.NewEntry(log.StandardLogger())
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		entry = entry.WithFields(log.Fields{
+			"trace_id": sc.TraceID().String(),
+			"span_id":  sc.SpanID().String(),
+		})
+	}
+	return entry
+}
+
 func LogContext(ctx context.Context, c, s string) *log.Entry {
-	return log.WithFields(log.Fields{
+	return Logger(ctx).WithFields(log.Fields{
 		"topic":   Topic,
 		"context": c,
 		"scope":   s,
@@ -33,20 +77,7 @@ func LogContext(ctx context.Context, c, s string) *log.Entry {
 }
 
 func Log(ctx context.Context, level log.Level, err error, context, scope string) {
-	log.SetFormatter(&log.JSONFormatter{})
-	/*
-		syslogOutput, err := logrusSyslog.NewSyslogHook("", "", syslog.LOG_INFO, LogTag)
-		// avoiding nil pointer. check for error first then add hook.
-		if err != nil {
-			log.Debug("Unable to setup syslog output")
-		}
-		log.AddHook(syslogOutput)
-	*/
 	entry := LogContext(ctx, context, scope)
-	reqID := ctx.Value(echo.HeaderXRequestID)
-	if reqID != "" {
-		entry = entry.WithField("request_id", reqID)
-	}
 	message := err.Error()
 
 	var sb strings.Builder
@@ -0,0 +1,33 @@
+package helper
+
+import "net/http"
+
+const (
+	ContentTypeProblem = "application/problem+json"
+	problemTypePrefix  = "urn:book-store:problem:"
+)
+
+// Problem is an RFC 7807 problem details object, extended with the error
+// code and the list of errors (messages or FieldError values).
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code"`
+	Errors    interface{} `json:"errors,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+func BuildProblem(status int, code string, detail string, instance string, errs interface{}) Problem {
+	return Problem{
+		Type:     problemTypePrefix + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: instance,
+		Code:     code,
+		Errors:   errs,
+	}
+}
@@ -7,10 +7,12 @@ import (
 )
 
 type Response struct {
-	Success bool        `json:"status"`
-	Message string      `json:"message"`
-	Errors  interface{} `json:"errors"`
-	Data    interface{} `json:"data"`
+	Success   bool        `json:"status"`
+	Message   string      `json:"message"`
+	Code      string      `json:"code,omitempty"`
+	Errors    interface{} `json:"errors"`
+	Data      interface{} `json:"data"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 type ResponseWithPagination struct {
@@ -44,6 +46,20 @@ func BuildErrorResponse(message string, err string, data interface{}) Response {
 	return res
 }
 
+// BuildCodedErrorResponse builds an error response carrying the
+// machine-readable code of an apperror and its errors as given, e.g. a
+// list of FieldError.
+func BuildCodedErrorResponse(message string, code string, errs interface{}, data interface{}) Response {
+	res := Response{
+		Success: false,
+		Message: message,
+		Code:    code,
+		Errors:  errs,
+		Data:    data,
+	}
+	return res
+}
+
 func BuildReadWithPagination(success bool, message string, data interface{}, total entities.Pagination) ResponseWithPagination {
 	res := ResponseWithPagination{
 		Success: success,
@@ -0,0 +1,120 @@
+package helper
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// DefaultLocale is used when Accept-Language names no supported locale.
+const DefaultLocale = "en"
+
+// FieldError is a failed validation rule of a single request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var translator *ut.UniversalTranslator
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(fieldName)
+
+	translator = ut.New(en.New(), en.New(), id.New())
+	registrations := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": enTranslations.RegisterDefaultTranslations,
+		"id": idTranslations.RegisterDefaultTranslations,
+	}
+	for locale, register := range registrations {
+		trans, _ := translator.GetTranslator(locale)
+		if err := register(v, trans); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ValidateStruct applies the same `binding` rules gin uses when binding a
+// request, for values that were not bound directly (e.g. items of a batch).
+func ValidateStruct(obj interface{}) error {
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// FieldErrors lists the failed rules of a validator error with messages in
+// the first supported locale of an Accept-Language header. It returns nil
+// when err holds no validator errors.
+func FieldErrors(err error, acceptLanguage string) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+	trans := Translator(acceptLanguage)
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Message: fe.Translate(trans),
+		})
+	}
+	return fields
+}
+
+// Translator picks the translator for an Accept-Language header, falling
+// back to DefaultLocale.
+func Translator(acceptLanguage string) ut.Translator {
+	trans, _ := translator.FindTranslator(parseAcceptLanguage(acceptLanguage)...)
+	return trans
+}
+
+// parseAcceptLanguage returns the language tags of the header in order,
+// each followed by its base language (id-ID yields id_id, id). Tags with
+// q=0 are dropped.
+func parseAcceptLanguage(header string) []string {
+	var locales []string
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" || strings.ReplaceAll(strings.TrimSpace(params), " ", "") == "q=0" {
+			continue
+		}
+		tag = strings.ToLower(strings.ReplaceAll(tag, "-", "_"))
+		locales = append(locales, tag)
+		if base, _, found := strings.Cut(tag, "_"); found {
+			locales = append(locales, base)
+		}
+	}
+	return locales
+}
+
+// fieldName reports fields by their JSON name, then form name, so errors
+// match what clients send.
+func fieldName(fld reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(fld.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return fld.Name
+}
+
+// fieldPath strips the top-level struct name from a validator namespace,
+// e.g. BulkCreateBookRequest.items[0].name becomes items[0].name.
+func fieldPath(namespace string) string {
+	if _, path, found := strings.Cut(namespace, "."); found {
+		return path
+	}
+	return namespace
+}
@@ -0,0 +1,51 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+type signupRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+func TestFieldErrors(t *testing.T) {
+	err := ValidateStruct(&signupRequest{Password: "abc"})
+	tests := []struct {
+		acceptLanguage string
+		want           []FieldError
+	}{
+		{"", []FieldError{
+			{Field: "email", Rule: "required", Message: "email is a required field"},
+			{Field: "password", Rule: "min", Message: "password must be at least 6 characters in length"},
+		}},
+		{"id-ID,id;q=0.9,en;q=0.8", []FieldError{
+			{Field: "email", Rule: "required", Message: "email wajib diisi"},
+			{Field: "password", Rule: "min", Message: "panjang minimal password adalah 6 karakter"},
+		}},
+		{"fr-FR, de", []FieldError{
+			{Field: "email", Rule: "required", Message: "email is a required field"},
+			{Field: "password", Rule: "min", Message: "password must be at least 6 characters in length"},
+		}},
+	}
+	for _, tt := range tests {
+		if got := FieldErrors(err, tt.acceptLanguage); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FieldErrors(%q) = %+v, want %+v", tt.acceptLanguage, got, tt.want)
+		}
+	}
+}
+
+func TestFieldErrorsIgnoresOtherErrors(t *testing.T) {
+	if got := FieldErrors(ValidateStruct(&signupRequest{Email: "a@b.c", Password: "secret"}), ""); got != nil {
+		t.Errorf("got %+v for a valid struct", got)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := parseAcceptLanguage("id-ID, en;q=0.5, *;q=0.1, fr;q=0")
+	want := []string{"id_id", "id", "en"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
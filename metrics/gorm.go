@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startKey = "metrics:start"
+
+type gormPlugin struct {
+	metrics *Metrics
+}
+
+// GormPlugin times every query of the database it is used on and counts
+// the failed ones.
+func (m *Metrics) GormPlugin() gorm.Plugin {
+	return &gormPlugin{metrics: m}
+}
+
+func (p *gormPlugin) Name() string {
+	return "metrics"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	registrations := []error{
+		cb.Create().Before("gorm:create").Register("metrics:before_create", p.before),
+		cb.Create().After("gorm:create").Register("metrics:after_create", p.after("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", p.before),
+		cb.Query().After("gorm:query").Register("metrics:after_query", p.after("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", p.before),
+		cb.Update().After("gorm:update").Register("metrics:after_update", p.after("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", p.before),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", p.after("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", p.before),
+		cb.Row().After("gorm:row").Register("metrics:after_row", p.after("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", p.before),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", p.after("raw")),
+	}
+	return errors.Join(registrations...)
+}
+
+func (p *gormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func (p *gormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start, _ := v.(time.Time)
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		p.metrics.dbDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			p.metrics.dbErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Package metrics collects Prometheus metrics of HTTP requests, database
+// queries and business events, and serves them for scraping.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "book_store"
+
+// unmatchedRoute labels requests that matched no route, so that arbitrary
+// paths do not create new series.
+const unmatchedRoute = "unmatched"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	dbDuration   *prometheus.HistogramVec
+	dbErrors     *prometheus.CounterVec
+
+	booksCreated  *prometheus.CounterVec
+	logins        *prometheus.CounterVec
+	registrations prometheus.Counter
+}
+
+// New creates the metrics on their own registry, with the Go runtime and
+// process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route template and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "GORM query latency by operation and table.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+		dbErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_query_errors_total",
+			Help:      "GORM queries that failed, not counting record not found.",
+		}, []string{"operation", "table"}),
+		booksCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "books_created_total",
+			Help:      "Books created, by single create, bulk create or import.",
+		}, []string{"via"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Login attempts by result.",
+		}, []string{"result"}),
+		registrations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "registrations_total",
+			Help:      "Users registered.",
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.dbDuration, m.dbErrors,
+		m.booksCreated, m.logins, m.registrations,
+	)
+	// Show the business counters from the start rather than on first use.
+	for _, via := range []string{viaCreate, viaBulk, viaImport} {
+		m.booksCreated.WithLabelValues(via)
+	}
+	for _, result := range []string{loginSucceeded, loginFailed} {
+		m.logins.WithLabelValues(result)
+	}
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware records every request by its gin route template, e.g.
+// /api/v1/book/:id, rather than by its path.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterDBStats exports the connection pool statistics of db.
+func (m *Metrics) RegisterDBStats(db *sql.DB, name string) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("scrape: status %d", w.Code)
+	}
+	return w.Body.String()
+}
+
+func assertContains(t *testing.T, body string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(body, line) {
+			t.Errorf("metrics missing %q", line)
+		}
+	}
+}
+
+func TestMiddlewareLabelsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/api/v1/book/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	for _, path := range []string{"/api/v1/book/1", "/api/v1/book/2", "/nowhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assertContains(t, scrape(t, m),
+		`book_store_http_requests_total{method="GET",route="/api/v1/book/:id",status="404"} 2`,
+		`book_store_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`book_store_http_request_duration_seconds_count{method="GET",route="/api/v1/book/:id",status="404"} 2`,
+	)
+}
+
+type stubBookService struct {
+	services.BookService
+}
+
+func (stubBookService) Create(context.Context, *dto.CreateBookRequest) (*dto.CreateBookResponse, error) {
+	return &dto.CreateBookResponse{}, nil
+}
+
+func (stubBookService) BulkCreate(context.Context, *dto.BulkCreateBookRequest) (*dto.BulkResponse, error) {
+	return &dto.BulkResponse{Succeeded: 3, Failed: 1}, nil
+}
+
+type stubAuthService struct {
+	services.AuthService
+}
+
+func (stubAuthService) VerifyCredential(email string, _ string) interface{} {
+	if email == "ok@example.com" {
+		return entities.User{Email: email}
+	}
+	return false
+}
+
+func (stubAuthService) Register(_ context.Context, req *dto.AuthRequest) (*entities.User, error) {
+	return &entities.User{Email: req.Email}, nil
+}
+
+func TestBusinessCounters(t *testing.T) {
+	m := New()
+	books := m.BookService(stubBookService{})
+	auth := m.AuthService(stubAuthService{})
+	ctx := context.Background()
+
+	_, _ = books.Create(ctx, &dto.CreateBookRequest{})
+	_, _ = books.BulkCreate(ctx, &dto.BulkCreateBookRequest{})
+	auth.VerifyCredential("ok@example.com", "secret")
+	auth.VerifyCredential("bad@example.com", "secret")
+	auth.VerifyCredential("bad@example.com", "secret")
+	_, _ = auth.Register(ctx, &dto.AuthRequest{Email: "new@example.com"})
+
+	assertContains(t, scrape(t, m),
+		`book_store_books_created_total{via="create"} 1`,
+		`book_store_books_created_total{via="bulk"} 3`,
+		`book_store_books_created_total{via="import"} 0`,
+		`book_store_logins_total{result="succeeded"} 1`,
+		`book_store_logins_total{result="failed"} 2`,
+		`book_store_registrations_total 1`,
+	)
+}
+
+func TestGormPluginTimesQueries(t *testing.T) {
+	// DryRun builds the SQL without a database; the callbacks still run.
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New()
+	if err := db.Use(m.GormPlugin()); err != nil {
+		t.Fatal(err)
+	}
+	var books []entities.Book
+	db.Find(&books)
+	db.Where("1 = 1").Delete(&entities.Book{})
+
+	assertContains(t, scrape(t, m),
+		`book_store_db_query_duration_seconds_count{operation="query",table="books"} 1`,
+		`book_store_db_query_duration_seconds_count{operation="delete",table="books"} 1`,
+	)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterDBStats(sqlDB, "book-store"); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, scrape(t, m), `go_sql_open_connections{db_name="book-store"} 0`)
+}
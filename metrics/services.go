@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/formats"
+	"github.com/aldisaputra17/book-store/services"
+)
+
+const (
+	viaCreate = "create"
+	viaBulk   = "bulk"
+	viaImport = "import"
+
+	loginSucceeded = "succeeded"
+	loginFailed    = "failed"
+)
+
+type bookService struct {
+	services.BookService
+	metrics *Metrics
+}
+
+// BookService counts the books created through next.
+func (m *Metrics) BookService(next services.BookService) services.BookService {
+	return &bookService{BookService: next, metrics: m}
+}
+
+func (s *bookService) Create(ctx context.Context, bookReq *dto.CreateBookRequest) (*dto.CreateBookResponse, error) {
+	res, err := s.BookService.Create(ctx, bookReq)
+	if err == nil {
+		s.metrics.booksCreated.WithLabelValues(viaCreate).Inc()
+	}
+	return res, err
+}
+
+func (s *bookService) BulkCreate(ctx context.Context, bulkReq *dto.BulkCreateBookRequest) (*dto.BulkResponse, error) {
+	res, err := s.BookService.BulkCreate(ctx, bulkReq)
+	if err == nil {
+		s.metrics.booksCreated.WithLabelValues(viaBulk).Add(float64(res.Succeeded))
+	}
+	return res, err
+}
+
+func (s *bookService) Import(ctx context.Context, records []formats.Record, dryRun bool) (*dto.ImportResponse, error) {
+	res, err := s.BookService.Import(ctx, records, dryRun)
+	if err == nil && !dryRun {
+		s.metrics.booksCreated.WithLabelValues(viaImport).Add(float64(res.Imported))
+	}
+	return res, err
+}
+
+type authService struct {
+	services.AuthService
+	metrics *Metrics
+}
+
+// AuthService counts the logins and registrations through next.
+func (m *Metrics) AuthService(next services.AuthService) services.AuthService {
+	return &authService{AuthService: next, metrics: m}
+}
+
+func (s *authService) VerifyCredential(email string, password string) interface{} {
+	res := s.AuthService.VerifyCredential(email, password)
+	result := loginFailed
+	if _, ok := res.(entities.User); ok {
+		result = loginSucceeded
+	}
+	s.metrics.logins.WithLabelValues(result).Inc()
+	return res
+}
+
+func (s *authService) Register(ctx context.Context, registerReq *dto.AuthRequest) (*entities.User, error) {
+	user, err := s.AuthService.Register(ctx, registerReq)
+	if err == nil {
+		s.metrics.registrations.Inc()
+	}
+	return user, err
+}
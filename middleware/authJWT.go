@@ -1,12 +1,9 @@
 package middleware
 
 import (
-	"log"
-	"net/http"
-
+	"github.com/aldisaputra17/book-store/apperror"
 	"github.com/aldisaputra17/book-store/helper"
 	"github.com/aldisaputra17/book-store/services"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,20 +11,61 @@ func AuthorizeJWT(jwtService services.JWTService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			response := helper.BuildErrorResponse("Failed To Process Response", "Not Token Found!", nil)
-			c.AbortWithStatusJSON(http.StatusBadRequest, response)
+			AbortWithError(c, "Failed To Process Response", apperror.Unauthorized(apperror.CodeMissingToken, "Not Token Found!"))
 			return
 		}
 		token, err := jwtService.ValidateToken(authHeader)
-		if token.Valid {
-			claims := token.Claims.(jwt.MapClaims)
-			log.Println("Claim[user_id]: ", claims["user_id"])
-			log.Println("Claim[issuer] :", claims["issuer"])
-		} else {
-			log.Println(err)
-			response := helper.BuildErrorResponse("Token not Valid!", err.Error(), nil)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+		if err != nil || !token.Valid {
+			helper.Logger(c.Request.Context()).WithError(err).Debug("invalid token")
+			AbortWithError(c, "Token not Valid!", apperror.Unauthorized(apperror.CodeInvalidToken, "Token is not valid"))
+			return
 		}
+		c.Set(adminKey, services.TokenAdmin(token))
+		SetUserID(c, services.TokenUserID(token))
+	}
+}
+
+const (
+	authenticatedKey = "jwt_authenticated"
+	adminKey         = "jwt_admin"
+)
 
+// OptionalJWT validates the token when the request carries one, rejecting
+// invalid tokens, but lets anonymous requests through. Handlers check the
+// outcome with IsAuthenticated.
+func OptionalJWT(jwtService services.JWTService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.Next()
+			return
+		}
+		token, err := jwtService.ValidateToken(authHeader)
+		if err != nil || !token.Valid {
+			AbortWithError(c, "Token not Valid!", apperror.Unauthorized(apperror.CodeInvalidToken, "Token is not valid"))
+			return
+		}
+		c.Set(authenticatedKey, true)
+		c.Set(adminKey, services.TokenAdmin(token))
+		SetUserID(c, services.TokenUserID(token))
+	}
+}
+
+func IsAuthenticated(c *gin.Context) bool {
+	return c.GetBool(authenticatedKey)
+}
+
+// IsAdmin tells whether the request carries the token of an administrator.
+func IsAdmin(c *gin.Context) bool {
+	return c.GetBool(adminKey)
+}
+
+// RequireAdmin rejects requests without an administrator token. It runs
+// after AuthorizeJWT.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsAdmin(c) {
+			AbortWithError(c, "Forbidden", apperror.Forbidden(apperror.CodeForbidden, "administrator token required"))
+		}
 	}
 }
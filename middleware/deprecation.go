@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
+
+// Deprecation announces that the routes it guards are deprecated since
+// the given time (RFC 9745) and, when sunset is set, removed after it
+// (RFC 8594). When successor is set, a successor-version link points at the
+// same path with prefix replaced by successor.
+func Deprecation(since, sunset time.Time, prefix, successor string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(since.Unix(), 10)
+	return func(c *gin.Context) {
+		c.Header(HeaderDeprecation, deprecation)
+		if !sunset.IsZero() {
+			c.Header(HeaderSunset, sunset.UTC().Format(http.TimeFormat))
+		}
+		if successor != "" {
+			path := successor + strings.TrimPrefix(c.Request.URL.Path, prefix)
+			c.Header(HeaderLink, "<"+path+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+var kindStatus = map[apperror.Kind]int{
+	apperror.KindInternal:     http.StatusInternalServerError,
+	apperror.KindBadRequest:   http.StatusBadRequest,
+	apperror.KindValidation:   http.StatusUnprocessableEntity,
+	apperror.KindNotFound:     http.StatusNotFound,
+	apperror.KindConflict:     http.StatusConflict,
+	apperror.KindForbidden:    http.StatusForbidden,
+	apperror.KindUnauthorized: http.StatusUnauthorized,
+	apperror.KindTooLarge:     http.StatusRequestEntityTooLarge,
+}
+
+// HTTPStatus is the status code responses use for err.
+func HTTPStatus(err error) int {
+	if status, ok := kindStatus[apperror.KindOf(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrorHandler writes the last error a handler attached with c.Error, unless
+// a response has already been written. The string Meta of the error, if
+// any, is used as the response message. It must run inside Idempotency so
+// that the written error is what gets stored for replays.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		last := c.Errors.Last()
+		message, _ := last.Meta.(string)
+		AbortWithError(c, message, last.Err)
+	}
+}
+
+// AbortWithError writes err with the status and code of its apperror kind,
+// as problem+json when the client accepts it and as helper.Response
+// otherwise. Validator errors are listed per field, translated according to
+// Accept-Language. Only the message of an error is sent: its cause, often
+// a driver error naming tables and constraints, is logged instead. Internal
+// errors also carry the request ID to find the log entry.
+func AbortWithError(c *gin.Context, message string, err error) {
+	appErr := apperror.From(err)
+	status := HTTPStatus(appErr)
+	requestID := c.GetString(requestIDKey)
+	if appErr.Kind == apperror.KindInternal {
+		requestID = RequestID(c)
+		helper.Log(c, log.ErrorLevel, err, "errorHandler", c.FullPath())
+	} else if appErr.Err != nil {
+		helper.Log(c, log.InfoLevel, err, "errorHandler", c.FullPath())
+	}
+	if message == "" {
+		message = appErr.Message
+	}
+	var errs interface{} = []string{appErr.Message}
+	if fields := helper.FieldErrors(err, c.GetHeader("Accept-Language")); fields != nil {
+		errs = fields
+	}
+
+	if strings.Contains(c.GetHeader("Accept"), helper.ContentTypeProblem) {
+		problem := helper.BuildProblem(status, appErr.Code, message, c.Request.URL.Path, errs)
+		problem.RequestID = requestID
+		c.Header("Content-Type", helper.ContentTypeProblem)
+		c.AbortWithStatusJSON(status, problem)
+		return
+	}
+	res := helper.BuildCodedErrorResponse(message, appErr.Code, errs, helper.EmptyObj{})
+	res.RequestID = requestID
+	c.AbortWithStatusJSON(status, res)
+}
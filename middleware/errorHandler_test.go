@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+func newErrorRouter(err error) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/", func(c *gin.Context) {
+		_ = c.Error(err).SetMeta("Failed fetch book")
+		c.Abort()
+	})
+	return r
+}
+
+func TestErrorHandlerStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+		code string
+	}{
+		{apperror.NotFound(apperror.CodeBookNotFound, "book not found"), http.StatusNotFound, apperror.CodeBookNotFound},
+		{apperror.Conflict(apperror.CodeEmailTaken, "taken"), http.StatusConflict, apperror.CodeEmailTaken},
+		{apperror.Validation(apperror.CodeValidation, "invalid", nil), http.StatusUnprocessableEntity, apperror.CodeValidation},
+		{apperror.BadRequest(apperror.CodeInvalidID, "invalid id", nil), http.StatusBadRequest, apperror.CodeInvalidID},
+		{apperror.Forbidden(apperror.CodeForbidden, "no"), http.StatusForbidden, apperror.CodeForbidden},
+		{apperror.Unauthorized(apperror.CodeInvalidToken, "no"), http.StatusUnauthorized, apperror.CodeInvalidToken},
+		{errors.New("pq: connection refused"), http.StatusInternalServerError, apperror.CodeInternal},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		newErrorRouter(tt.err).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != tt.want {
+			t.Errorf("%v: status %d, want %d", tt.err, w.Code, tt.want)
+		}
+		var res helper.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Code != tt.code || res.Message != "Failed fetch book" || res.Success {
+			t.Errorf("%v: unexpected body %s", tt.err, w.Body)
+		}
+	}
+}
+
+func TestErrorHandlerHidesInternalErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	newErrorRouter(errors.New("pq: connection refused")).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	var res struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Errors) != 1 || res.Errors[0] != "internal error" {
+		t.Errorf("internal cause leaked: %s", w.Body)
+	}
+}
+
+func TestErrorHandlerProblemJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", helper.ContentTypeProblem)
+	newErrorRouter(apperror.NotFound(apperror.CodeBookNotFound, "book not found")).ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Type"); got != helper.ContentTypeProblem {
+		t.Fatalf("Content-Type %q", got)
+	}
+	var problem helper.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != http.StatusNotFound || problem.Code != apperror.CodeBookNotFound || problem.Instance != "/" || problem.Type == "" {
+		t.Errorf("unexpected problem %+v", problem)
+	}
+}
+
+func TestErrorHandlerKeepsWrittenResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/", func(c *gin.Context) {
+		_ = c.Error(errors.New("logged only"))
+		c.String(http.StatusOK, "ok")
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("response rewritten: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestErrorHandlerListsFieldErrors(t *testing.T) {
+	err := helper.ValidateStruct(&dto.AuthRequest{Email: "a@b.c", Password: "abc"})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "id")
+	newErrorRouter(apperror.Binding(err)).ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status %d", w.Code)
+	}
+	var res struct {
+		Code   string              `json:"code"`
+		Errors []helper.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	want := helper.FieldError{Field: "password", Rule: "min", Message: "panjang minimal password adalah 6 karakter"}
+	if res.Code != apperror.CodeValidation || len(res.Errors) != 1 || res.Errors[0] != want {
+		t.Errorf("unexpected body %s", w.Body)
+	}
+}
@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotentResponse is the response stored for a completed request.
+type IdempotentResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyRecord is what a store keeps per key. Response is nil while the
+// first request carrying the key is still being processed.
+type IdempotencyRecord struct {
+	RequestHash string
+	Response    *IdempotentResponse
+	ExpiresAt   time.Time
+}
+
+// IdempotencyStore persists idempotency keys. Reserve must be atomic: it
+// either records a new in-flight entry for key and returns reserved true, or
+// returns the existing record untouched.
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, key string, requestHash string, ttl time.Duration) (record *IdempotencyRecord, reserved bool, err error)
+	Complete(ctx context.Context, key string, response *IdempotentResponse, ttl time.Duration) error
+	Release(ctx context.Context, key string) error
+}
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*idempotencyEntry
+	expiry  expiryHeap
+	now     func() time.Time
+}
+
+// idempotencyEntry is a record in the memory store, kept in both the map
+// and the expiry heap.
+type idempotencyEntry struct {
+	key    string
+	record IdempotencyRecord
+	index  int
+}
+
+// expiryHeap orders entries by expiry, soonest first, so that Reserve only
+// visits the expired ones.
+type expiryHeap []*idempotencyEntry
+
+func (h expiryHeap) Len() int { return len(h) }
+
+func (h expiryHeap) Less(i, j int) bool {
+	return h[i].record.ExpiresAt.Before(h[j].record.ExpiresAt)
+}
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	entry := x.(*idempotencyEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
+
+// NewMemoryIdempotencyStore keeps keys in process memory, suitable for a
+// single instance; replicas behind a load balancer need a shared store.
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{
+		records: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *memoryIdempotencyStore) Reserve(ctx context.Context, key string, requestHash string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evictExpired(now)
+	if entry, ok := s.records[key]; ok {
+		copied := entry.record
+		return &copied, false, nil
+	}
+	entry := &idempotencyEntry{
+		key: key,
+		record: IdempotencyRecord{
+			RequestHash: requestHash,
+			ExpiresAt:   now.Add(ttl),
+		},
+	}
+	s.records[key] = entry
+	heap.Push(&s.expiry, entry)
+	return nil, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, key string, response *IdempotentResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.records[key]; ok {
+		entry.record.Response = response
+		entry.record.ExpiresAt = s.now().Add(ttl)
+		heap.Fix(&s.expiry, entry.index)
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.records[key]; ok {
+		heap.Remove(&s.expiry, entry.index)
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// evictExpired drops the entries that expired by now, soonest first.
+func (s *memoryIdempotencyStore) evictExpired(now time.Time) {
+	for len(s.expiry) > 0 && now.After(s.expiry[0].record.ExpiresAt) {
+		entry := heap.Pop(&s.expiry).(*idempotencyEntry)
+		delete(s.records, entry.key)
+	}
+}
+
+// bodyRecorder tees everything the handler writes so it can be stored.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry. The first response for a key is stored for ttl and replayed to
+// later requests with the same key and body; reusing the key with another
+// body is rejected with 422, and a retry arriving while the first request
+// is still running gets 409. Keys are scoped to the route and the caller's
+// Authorization header. Responses with a 5xx status are not stored, so the
+// request can be retried for real. Bodies are read into memory to be
+// compared, those over maxBody bytes are rejected with 413.
+func Idempotency(store IdempotencyStore, ttl time.Duration, maxBody int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			AbortWithError(c, "Failed to process request", apperror.BadRequest(apperror.CodeBadRequest, "Idempotency-Key is too long", nil))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBody))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			AbortWithError(c, "Failed to process request", apperror.Wrap(apperror.KindTooLarge, apperror.CodeRequestTooLarge, "request body is too large", err))
+			return
+		}
+		if err != nil {
+			AbortWithError(c, "Failed to process request", apperror.BadRequest(apperror.CodeBadRequest, "cannot read request body", err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := hashParts(key, c.Request.Method, c.FullPath(), c.GetHeader("Authorization"))
+		requestHash := hashParts(c.Request.Method, c.Request.URL.RequestURI(), c.ContentType(), string(body))
+
+		record, reserved, err := store.Reserve(c, storeKey, requestHash, ttl)
+		if err != nil {
+			AbortWithError(c, "Failed to process request", err)
+			return
+		}
+		if !reserved {
+			switch {
+			case record.RequestHash != requestHash:
+				AbortWithError(c, "Idempotency-Key already used", apperror.Validation(apperror.CodeIdempotencyReused, "The key was used with a different request", nil))
+			case record.Response == nil:
+				AbortWithError(c, "Idempotency-Key in use", apperror.Conflict(apperror.CodeIdempotencyPending, "A request with this key is still being processed"))
+			default:
+				c.Header(HeaderIdempotencyReplayed, "true")
+				c.Data(record.Response.Status, record.Response.ContentType, record.Response.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			if !completed {
+				store.Release(context.Background(), storeKey)
+			}
+		}()
+
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+		err = store.Complete(context.Background(), storeKey, &IdempotentResponse{
+			Status:      c.Writer.Status(),
+			ContentType: c.Writer.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}, ttl)
+		completed = err == nil
+	}
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
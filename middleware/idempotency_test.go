@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// idempotentRouter serves POST /book and /author, answering with status
+// and counting the requests that reach the handler. With block set, the
+// handler signals entered and waits for block to be closed.
+type idempotentRouter struct {
+	*gin.Engine
+	calls   int
+	status  int
+	entered chan struct{}
+	block   chan struct{}
+}
+
+func newIdempotentRouter() *idempotentRouter {
+	gin.SetMode(gin.TestMode)
+	r := &idempotentRouter{Engine: gin.New(), status: http.StatusCreated}
+	r.Use(Idempotency(NewMemoryIdempotencyStore(), time.Minute, 64))
+	handler := func(c *gin.Context) {
+		r.calls++
+		if r.block != nil {
+			r.entered <- struct{}{}
+			<-r.block
+		}
+		c.JSON(r.status, gin.H{"call": r.calls})
+	}
+	r.POST("/book", handler)
+	r.POST("/author", handler)
+	r.GET("/book", handler)
+	return r
+}
+
+func (r *idempotentRouter) send(method, path, key, auth, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderIdempotencyKey, key)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var res helper.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%v: %s", err, w.Body)
+	}
+	return res.Code
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	r := newIdempotentRouter()
+	first := r.send(http.MethodPost, "/book", "k1", "token", `{"title":"Mort"}`)
+	again := r.send(http.MethodPost, "/book", "k1", "token", `{"title":"Mort"}`)
+
+	if r.calls != 1 {
+		t.Fatalf("handler ran %d times", r.calls)
+	}
+	if again.Code != first.Code || again.Body.String() != first.Body.String() {
+		t.Errorf("replayed %d %s, want %d %s", again.Code, again.Body, first.Code, first.Body)
+	}
+	if first.Header().Get(HeaderIdempotencyReplayed) != "" || again.Header().Get(HeaderIdempotencyReplayed) != "true" {
+		t.Errorf("%s header: first %q, replay %q", HeaderIdempotencyReplayed,
+			first.Header().Get(HeaderIdempotencyReplayed), again.Header().Get(HeaderIdempotencyReplayed))
+	}
+}
+
+func TestIdempotencyRejectsReusedKey(t *testing.T) {
+	r := newIdempotentRouter()
+	r.send(http.MethodPost, "/book", "k1", "token", `{"title":"Mort"}`)
+	w := r.send(http.MethodPost, "/book", "k1", "token", `{"title":"Sourcery"}`)
+
+	if w.Code != http.StatusUnprocessableEntity || errorCode(t, w) != apperror.CodeIdempotencyReused {
+		t.Errorf("different body: %d %s", w.Code, w.Body)
+	}
+	if r.calls != 1 {
+		t.Errorf("handler ran %d times", r.calls)
+	}
+}
+
+func TestIdempotencyRejectsKeyInFlight(t *testing.T) {
+	r := newIdempotentRouter()
+	r.entered, r.block = make(chan struct{}), make(chan struct{})
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- r.send(http.MethodPost, "/book", "k1", "token", `{"title":"Mort"}`)
+	}()
+	<-r.entered
+
+	w := r.send(http.MethodPost, "/book", "k1", "token", `{"title":"Mort"}`)
+	close(r.block)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first request: %d %s", first.Code, first.Body)
+	}
+	if w.Code != http.StatusConflict || errorCode(t, w) != apperror.CodeIdempotencyPending {
+		t.Errorf("request in flight: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	r := newIdempotentRouter()
+	r.status = http.StatusServiceUnavailable
+	r.send(http.MethodPost, "/book", "k1", "token", `{"title":"Mort"}`)
+	r.status = http.StatusCreated
+	w := r.send(http.MethodPost, "/book", "k1", "token", `{"title":"Mort"}`)
+
+	if r.calls != 2 || w.Code != http.StatusCreated || w.Header().Get(HeaderIdempotencyReplayed) != "" {
+		t.Errorf("retry after a 5xx: %d calls, %d %s", r.calls, w.Code, w.Body)
+	}
+}
+
+func TestIdempotencyKeyScope(t *testing.T) {
+	r := newIdempotentRouter()
+	body := `{"name":"Mort"}`
+	r.send(http.MethodPost, "/book", "k1", "alice", body)
+
+	for _, c := range []struct{ name, method, path, auth string }{
+		{"another route", http.MethodPost, "/author", "alice"},
+		{"another caller", http.MethodPost, "/book", "bob"},
+		{"no caller", http.MethodPost, "/book", ""},
+		{"another method", http.MethodGet, "/book", "alice"},
+	} {
+		calls := r.calls
+		w := r.send(c.method, c.path, "k1", c.auth, body)
+		if r.calls != calls+1 || w.Header().Get(HeaderIdempotencyReplayed) != "" {
+			t.Errorf("%s: got %d %s, want the handler to run", c.name, w.Code, w.Body)
+		}
+	}
+}
+
+func TestIdempotencyLimitsBody(t *testing.T) {
+	r := newIdempotentRouter()
+	w := r.send(http.MethodPost, "/book", "k1", "token", `{"title":"`+strings.Repeat("a", 64)+`"}`)
+	if w.Code != http.StatusRequestEntityTooLarge || errorCode(t, w) != apperror.CodeRequestTooLarge {
+		t.Errorf("large body: %d %s", w.Code, w.Body)
+	}
+	if r.calls != 0 {
+		t.Errorf("handler ran %d times", r.calls)
+	}
+	if w := r.send(http.MethodPost, "/book", "k1", "token", `{"title":"Mort"}`); w.Code != http.StatusCreated {
+		t.Errorf("key of a rejected request: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestMemoryIdempotencyStoreEvictsExpired(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore().(*memoryIdempotencyStore)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	store.Reserve(ctx, "short", "h", time.Minute)
+	store.Reserve(ctx, "long", "h", time.Hour)
+	store.Reserve(ctx, "completed", "h", time.Minute)
+	store.Complete(ctx, "completed", &IdempotentResponse{Status: http.StatusCreated}, time.Hour)
+	store.Reserve(ctx, "released", "h", time.Minute)
+	store.Release(ctx, "released")
+
+	now = now.Add(2 * time.Minute)
+	if _, reserved, _ := store.Reserve(ctx, "short", "h", time.Minute); !reserved {
+		t.Error("expired key still reserved")
+	}
+	for _, key := range []string{"long", "completed"} {
+		if record, reserved, _ := store.Reserve(ctx, key, "h", time.Minute); reserved || record == nil {
+			t.Errorf("%s evicted before it expired", key)
+		}
+	}
+	if len(store.records) != 3 || len(store.expiry) != 3 {
+		t.Errorf("%d records, %d in the expiry order, want 3", len(store.records), len(store.expiry))
+	}
+}
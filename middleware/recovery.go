@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// Recovery turns a panic in a later handler into a 500 error response
+// carrying the request ID, and logs the panic with its stack trace. It
+// replaces gin.Recovery, which answers with an empty body.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			helper.Logger(c.Request.Context()).WithFields(log.Fields{
+				"request_id": RequestID(c),
+				"method":     c.Request.Method,
+				"route":      c.FullPath(),
+				"stack":      string(debug.Stack()),
+			}).Errorf("panic: %v", rec)
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			AbortWithError(c, "Internal server error", apperror.Wrap(apperror.KindInternal, apperror.CodeInternal, "internal error", fmt.Errorf("panic: %v", rec)))
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoveryReturnsInternalError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Recovery())
+	r.GET("/", func(c *gin.Context) {
+		var m map[string]int
+		m["boom"]++
+	})
+
+	for _, incoming := range []string{"", "req-123"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if incoming != "" {
+			req.Header.Set(HeaderRequestID, incoming)
+		}
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("status %d, want 500", w.Code)
+		}
+		var res helper.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Code != apperror.CodeInternal || res.RequestID == "" {
+			t.Errorf("unexpected body %s", w.Body)
+		}
+		if incoming != "" && res.RequestID != incoming {
+			t.Errorf("request id %q, want %q", res.RequestID, incoming)
+		}
+		if got := w.Header().Get(HeaderRequestID); got != res.RequestID {
+			t.Errorf("header request id %q, body %q", got, res.RequestID)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// captureLogs sends the standard logger to a buffer as JSON for the test
+// and returns a function decoding the entries written so far.
+func captureLogs(t *testing.T) func() []map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := log.StandardLogger()
+	out, formatter := logger.Out, logger.Formatter
+	log.SetOutput(&buf)
+	log.SetFormatter(&log.JSONFormatter{})
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFormatter(formatter)
+	})
+	return func() []map[string]interface{} {
+		var entries []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("log line %q: %v", line, err)
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+}
+
+func newLoggedRouter(jwtService services.JWTService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(RequestContext(), AccessLog())
+	r.GET("/books/:id", AuthorizeJWT(jwtService), func(c *gin.Context) {
+		helper.Logger(c).Info("handled")
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func TestRequestContextLogsRequestAndUser(t *testing.T) {
+	entries := captureLogs(t)
+	jwtService := services.NewJWTService(config.Default().JWT)
+	userID := uuid.New()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/books/42", nil)
+	req.Header.Set(HeaderRequestID, "req-123")
+	req.Header.Set("Authorization", jwtService.GenerateToken(userID, false))
+	newLoggedRouter(jwtService).ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get(HeaderRequestID); got != "req-123" {
+		t.Errorf("response %s = %q", HeaderRequestID, got)
+	}
+	logged := entries()
+	if len(logged) != 2 {
+		t.Fatalf("got %d log entries, want handler and access entries", len(logged))
+	}
+	for _, entry := range logged {
+		if entry["request_id"] != "req-123" || entry["user_id"] != userID.String() || entry["route"] != "/books/:id" {
+			t.Errorf("entry lacks request fields: %v", entry)
+		}
+	}
+	access := logged[1]
+	if access["msg"] != "request completed" || access["status"] != float64(http.StatusNoContent) || access["path"] != "/books/42" {
+		t.Errorf("unexpected access entry: %v", access)
+	}
+}
+
+func TestRequestContextGeneratesRequestID(t *testing.T) {
+	entries := captureLogs(t)
+	w := httptest.NewRecorder()
+	newLoggedRouter(services.NewJWTService(config.Default().JWT)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/books/42", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status %d, want 401", w.Code)
+	}
+	id := w.Header().Get(HeaderRequestID)
+	if _, err := uuid.Parse(id); err != nil {
+		t.Fatalf("generated request ID %q: %v", id, err)
+	}
+	logged := entries()
+	access := logged[len(logged)-1]
+	if access["request_id"] != id || access["level"] != "warning" || access["user_id"] != nil {
+		t.Errorf("unexpected access entry: %v", access)
+	}
+}
@@ -0,0 +1,257 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// SecuritySchemeJWT names the scheme of routes guarded by
+// middleware.AuthorizeJWT, which expects the raw token in Authorization.
+const SecuritySchemeJWT = "jwt"
+
+// Route documents one gin route. Path uses gin syntax, e.g. /api/book/:id.
+type Route struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	// Auth marks routes behind middleware.AuthorizeJWT, Admin those also
+	// behind middleware.RequireAdmin.
+	Auth  bool
+	Admin bool
+	Query []*Parameter
+	// Body is the JSON request body, Form the fields of a multipart body.
+	Body interface{}
+	Form []FormField
+	// Status is the success status, 200 when zero.
+	Status int
+	// Data is the type of the `data` member of the response envelope.
+	// Paginated switches to helper.ResponseWithPagination.
+	Data      interface{}
+	Paginated bool
+	// Produces replaces the JSON envelope with raw content of these types.
+	Produces []string
+	// Errors lists error statuses besides the ones every route documents:
+	// 400 and 500, 401 for Auth, 403 for Admin, 404 for paths with
+	// parameters and 422 for routes with a Body.
+	Errors []int
+	// Deprecated marks routes of a deprecated API version or alias.
+	Deprecated bool
+}
+
+type FormField struct {
+	Name        string
+	Description string
+	Required    bool
+	File        bool
+}
+
+// QueryParam is a shorthand for an optional query string parameter.
+func QueryParam(name, typ, description string) *Parameter {
+	return &Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: typ}}
+}
+
+// Build documents every route of registered that has an entry in docs.
+// Registered routes without documentation are left out, Missing reports
+// them.
+func Build(info Info, tags []Tag, registered gin.RoutesInfo, docs []Route) *Document {
+	byKey := make(map[string]Route, len(docs))
+	for _, doc := range docs {
+		byKey[doc.Method+" "+doc.Path] = doc
+	}
+
+	s := newSchemas()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Tags:    tags,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				SecuritySchemeJWT: {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        "Authorization",
+					Description: "Token returned by /api/v1/user/login, sent as is. Writes need the token of an administrator.",
+				},
+			},
+		},
+	}
+	for _, route := range registered {
+		routeDoc, ok := byKey[route.Method+" "+route.Path]
+		if !ok {
+			continue
+		}
+		path, params := pathTemplate(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		item.setOperation(route.Method, s.operation(routeDoc, params))
+	}
+	doc.Components.Schemas = s.components
+	return doc
+}
+
+// Missing lists the registered routes that doc does not describe.
+func Missing(doc *Document, registered gin.RoutesInfo) []string {
+	var missing []string
+	for _, route := range registered {
+		path, _ := pathTemplate(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok || item.Operation(route.Method) == nil {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// pathTemplate converts gin parameters (:id, *path) to OpenAPI ({id}) and
+// returns the matching path parameters.
+func pathTemplate(path string) (string, []*Parameter) {
+	segments := strings.Split(path, "/")
+	var params []*Parameter
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name := segment[1:]
+		param := &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		if name == "id" {
+			param.Schema.Format = "uuid"
+		}
+		params = append(params, param)
+		segments[i] = "{" + name + "}"
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func (s *schemas) operation(route Route, params []*Parameter) *Operation {
+	op := &Operation{
+		Summary:     route.Summary,
+		OperationID: operationID(route.Method, route.Path),
+		Parameters:  append(params, route.Query...),
+		Responses:   make(map[string]*Response),
+		Deprecated:  route.Deprecated,
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	if route.Auth {
+		op.Security = []map[string][]string{{SecuritySchemeJWT: {}}}
+	}
+
+	switch {
+	case route.Body != nil:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: s.of(route.Body)}},
+		}
+	case len(route.Form) > 0:
+		form := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for _, field := range route.Form {
+			prop := &Schema{Type: "string", Description: field.Description}
+			if field.File {
+				prop.Format = "binary"
+			}
+			form.Properties[field.Name] = prop
+			if field.Required {
+				form.Required = append(form.Required, field.Name)
+			}
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"multipart/form-data": {Schema: form}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status), Content: make(map[string]*MediaType)}
+	if len(route.Produces) > 0 {
+		for _, contentType := range route.Produces {
+			success.Content[contentType] = &MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+	} else {
+		success.Content["application/json"] = &MediaType{Schema: s.envelope(route.Data, route.Paginated)}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errs := append([]int{http.StatusBadRequest, http.StatusInternalServerError}, route.Errors...)
+	if route.Auth {
+		errs = append(errs, http.StatusUnauthorized)
+	}
+	if route.Admin {
+		errs = append(errs, http.StatusForbidden)
+	}
+	if len(params) > 0 {
+		errs = append(errs, http.StatusNotFound)
+	}
+	if route.Body != nil {
+		errs = append(errs, http.StatusUnprocessableEntity)
+	}
+	for _, status := range errs {
+		op.Responses[strconv.Itoa(status)] = s.errorResponse(status)
+	}
+	return op
+}
+
+// envelope is the helper.Response (or ResponseWithPagination) wrapper with
+// its data member typed.
+func (s *schemas) envelope(data interface{}, paginated bool) *Schema {
+	var env *Schema
+	if paginated {
+		env = s.object(reflect.TypeOf(helper.ResponseWithPagination{}))
+	} else {
+		env = s.object(reflect.TypeOf(helper.Response{}))
+	}
+	if data != nil {
+		env.Properties["data"] = s.of(data)
+	}
+	return env
+}
+
+// errorResponse is the body written by middleware.ErrorHandler, as
+// helper.Response or, when the client accepts it, RFC 7807 problem+json.
+// Validation failures list helper.FieldError values, other errors strings.
+func (s *schemas) errorResponse(status int) *Response {
+	errs := &Schema{Type: "array", Items: &Schema{Type: "string"}}
+	if status == http.StatusUnprocessableEntity {
+		errs = &Schema{Type: "array", Items: s.of(helper.FieldError{})}
+	}
+	env := s.envelope(nil, false)
+	env.Properties["errors"] = errs
+	problem := s.object(reflect.TypeOf(helper.Problem{}))
+	problem.Properties["errors"] = errs
+	return &Response{
+		Description: http.StatusText(status),
+		Content: map[string]*MediaType{
+			"application/json":        {Schema: env},
+			helper.ContentTypeProblem: {Schema: problem},
+		},
+	}
+}
+
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.TrimLeft(segment, ":*")
+		if segment == "" || segment == "api" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(segment[:1]))
+		b.WriteString(segment[1:])
+	}
+	return b.String()
+}
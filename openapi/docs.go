@@ -0,0 +1,202 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+//go:embed docs.html
+var docsPage string
+
+var docsTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"typeName": typeName,
+}).Parse(docsPage))
+
+var docsMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+type docsView struct {
+	Info    Info
+	SpecURL string
+	Tags    []*docsTag
+	Schemas []docsSchema
+}
+
+type docsTag struct {
+	Tag
+	Operations []docsOperation
+}
+
+type docsOperation struct {
+	*Operation
+	Method, Path string
+	Body         []docsContent
+	Responses    []docsResponse
+}
+
+type docsResponse struct {
+	Status, Description string
+	Content             []docsContent
+}
+
+type docsContent struct {
+	ContentType, Type string
+}
+
+type docsSchema struct {
+	Name, Description string
+	Properties        []docsProperty
+}
+
+type docsProperty struct {
+	Name     string
+	Required bool
+	Schema   *Schema
+}
+
+// renderDocs renders doc as a standalone HTML page. The page runs no
+// script, so it needs nothing but this package to be reviewed.
+func renderDocs(doc *Document, specURL string) ([]byte, error) {
+	view := docsView{Info: doc.Info, SpecURL: specURL}
+	tags := make(map[string]*docsTag)
+	for _, tag := range doc.Tags {
+		t := &docsTag{Tag: tag}
+		tags[tag.Name] = t
+		view.Tags = append(view.Tags, t)
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, method := range docsMethods {
+			op := doc.Paths[path].Operation(method)
+			if op == nil {
+				continue
+			}
+			name := "Other"
+			if len(op.Tags) > 0 {
+				name = op.Tags[0]
+			}
+			t, ok := tags[name]
+			if !ok {
+				t = &docsTag{Tag: Tag{Name: name}}
+				tags[name] = t
+				view.Tags = append(view.Tags, t)
+			}
+			t.Operations = append(t.Operations, docsOperation{
+				Operation: op,
+				Method:    method,
+				Path:      path,
+				Body:      docsBody(op.RequestBody),
+				Responses: docsResponses(op.Responses),
+			})
+		}
+	}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema := doc.Components.Schemas[name]
+		view.Schemas = append(view.Schemas, docsSchema{
+			Name:        name,
+			Description: schema.Description,
+			Properties:  docsProperties(schema),
+		})
+	}
+
+	var page bytes.Buffer
+	if err := docsTemplate.Execute(&page, view); err != nil {
+		return nil, err
+	}
+	return page.Bytes(), nil
+}
+
+func docsBody(body *RequestBody) []docsContent {
+	if body == nil {
+		return nil
+	}
+	return docsContents(body.Content)
+}
+
+func docsResponses(responses map[string]*Response) []docsResponse {
+	statuses := make([]string, 0, len(responses))
+	for status := range responses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	res := make([]docsResponse, len(statuses))
+	for i, status := range statuses {
+		res[i] = docsResponse{
+			Status:      status,
+			Description: responses[status].Description,
+			Content:     docsContents(responses[status].Content),
+		}
+	}
+	return res
+}
+
+func docsContents(content map[string]*MediaType) []docsContent {
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	res := make([]docsContent, len(types))
+	for i, contentType := range types {
+		res[i] = docsContent{ContentType: contentType, Type: typeName(content[contentType].Schema)}
+	}
+	return res
+}
+
+func docsProperties(schema *Schema) []docsProperty {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]docsProperty, len(names))
+	for i, name := range names {
+		res[i] = docsProperty{Name: name, Required: required[name], Schema: schema.Properties[name]}
+	}
+	return res
+}
+
+// typeName is a short description of schema: the name of the component it
+// refers to, []T for arrays, {name: type} for inline objects, or its type
+// and format.
+func typeName(schema *Schema) string {
+	switch {
+	case schema == nil:
+		return ""
+	case schema.Ref != "":
+		return schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+	case schema.Type == "array":
+		return "[]" + typeName(schema.Items)
+	case len(schema.Properties) > 0:
+		props := docsProperties(schema)
+		fields := make([]string, len(props))
+		for i, prop := range props {
+			fields[i] = prop.Name + ": " + typeName(prop.Schema)
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case schema.Format != "":
+		return schema.Type + " (" + schema.Format + ")"
+	case len(schema.Enum) > 0:
+		return schema.Type + ": " + strings.Join(schema.Enum, ", ")
+	case schema.Type == "":
+		return "any"
+	}
+	return schema.Type
+}
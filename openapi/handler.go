@@ -0,0 +1,41 @@
+package openapi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSONHandler serves the document returned by build. build runs on the
+// first request, once every route has been registered.
+func JSONHandler(build func() *Document) gin.HandlerFunc {
+	var (
+		once sync.Once
+		doc  *Document
+	)
+	return func(c *gin.Context) {
+		once.Do(func() { doc = build() })
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// DocsHandler serves an HTML page documenting the document returned by
+// build, with a link to specURL. Like JSONHandler it renders on the first
+// request; a rendering error is left to middleware.ErrorHandler.
+func DocsHandler(build func() *Document, specURL string) gin.HandlerFunc {
+	var (
+		once sync.Once
+		page []byte
+		err  error
+	)
+	return func(c *gin.Context) {
+		once.Do(func() { page, err = renderDocs(build(), specURL) })
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	}
+}
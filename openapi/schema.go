@@ -0,0 +1,171 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// schemas generates component schemas for Go types, keyed by type name.
+type schemas struct {
+	components map[string]*Schema
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: make(map[string]*Schema)}
+}
+
+// of returns the schema for the type of v, registering named structs as
+// components and returning a reference to them.
+func (s *schemas) of(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		if _, ok := s.components[t.Name()]; !ok {
+			// Reserve the name first so self-referencing types terminate.
+			s.components[t.Name()] = &Schema{}
+			*s.components[t.Name()] = *s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	}
+	// interface{} and anything else accept any value.
+	return &Schema{}
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	obj := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		prop := s.schema(field.Type)
+		if applyBinding(prop, field.Tag.Get("binding")) {
+			obj.Required = append(obj.Required, name)
+		}
+		obj.Properties[name] = prop
+	}
+	return obj
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// applyBinding translates go-playground validator rules from a binding tag
+// into schema constraints and reports whether the field is required. Rules
+// after "dive" apply to the items of a slice.
+func applyBinding(prop *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	required := false
+	target := prop
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			if target == prop {
+				required = true
+			}
+		case "dive":
+			if target.Items != nil {
+				target = target.Items
+			}
+		case "min", "max", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			applyBound(target, name, n)
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "uuid", "uuid4":
+			target.Format = "uuid"
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		}
+	}
+	return required
+}
+
+func applyBound(target *Schema, rule string, n int) {
+	switch target.Type {
+	case "string":
+		if rule != "max" {
+			target.MinLength = &n
+		}
+		if rule != "min" {
+			target.MaxLength = &n
+		}
+	case "array":
+		if rule != "max" {
+			target.MinItems = &n
+		}
+		if rule != "min" {
+			target.MaxItems = &n
+		}
+	case "integer", "number":
+		f := float64(n)
+		if rule != "max" {
+			target.Minimum = &f
+		}
+		if rule != "min" {
+			target.Maximum = &f
+		}
+	}
+}
@@ -14,6 +14,12 @@ type AuthorRepository interface {
 	Update(ctx context.Context, author *entities.Author) (*dto.UpdateAuthorResponse, error)
 	Delete(ctx context.Context, author entities.Author) error
 	FindByID(ctx context.Context, id string) (*dto.ReadAuthorResponse, error)
+	CreateBatch(ctx context.Context, authors []*entities.Author) error
+	UpdateBatch(ctx context.Context, authors []*entities.Author) error
+	DeleteBatch(ctx context.Context, ids []string) error
+	ExistingIDs(ctx context.Context, ids []string) (map[string]bool, error)
+	FindByNames(ctx context.Context, names []string) ([]*entities.Author, error)
+	FindByBookIDs(ctx context.Context, bookIDs []string) (map[string][]*dto.AuthorResponse, error)
 }
 
 type authorConnection struct {
@@ -29,7 +35,7 @@ func NewAuthorRepository(db *gorm.DB) AuthorRepository {
 func (db *authorConnection) Create(ctx context.Context, author *entities.Author) (*dto.AuthorResponse, error) {
 	res := db.connection.WithContext(ctx).Create(&author)
 	if res.Error != nil {
-		return nil, res.Error
+		return nil, dbError(res.Error, ErrAuthorNotFound)
 	}
 	authorRes := &dto.AuthorResponse{
 		ID:      author.ID.String(),
@@ -44,7 +50,15 @@ func (db *authorConnection) Update(ctx context.Context, author *entities.Author)
 		Name: author.Name, Country: author.Country,
 	})
 	if res.Error != nil {
-		return nil, res.Error
+		return nil, dbError(res.Error, ErrAuthorNotFound)
+	}
+	if res.RowsAffected == 0 {
+		// Updates skips the statement when name and country are both empty.
+		var count int64
+		db.connection.WithContext(ctx).Model(&entities.Author{}).Where("id = ?", author.ID).Count(&count)
+		if count == 0 {
+			return nil, ErrAuthorNotFound
+		}
 	}
 	authorRes := &dto.UpdateAuthorResponse{
 		ID:      author.ID.String(),
@@ -58,7 +72,10 @@ func (db *authorConnection) Delete(ctx context.Context, author entities.Author)
 	db.connection.Unscoped().Table("author_books").Where("author_id = ?", author.ID).Delete(nil)
 	res := db.connection.WithContext(ctx).Delete(author)
 	if res.Error != nil {
-		return res.Error
+		return dbError(res.Error, ErrAuthorNotFound)
+	}
+	if res.RowsAffected == 0 {
+		return ErrAuthorNotFound
 	}
 	return nil
 }
@@ -67,7 +84,7 @@ func (db *authorConnection) FindByID(ctx context.Context, id string) (*dto.ReadA
 	var author *entities.Author
 	res := db.connection.WithContext(ctx).Where("id = ?", id).First(&author)
 	if res.Error != nil {
-		return nil, res.Error
+		return nil, dbError(res.Error, ErrAuthorNotFound)
 	}
 	bookRes := make([]*dto.CreateBookResponse, len(author.Books))
 
@@ -99,7 +116,7 @@ func (db *authorConnection) GetAuthorByCondition(ctx context.Context, bookID str
 		query = query.Where("author_books.book_id = ?", bookID)
 	}
 	if title != "" {
-		query = query.Where("books.title LIKE ?", "%"+title+"%")
+		query = whereContains(query, "books.title", title)
 	}
 	var total int64
 
@@ -107,10 +124,10 @@ func (db *authorConnection) GetAuthorByCondition(ctx context.Context, bookID str
 
 	offset := entities.CalculateOffset(page, PageSize)
 
-	query = query.Offset(offset).Limit(PageSize)
+	query = query.Order("authors.id").Offset(offset).Limit(PageSize)
 	err := query.Preload("Books").Find(&authors).Error
 	if err != nil {
-		return nil, entities.Pagination{}, err
+		return nil, entities.Pagination{}, dbError(err, ErrAuthorNotFound)
 	}
 	pageInfo := entities.CalculatePagination(int(total), page, PageSize)
 
@@ -136,3 +153,98 @@ func (db *authorConnection) GetAuthorByCondition(ctx context.Context, bookID str
 	}
 	return authorRes, pageInfo, nil
 }
+
+func (db *authorConnection) CreateBatch(ctx context.Context, authors []*entities.Author) error {
+	if len(authors) == 0 {
+		return nil
+	}
+	err := db.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(authors, batchSize).Error
+	})
+	return dbError(err, ErrAuthorNotFound)
+}
+
+func (db *authorConnection) UpdateBatch(ctx context.Context, authors []*entities.Author) error {
+	return db.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, author := range authors {
+			res := tx.Model(&entities.Author{}).Where("id = ?", author.ID).Updates(&entities.Author{
+				Name: author.Name, Country: author.Country,
+			})
+			if res.Error != nil {
+				return &BatchItemError{Index: i, Err: dbError(res.Error, ErrAuthorNotFound)}
+			}
+			if res.RowsAffected == 0 {
+				return &BatchItemError{Index: i, Err: ErrAuthorNotFound}
+			}
+		}
+		return nil
+	})
+}
+
+func (db *authorConnection) DeleteBatch(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := db.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Table("author_books").Where("author_id IN ?", ids).Delete(nil)
+		if res.Error != nil {
+			return res.Error
+		}
+		return tx.Where("id IN ?", ids).Delete(&entities.Author{}).Error
+	})
+	return dbError(err, ErrAuthorNotFound)
+}
+
+func (db *authorConnection) ExistingIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	var found []string
+	res := db.connection.WithContext(ctx).Model(&entities.Author{}).Where("id IN ?", ids).Pluck("id", &found)
+	if res.Error != nil {
+		return nil, dbError(res.Error, ErrAuthorNotFound)
+	}
+	existing := make(map[string]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+func (db *authorConnection) FindByNames(ctx context.Context, names []string) ([]*entities.Author, error) {
+	var authors []*entities.Author
+	if len(names) == 0 {
+		return authors, nil
+	}
+	res := db.connection.WithContext(ctx).Where("name IN ?", names).Find(&authors)
+	if res.Error != nil {
+		return nil, dbError(res.Error, ErrAuthorNotFound)
+	}
+	return authors, nil
+}
+
+// FindByBookIDs loads the authors of several books in one query, keyed by
+// book id.
+func (db *authorConnection) FindByBookIDs(ctx context.Context, bookIDs []string) (map[string][]*dto.AuthorResponse, error) {
+	var rows []struct {
+		ID      string
+		Name    string
+		Country string
+		BookID  string
+	}
+	res := db.connection.WithContext(ctx).Table("authors").
+		Select("authors.id, authors.name, authors.country, author_books.book_id").
+		Joins("JOIN author_books ON author_books.author_id = authors.id").
+		Where("author_books.book_id IN ?", bookIDs).
+		Order("authors.name").
+		Scan(&rows)
+	if res.Error != nil {
+		return nil, dbError(res.Error, ErrAuthorNotFound)
+	}
+	authors := make(map[string][]*dto.AuthorResponse, len(bookIDs))
+	for _, row := range rows {
+		authors[row.BookID] = append(authors[row.BookID], &dto.AuthorResponse{
+			ID:      row.ID,
+			Name:    row.Name,
+			Country: row.Country,
+		})
+	}
+	return authors, nil
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import "fmt"
+
+// batchSize is the number of rows sent per INSERT by the batch methods.
+const batchSize = 100
+
+// BatchItemError reports which item of a batch operation made the whole
+// transaction fail.
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
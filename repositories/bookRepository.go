@@ -2,10 +2,12 @@ package repositories
 
 import (
 	"context"
-	"fmt"
+	"time"
 
+	"github.com/aldisaputra17/book-store/apperror"
 	"github.com/aldisaputra17/book-store/dto"
 	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -16,6 +18,14 @@ type BookRepository interface {
 	FindByID(ctx context.Context, id string) (*dto.ReadBookResponse, error)
 	AddAuthor(ctx context.Context, authorbook *entities.AuthorBook) error
 	GetBookByCondition(ctx context.Context, authorID string, name string, page int, PageSize int) ([]dto.ReadBookResponse, entities.Pagination, error)
+	CreateBatch(ctx context.Context, books []*entities.Book) error
+	// ImportBatch creates authors and then books in one transaction.
+	ImportBatch(ctx context.Context, authors []*entities.Author, books []*entities.Book) error
+	UpdateBatch(ctx context.Context, books []*entities.Book) error
+	DeleteBatch(ctx context.Context, ids []string) error
+	ExistingIDs(ctx context.Context, ids []string) (map[string]bool, error)
+	Export(ctx context.Context, authorID string, name string, fn func(books []*entities.Book) error) error
+	FindByAuthorIDs(ctx context.Context, authorIDs []string) (map[string][]*dto.CreateBookResponse, error)
 }
 
 type bookConnection struct {
@@ -31,7 +41,7 @@ func NewBookRepository(db *gorm.DB) BookRepository {
 func (db *bookConnection) Create(ctx context.Context, book *entities.Book) (*dto.CreateBookResponse, error) {
 	res := db.connection.WithContext(ctx).Create(&book)
 	if res.Error != nil {
-		return nil, res.Error
+		return nil, dbError(res.Error, ErrBookNotFound)
 	}
 	bookRes := &dto.CreateBookResponse{
 		ID:            book.ID.String(),
@@ -43,14 +53,17 @@ func (db *bookConnection) Create(ctx context.Context, book *entities.Book) (*dto
 }
 
 func (db *bookConnection) Update(ctx context.Context, book *entities.Book) (*dto.UpdateBookResponse, error) {
-	if len(book.ID) == 0 {
-		return nil, fmt.Errorf("id not found")
+	if book.ID == uuid.Nil {
+		return nil, apperror.Validation(apperror.CodeInvalidID, "id is required", nil)
 	}
 	res := db.connection.WithContext(ctx).Model(&book).Where("id = ?", book.ID).Updates(entities.Book{
 		Title: book.Title,
 	})
 	if res.Error != nil {
-		return nil, res.Error
+		return nil, dbError(res.Error, ErrBookNotFound)
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrBookNotFound
 	}
 	bookRes := &dto.UpdateBookResponse{
 		ID:    book.ID.String(),
@@ -65,7 +78,10 @@ func (db *bookConnection) Delete(ctx context.Context, book entities.Book) error
 
 	res := db.connection.WithContext(ctx).Delete(book)
 	if res.Error != nil {
-		return res.Error
+		return dbError(res.Error, ErrBookNotFound)
+	}
+	if res.RowsAffected == 0 {
+		return ErrBookNotFound
 	}
 	return nil
 }
@@ -73,7 +89,7 @@ func (db *bookConnection) Delete(ctx context.Context, book entities.Book) error
 func (db *bookConnection) AddAuthor(ctx context.Context, authorbook *entities.AuthorBook) error {
 	res := db.connection.WithContext(ctx).Create(&authorbook)
 	if res.Error != nil {
-		return res.Error
+		return dbError(res.Error, ErrAuthorNotFound)
 	}
 	return nil
 }
@@ -82,7 +98,7 @@ func (db *bookConnection) FindByID(ctx context.Context, id string) (*dto.ReadBoo
 	var book *entities.Book
 	res := db.connection.WithContext(ctx).Where("id = ?", id).First(&book)
 	if res.Error != nil {
-		return nil, res.Error
+		return nil, dbError(res.Error, ErrBookNotFound)
 	}
 	authorRes := make([]*dto.AuthorResponse, len(book.Authors))
 
@@ -115,7 +131,7 @@ func (db *bookConnection) GetBookByCondition(ctx context.Context, authorID strin
 	}
 
 	if name != "" {
-		query = query.Where("authors.name LIKE ?", "%"+name+"%")
+		query = whereContains(query, "authors.name", name)
 	}
 
 	var total int64
@@ -124,10 +140,10 @@ func (db *bookConnection) GetBookByCondition(ctx context.Context, authorID strin
 
 	offset := entities.CalculateOffset(page, PageSize)
 
-	query = query.Offset(offset).Limit(PageSize)
+	query = query.Order("books.id").Offset(offset).Limit(PageSize)
 	err := query.Preload("Authors").Find(&books).Error
 	if err != nil {
-		return nil, entities.Pagination{}, err
+		return nil, entities.Pagination{}, dbError(err, ErrBookNotFound)
 	}
 	pageInfo := entities.CalculatePagination(int(total), page, PageSize)
 
@@ -153,3 +169,154 @@ func (db *bookConnection) GetBookByCondition(ctx context.Context, authorID strin
 	}
 	return bookRes, pageInfo, nil
 }
+
+func (db *bookConnection) CreateBatch(ctx context.Context, books []*entities.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	err := db.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createBooks(tx, books)
+	})
+	return dbError(err, ErrBookNotFound)
+}
+
+func (db *bookConnection) ImportBatch(ctx context.Context, authors []*entities.Author, books []*entities.Book) error {
+	if len(authors) == 0 && len(books) == 0 {
+		return nil
+	}
+	err := db.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(authors) > 0 {
+			if err := tx.CreateInBatches(authors, batchSize).Error; err != nil {
+				return err
+			}
+		}
+		if len(books) == 0 {
+			return nil
+		}
+		return createBooks(tx, books)
+	})
+	return dbError(err, ErrBookNotFound)
+}
+
+// createBooks inserts books and their author links within tx.
+func createBooks(tx *gorm.DB, books []*entities.Book) error {
+	if err := tx.CreateInBatches(books, batchSize).Error; err != nil {
+		return err
+	}
+	var authorBooks []*entities.AuthorBook
+	for _, book := range books {
+		for _, authorID := range book.AuthorID {
+			authorBooks = append(authorBooks, &entities.AuthorBook{
+				AuthorID: authorID,
+				BookID:   book.ID.String(),
+			})
+		}
+	}
+	if len(authorBooks) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(authorBooks, batchSize).Error
+}
+
+func (db *bookConnection) UpdateBatch(ctx context.Context, books []*entities.Book) error {
+	return db.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, book := range books {
+			res := tx.Model(&entities.Book{}).Where("id = ?", book.ID).Updates(entities.Book{
+				Title: book.Title,
+			})
+			if res.Error != nil {
+				return &BatchItemError{Index: i, Err: dbError(res.Error, ErrBookNotFound)}
+			}
+			if res.RowsAffected == 0 {
+				return &BatchItemError{Index: i, Err: ErrBookNotFound}
+			}
+		}
+		return nil
+	})
+}
+
+func (db *bookConnection) DeleteBatch(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := db.connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Table("author_books").Where("book_id IN ?", ids).Delete(nil)
+		if res.Error != nil {
+			return res.Error
+		}
+		return tx.Where("id IN ?", ids).Delete(&entities.Book{}).Error
+	})
+	return dbError(err, ErrBookNotFound)
+}
+
+func (db *bookConnection) ExistingIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	var found []string
+	res := db.connection.WithContext(ctx).Model(&entities.Book{}).Where("id IN ?", ids).Pluck("id", &found)
+	if res.Error != nil {
+		return nil, dbError(res.Error, ErrBookNotFound)
+	}
+	existing := make(map[string]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+// exportBatchSize is the number of books loaded per query while exporting.
+const exportBatchSize = 500
+
+// Export walks every book matching the GetBookByCondition filters in primary
+// key order, handing them to fn one batch at a time with their authors
+// preloaded, so the whole catalog never has to be held in memory.
+func (db *bookConnection) Export(ctx context.Context, authorID string, name string, fn func(books []*entities.Book) error) error {
+	query := db.connection.WithContext(ctx).Model(&entities.Book{})
+	if authorID != "" || name != "" {
+		matching := db.connection.Table("author_books").
+			Select("author_books.book_id").
+			Joins("JOIN authors ON authors.id = author_books.author_id")
+		if authorID != "" {
+			matching = matching.Where("author_books.author_id = ?", authorID)
+		}
+		if name != "" {
+			matching = whereContains(matching, "authors.name", name)
+		}
+		query = query.Where("books.id IN (?)", matching)
+	}
+
+	var books []*entities.Book
+	res := query.Preload("Authors").FindInBatches(&books, exportBatchSize, func(tx *gorm.DB, batch int) error {
+		return fn(books)
+	})
+	return dbError(res.Error, ErrBookNotFound)
+}
+
+// FindByAuthorIDs loads the books of several authors in one query, keyed by
+// author id.
+func (db *bookConnection) FindByAuthorIDs(ctx context.Context, authorIDs []string) (map[string][]*dto.CreateBookResponse, error) {
+	var rows []struct {
+		ID            string
+		Title         string
+		PublishedYear time.Time
+		Isbn          string
+		AuthorID      string
+	}
+	res := db.connection.WithContext(ctx).Table("books").
+		Select("books.id, books.title, books.published_year, books.isbn, author_books.author_id").
+		Joins("JOIN author_books ON author_books.book_id = books.id").
+		Where("author_books.author_id IN ?", authorIDs).
+		Order("books.title").
+		Scan(&rows)
+	if res.Error != nil {
+		return nil, dbError(res.Error, ErrBookNotFound)
+	}
+	books := make(map[string][]*dto.CreateBookResponse, len(authorIDs))
+	for _, row := range rows {
+		books[row.AuthorID] = append(books[row.AuthorID], &dto.CreateBookResponse{
+			ID:            row.ID,
+			Title:         row.Title,
+			PublishedYear: row.PublishedYear,
+			Isbn:          row.Isbn,
+		})
+	}
+	return books, nil
+}
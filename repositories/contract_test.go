@@ -0,0 +1,578 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// repositorySet is one implementation of the three repositories, sharing
+// a store.
+type repositorySet struct {
+	books   BookRepository
+	authors AuthorRepository
+	users   UserRepository
+}
+
+// implementations are the repositories the contract tests hold to the same
+// behavior. Each call opens an empty store.
+var implementations = []struct {
+	name string
+	open func(t *testing.T) repositorySet
+}{
+	{"gorm", func(t *testing.T) repositorySet {
+		db := newSQLiteDB(t)
+		return repositorySet{NewBookRepository(db), NewAuthorRepository(db), NewUserRepository(db)}
+	}},
+	{"memory", func(t *testing.T) repositorySet {
+		store := NewMemoryStore()
+		return repositorySet{NewMemoryBookRepository(store), NewMemoryAuthorRepository(store), NewMemoryUserRepository(store)}
+	}},
+}
+
+var contractTests = []struct {
+	name string
+	run  func(t *testing.T, r repositorySet)
+}{
+	{"book crud", testBookCRUD},
+	{"book links", testBookLinks},
+	{"book listing", testBookListing},
+	{"book batches", testBookBatches},
+	{"book export", testBookExport},
+	{"author crud", testAuthorCRUD},
+	{"author listing", testAuthorListing},
+	{"author batches", testAuthorBatches},
+	{"related lookups", testRelatedLookups},
+	{"users", testUsers},
+}
+
+func TestRepositoryContract(t *testing.T) {
+	for _, impl := range implementations {
+		for _, tt := range contractTests {
+			impl, tt := impl, tt
+			t.Run(impl.name+"/"+tt.name, func(t *testing.T) {
+				tt.run(t, impl.open(t))
+			})
+		}
+	}
+}
+
+// catalog is the data seeded by seedCatalog. Ids are fixed so that the
+// order of listings is known: leGuin < pratchett < gaiman, and
+// dispossessed < leftHand < goodOmens < orphan.
+var (
+	leGuin    = &entities.Author{ID: uuid.MustParse("00000000-0000-0000-0000-00000000a001"), Name: "Ursula K. Le Guin", Country: "US"}
+	pratchett = &entities.Author{ID: uuid.MustParse("00000000-0000-0000-0000-00000000a002"), Name: "Terry Pratchett", Country: "GB"}
+	gaiman    = &entities.Author{ID: uuid.MustParse("00000000-0000-0000-0000-00000000a003"), Name: "Neil Gaiman", Country: "GB"}
+
+	dispossessed = &entities.Book{ID: uuid.MustParse("00000000-0000-0000-0000-00000000b001"), Title: "The Dispossessed", Isbn: "978-0061054884", PublishedYear: year(1974)}
+	leftHand     = &entities.Book{ID: uuid.MustParse("00000000-0000-0000-0000-00000000b002"), Title: "The Left Hand of Darkness", Isbn: "978-0441478125", PublishedYear: year(1969)}
+	goodOmens    = &entities.Book{ID: uuid.MustParse("00000000-0000-0000-0000-00000000b003"), Title: "Good Omens", Isbn: "978-0060853983", PublishedYear: year(1990)}
+	orphan       = &entities.Book{ID: uuid.MustParse("00000000-0000-0000-0000-00000000b004"), Title: "Orphan", PublishedYear: year(2000)}
+)
+
+func year(y int) time.Time {
+	return time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// seedCatalog stores the catalog: Le Guin wrote two books, Pratchett and
+// Gaiman wrote Good Omens together and nobody wrote the orphan.
+func seedCatalog(t *testing.T, r repositorySet) {
+	t.Helper()
+	ctx := context.Background()
+	for _, author := range []*entities.Author{leGuin, pratchett, gaiman} {
+		a := *author
+		if _, err := r.authors.Create(ctx, &a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, book := range []*entities.Book{dispossessed, leftHand, goodOmens, orphan} {
+		b := *book
+		if _, err := r.books.Create(ctx, &b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := []entities.AuthorBook{
+		{AuthorID: leGuin.ID.String(), BookID: dispossessed.ID.String()},
+		{AuthorID: leGuin.ID.String(), BookID: leftHand.ID.String()},
+		{AuthorID: pratchett.ID.String(), BookID: goodOmens.ID.String()},
+		{AuthorID: gaiman.ID.String(), BookID: goodOmens.ID.String()},
+	}
+	for _, link := range links {
+		link := link
+		if err := r.books.AddAuthor(ctx, &link); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func wantCode(t *testing.T, what string, err error, code string) {
+	t.Helper()
+	if got := apperror.From(err); got.Code != code {
+		t.Errorf("%s: err = %v, want code %s", what, err, code)
+	}
+}
+
+func testBookCRUD(t *testing.T, r repositorySet) {
+	ctx := context.Background()
+	book := &entities.Book{ID: uuid.New(), Title: "Mort", Isbn: "978-0552131063", PublishedYear: year(1987)}
+	created, err := r.books.Create(ctx, book)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != book.ID.String() || created.Title != "Mort" || created.Isbn != book.Isbn {
+		t.Errorf("Create = %+v", created)
+	}
+	_, err = r.books.Create(ctx, &entities.Book{ID: book.ID, Title: "Again"})
+	wantCode(t, "duplicate id", err, apperror.CodeConflict)
+
+	found, err := r.books.FindByID(ctx, book.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Title != "Mort" || !found.PublishedYear.Equal(book.PublishedYear) || len(found.Author) != 0 {
+		t.Errorf("FindByID = %+v", found)
+	}
+	if _, err := r.books.FindByID(ctx, uuid.NewString()); !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("FindByID of a missing book: %v", err)
+	}
+
+	updated, err := r.books.Update(ctx, &entities.Book{ID: book.ID, Title: "Reaper Man"})
+	if err != nil || updated.Title != "Reaper Man" {
+		t.Fatalf("Update = %+v, %v", updated, err)
+	}
+	if found, _ := r.books.FindByID(ctx, book.ID.String()); found.Title != "Reaper Man" || found.Isbn != book.Isbn {
+		t.Errorf("after Update = %+v", found)
+	}
+	if _, err := r.books.Update(ctx, &entities.Book{ID: uuid.New(), Title: "Nothing"}); !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("Update of a missing book: %v", err)
+	}
+	_, err = r.books.Update(ctx, &entities.Book{Title: "No id"})
+	wantCode(t, "Update without id", err, apperror.CodeInvalidID)
+
+	if err := r.books.Delete(ctx, entities.Book{ID: book.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.books.Delete(ctx, entities.Book{ID: book.ID}); !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("second Delete: %v", err)
+	}
+	if _, err := r.books.FindByID(ctx, book.ID.String()); !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("FindByID after Delete: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := r.books.FindByID(cancelled, dispossessed.ID.String()); err == nil {
+		t.Error("FindByID with a cancelled context succeeded")
+	}
+}
+
+func testBookLinks(t *testing.T, r repositorySet) {
+	ctx := context.Background()
+	seedCatalog(t, r)
+	err := r.books.AddAuthor(ctx, &entities.AuthorBook{AuthorID: uuid.NewString(), BookID: orphan.ID.String()})
+	wantCode(t, "unknown author", err, apperror.CodeUnknownReference)
+	err = r.books.AddAuthor(ctx, &entities.AuthorBook{AuthorID: gaiman.ID.String(), BookID: uuid.NewString()})
+	wantCode(t, "unknown book", err, apperror.CodeUnknownReference)
+	err = r.books.AddAuthor(ctx, &entities.AuthorBook{AuthorID: gaiman.ID.String(), BookID: goodOmens.ID.String()})
+	wantCode(t, "existing link", err, apperror.CodeConflict)
+
+	// Deleting a book or an author removes their links.
+	if err := r.books.Delete(ctx, entities.Book{ID: leftHand.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.authors.Delete(ctx, entities.Author{ID: gaiman.ID}); err != nil {
+		t.Fatal(err)
+	}
+	books, err := r.books.FindByAuthorIDs(ctx, []string{leGuin.ID.String(), gaiman.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books[leGuin.ID.String()]) != 1 || len(books[gaiman.ID.String()]) != 0 {
+		t.Errorf("books left: %v", books)
+	}
+}
+
+func testBookListing(t *testing.T, r repositorySet) {
+	ctx := context.Background()
+	seedCatalog(t, r)
+	list := func(authorID, name string, page, size int) []string {
+		t.Helper()
+		books, _, err := r.books.GetBookByCondition(ctx, authorID, name, page, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, len(books))
+		for i, book := range books {
+			ids[i] = book.ID
+		}
+		return ids
+	}
+	id := func(book *entities.Book) string { return book.ID.String() }
+
+	// A book is listed once per author; the orphan has none.
+	if got := list("", "", 1, 10); !equalStrings(got, []string{id(dispossessed), id(leftHand), id(goodOmens), id(goodOmens)}) {
+		t.Errorf("all books: %v", got)
+	}
+	if got := list(leGuin.ID.String(), "", 1, 10); !equalStrings(got, []string{id(dispossessed), id(leftHand)}) {
+		t.Errorf("by author: %v", got)
+	}
+	// Searches ignore case on every driver, Postgres included.
+	for _, name := range []string{"PRATCH", "tErRy pRaTcHeTt"} {
+		if got := list("", name, 1, 10); !equalStrings(got, []string{id(goodOmens)}) {
+			t.Errorf("by name %q: %v", name, got)
+		}
+	}
+	if got := list(leGuin.ID.String(), "gaiman", 1, 10); len(got) != 0 {
+		t.Errorf("by author and other name: %v", got)
+	}
+	if got := list("", "_", 1, 10); len(got) != 0 {
+		t.Errorf("_ matched as a wildcard: %v", got)
+	}
+
+	books, page, err := r.books.GetBookByCondition(ctx, "", "", 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != (entities.Pagination{TotalRecords: 4, TotalPages: 2, CurrentPage: 2, PageSize: 3}) {
+		t.Errorf("pagination %+v", page)
+	}
+	if len(books) != 1 || books[0].ID != id(goodOmens) || books[0].Title != goodOmens.Title || books[0].Isbn != goodOmens.Isbn {
+		t.Fatalf("second page %+v", books)
+	}
+	var authors []string
+	for _, author := range books[0].Author {
+		authors = append(authors, author.Name)
+	}
+	sort.Strings(authors)
+	if !equalStrings(authors, []string{gaiman.Name, pratchett.Name}) {
+		t.Errorf("authors of %s: %v", goodOmens.Title, authors)
+	}
+	if got := list("", "", 3, 3); len(got) != 0 {
+		t.Errorf("page past the end: %v", got)
+	}
+}
+
+func testBookBatches(t *testing.T, r repositorySet) {
+	ctx := context.Background()
+	seedCatalog(t, r)
+	colour := &entities.Book{ID: uuid.New(), Title: "The Colour of Magic", AuthorID: []string{pratchett.ID.String()}}
+	light := &entities.Book{ID: uuid.New(), Title: "The Light Fantastic", AuthorID: []string{pratchett.ID.String()}}
+	if err := r.books.CreateBatch(ctx, []*entities.Book{colour, light}); err != nil {
+		t.Fatal(err)
+	}
+	if books, _ := r.books.FindByAuthorIDs(ctx, []string{pratchett.ID.String()}); len(books[pratchett.ID.String()]) != 3 {
+		t.Errorf("books of Pratchett after CreateBatch: %v", books[pratchett.ID.String()])
+	}
+
+	// A failing item leaves the whole batch out.
+	sourcery := &entities.Book{ID: uuid.New(), Title: "Sourcery"}
+	if err := r.books.CreateBatch(ctx, []*entities.Book{sourcery, {ID: colour.ID, Title: "Again"}}); err == nil {
+		t.Error("CreateBatch with an existing id succeeded")
+	}
+	if err := r.books.CreateBatch(ctx, []*entities.Book{sourcery, {ID: uuid.New(), Title: "Nobody's", AuthorID: []string{uuid.NewString()}}}); err == nil {
+		t.Error("CreateBatch with an unknown author succeeded")
+	}
+	existing, err := r.books.ExistingIDs(ctx, []string{colour.ID.String(), sourcery.ID.String(), orphan.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(existing) != 2 || !existing[colour.ID.String()] || !existing[orphan.ID.String()] {
+		t.Errorf("ExistingIDs = %v", existing)
+	}
+
+	err = r.books.UpdateBatch(ctx, []*entities.Book{{ID: colour.ID, Title: "Colour"}, {ID: uuid.New(), Title: "Missing"}})
+	var itemErr *BatchItemError
+	if !errors.As(err, &itemErr) || itemErr.Index != 1 || !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("UpdateBatch with a missing book: %v", err)
+	}
+	if found, _ := r.books.FindByID(ctx, colour.ID.String()); found.Title != colour.Title {
+		t.Errorf("failed UpdateBatch changed %q", found.Title)
+	}
+	if err := r.books.UpdateBatch(ctx, []*entities.Book{{ID: colour.ID, Title: "Colour"}, {ID: light.ID, Title: "Light"}}); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := r.books.FindByID(ctx, light.ID.String()); found.Title != "Light" {
+		t.Errorf("after UpdateBatch %q", found.Title)
+	}
+
+	// ImportBatch creates authors and books together, or neither.
+	banks := &entities.Author{ID: uuid.New(), Name: "Iain M. Banks", Country: "GB"}
+	phlebas := &entities.Book{ID: uuid.New(), Title: "Consider Phlebas", AuthorID: []string{banks.ID.String(), banks.ID.String()}}
+	if err := r.books.ImportBatch(ctx, []*entities.Author{banks}, []*entities.Book{phlebas}); err == nil {
+		t.Error("ImportBatch linking an author twice succeeded")
+	}
+	if existing, _ := r.authors.ExistingIDs(ctx, []string{banks.ID.String()}); len(existing) != 0 {
+		t.Error("failed ImportBatch kept its author")
+	}
+	phlebas.AuthorID = phlebas.AuthorID[:1]
+	if err := r.books.ImportBatch(ctx, []*entities.Author{banks}, []*entities.Book{phlebas}); err != nil {
+		t.Fatal(err)
+	}
+	if books, _ := r.books.FindByAuthorIDs(ctx, []string{banks.ID.String()}); len(books[banks.ID.String()]) != 1 {
+		t.Errorf("books of Banks after ImportBatch: %v", books[banks.ID.String()])
+	}
+
+	if err := r.books.DeleteBatch(ctx, []string{colour.ID.String(), light.ID.String(), uuid.NewString()}); err != nil {
+		t.Fatal(err)
+	}
+	if existing, _ := r.books.ExistingIDs(ctx, []string{colour.ID.String(), light.ID.String()}); len(existing) != 0 {
+		t.Errorf("left after DeleteBatch: %v", existing)
+	}
+	if books, _ := r.books.FindByAuthorIDs(ctx, []string{pratchett.ID.String()}); len(books[pratchett.ID.String()]) != 1 {
+		t.Errorf("links left after DeleteBatch: %v", books[pratchett.ID.String()])
+	}
+}
+
+func testBookExport(t *testing.T, r repositorySet) {
+	ctx := context.Background()
+	seedCatalog(t, r)
+	export := func(authorID, name string) map[string]int {
+		t.Helper()
+		authors := map[string]int{}
+		var last string
+		err := r.books.Export(ctx, authorID, name, func(books []*entities.Book) error {
+			for _, book := range books {
+				if book.ID.String() <= last {
+					t.Errorf("%s exported after %s", book.ID, last)
+				}
+				last = book.ID.String()
+				authors[book.Title] = len(book.Authors)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return authors
+	}
+
+	all := export("", "")
+	if len(all) != 4 || all[goodOmens.Title] != 2 || all[orphan.Title] != 0 {
+		t.Errorf("export of everything: %v", all)
+	}
+	if got := export("", "nEiL GAIman"); len(got) != 1 || got[goodOmens.Title] != 2 {
+		t.Errorf("export by name: %v", got)
+	}
+	if got := export(leGuin.ID.String(), ""); len(got) != 2 {
+		t.Errorf("export by author: %v", got)
+	}
+
+	stop := errors.New("stop")
+	if err := r.books.Export(ctx, "", "", func([]*entities.Book) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("Export returned %v, want the error of fn", err)
+	}
+}
+
+func testAuthorCRUD(t *testing.T, r repositorySet) {
+	ctx := context.Background()
+	author := &entities.Author{ID: uuid.New(), Name: "Iain Banks", Country: "GB"}
+	if _, err := r.authors.Create(ctx, author); err != nil {
+		t.Fatal(err)
+	}
+	_, err := r.authors.Create(ctx, &entities.Author{ID: author.ID, Name: "Again"})
+	wantCode(t, "duplicate id", err, apperror.CodeConflict)
+
+	// Empty fields are left as they are.
+	if _, err := r.authors.Update(ctx, &entities.Author{ID: author.ID, Name: "Iain M. Banks"}); err != nil {
+		t.Fatal(err)
+	}
+	found, err := r.authors.FindByID(ctx, author.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Name != "Iain M. Banks" || found.Country != "GB" || len(found.Book) != 0 {
+		t.Errorf("after Update = %+v", found)
+	}
+	if _, err := r.authors.Update(ctx, &entities.Author{ID: author.ID}); err != nil {
+		t.Errorf("Update without changes: %v", err)
+	}
+	if _, err := r.authors.Update(ctx, &entities.Author{ID: uuid.New(), Name: "Nobody"}); !errors.Is(err, ErrAuthorNotFound) {
+		t.Errorf("Update of a missing author: %v", err)
+	}
+	if _, err := r.authors.Update(ctx, &entities.Author{ID: uuid.New()}); !errors.Is(err, ErrAuthorNotFound) {
+		t.Errorf("Update of a missing author without changes: %v", err)
+	}
+
+	if err := r.authors.Delete(ctx, entities.Author{ID: author.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.authors.Delete(ctx, entities.Author{ID: author.ID}); !errors.Is(err, ErrAuthorNotFound) {
+		t.Errorf("second Delete: %v", err)
+	}
+	if _, err := r.authors.FindByID(ctx, author.ID.String()); !errors.Is(err, ErrAuthorNotFound) {
+		t.Errorf("FindByID after Delete: %v", err)
+	}
+}
+
+func testAuthorListing(t *testing.T, r repositorySet) {
+	ctx := context.Background()
+	seedCatalog(t, r)
+	list := func(bookID, title string, page, size int) []string {
+		t.Helper()
+		authors, _, err := r.authors.GetAuthorByCondition(ctx, bookID, title, page, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, len(authors))
+		for i, author := range authors {
+			names[i] = author.Name
+		}
+		return names
+	}
+
+	// An author is listed once per book.
+	if got := list("", "", 1, 10); !equalStrings(got, []string{leGuin.Name, leGuin.Name, pratchett.Name, gaiman.Name}) {
+		t.Errorf("all authors: %v", got)
+	}
+	if got := list(goodOmens.ID.String(), "", 1, 10); !equalStrings(got, []string{pratchett.Name, gaiman.Name}) {
+		t.Errorf("by book: %v", got)
+	}
+	if got := list("", "lEfT hAnD oF dArKnEsS", 1, 10); !equalStrings(got, []string{leGuin.Name}) {
+		t.Errorf("by title: %v", got)
+	}
+
+	authors, page, err := r.authors.GetAuthorByCondition(ctx, "", "omens", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != (entities.Pagination{TotalRecords: 2, TotalPages: 2, CurrentPage: 1, PageSize: 1}) {
+		t.Errorf("pagination %+v", page)
+	}
+	if len(authors) != 1 || authors[0].Name != pratchett.Name || len(authors[0].Book) != 1 || authors[0].Book[0].ID != goodOmens.ID.String() {
+		t.Errorf("first page %+v", authors)
+	}
+}
+
+func testAuthorBatches(t *testing.T, r repositorySet) {
+	ctx := context.Background()
+	seedCatalog(t, r)
+	banks := &entities.Author{ID: uuid.New(), Name: "Iain Banks", Country: "GB"}
+	jemisin := &entities.Author{ID: uuid.New(), Name: "N. K. Jemisin", Country: "US"}
+	if err := r.authors.CreateBatch(ctx, []*entities.Author{banks, jemisin}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.authors.CreateBatch(ctx, []*entities.Author{{ID: uuid.New(), Name: "Le Guin"}, {ID: banks.ID, Name: "Again"}}); err == nil {
+		t.Error("CreateBatch with an existing id succeeded")
+	}
+	found, err := r.authors.FindByNames(ctx, []string{"Iain Banks", "Le Guin", "n. k. jemisin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != banks.ID {
+		t.Errorf("FindByNames = %+v", found)
+	}
+
+	err = r.authors.UpdateBatch(ctx, []*entities.Author{{ID: banks.ID, Country: "UK"}, {ID: uuid.New(), Name: "Missing"}})
+	var itemErr *BatchItemError
+	if !errors.As(err, &itemErr) || itemErr.Index != 1 || !errors.Is(err, ErrAuthorNotFound) {
+		t.Errorf("UpdateBatch with a missing author: %v", err)
+	}
+	if got, _ := r.authors.FindByID(ctx, banks.ID.String()); got.Country != "GB" {
+		t.Errorf("failed UpdateBatch changed the country to %q", got.Country)
+	}
+
+	if err := r.authors.DeleteBatch(ctx, []string{banks.ID.String(), leGuin.ID.String()}); err != nil {
+		t.Fatal(err)
+	}
+	existing, err := r.authors.ExistingIDs(ctx, []string{banks.ID.String(), leGuin.ID.String(), jemisin.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(existing) != 1 || !existing[jemisin.ID.String()] {
+		t.Errorf("ExistingIDs after DeleteBatch = %v", existing)
+	}
+	if books, _, _ := r.books.GetBookByCondition(ctx, leGuin.ID.String(), "", 1, 10); len(books) != 0 {
+		t.Errorf("books still linked to a deleted author: %+v", books)
+	}
+}
+
+func testRelatedLookups(t *testing.T, r repositorySet) {
+	ctx := context.Background()
+	seedCatalog(t, r)
+	books, err := r.books.FindByAuthorIDs(ctx, []string{leGuin.ID.String(), pratchett.ID.String(), uuid.NewString()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, book := range books[leGuin.ID.String()] {
+		titles = append(titles, book.Title)
+	}
+	if len(books) != 2 || !equalStrings(titles, []string{dispossessed.Title, leftHand.Title}) || len(books[pratchett.ID.String()]) != 1 {
+		t.Errorf("FindByAuthorIDs = %v", books)
+	}
+
+	authors, err := r.authors.FindByBookIDs(ctx, []string{goodOmens.ID.String(), orphan.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, author := range authors[goodOmens.ID.String()] {
+		names = append(names, author.Name)
+	}
+	if len(authors) != 1 || !equalStrings(names, []string{gaiman.Name, pratchett.Name}) {
+		t.Errorf("FindByBookIDs = %v", authors)
+	}
+}
+
+func testUsers(t *testing.T, r repositorySet) {
+	ctx := context.Background()
+	user := &entities.User{ID: uuid.New(), Email: "reader@example.com", Password: "secret12"}
+	created, err := r.users.Create(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != user.ID || created.CreatedAt.IsZero() || bcrypt.CompareHashAndPassword([]byte(created.Password), []byte("secret12")) != nil {
+		t.Errorf("Create = %+v", created)
+	}
+	_, err = r.users.Create(ctx, &entities.User{ID: uuid.New(), Email: "reader@example.com", Password: "other123"})
+	wantCode(t, "duplicate email", err, apperror.CodeEmailTaken)
+
+	if r.users.IsDuplicateEmail("reader@example.com").Error != nil {
+		t.Error("IsDuplicateEmail of a registered email is not nil")
+	}
+	if r.users.IsDuplicateEmail("nobody@example.com").Error == nil {
+		t.Error("IsDuplicateEmail of an unknown email is nil")
+	}
+	if found, ok := r.users.VerifyCredential("reader@example.com", "").(entities.User); !ok || found.ID != user.ID {
+		t.Errorf("VerifyCredential = %v", found)
+	}
+	if r.users.VerifyCredential("nobody@example.com", "") != nil {
+		t.Error("VerifyCredential of an unknown email is not nil")
+	}
+	if found := r.users.FindByEmail("reader@example.com"); found == nil || found.ID != user.ID || found.Admin {
+		t.Errorf("FindByEmail = %+v", found)
+	}
+	if found := r.users.FindByEmail("nobody@example.com"); found == nil || found.ID != uuid.Nil {
+		t.Errorf("FindByEmail of an unknown email = %+v", found)
+	}
+
+	if err := r.users.UpdatePassword(ctx, "reader@example.com", "changed1"); err != nil {
+		t.Fatal(err)
+	}
+	if found := r.users.FindByEmail("reader@example.com"); bcrypt.CompareHashAndPassword([]byte(found.Password), []byte("changed1")) != nil {
+		t.Error("UpdatePassword did not store the new password")
+	}
+	if err := r.users.UpdatePassword(ctx, "nobody@example.com", "changed1"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("UpdatePassword of an unknown email: %v", err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
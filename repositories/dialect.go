@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// whereContains filters query to rows whose column contains term, ignoring
+// case. Postgres LIKE is case-sensitive and SQLite LIKE is not, so both
+// sides are lowered rather than relying on either, or on ILIKE, which
+// SQLite lacks.
+func whereContains(query *gorm.DB, column string, term string) *gorm.DB {
+	return query.Where("LOWER("+column+`) LIKE LOWER(?) ESCAPE '\'`, "%"+likeEscaper.Replace(term)+"%")
+}
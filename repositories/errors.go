@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBookNotFound   = apperror.NotFound(apperror.CodeBookNotFound, "book not found")
+	ErrAuthorNotFound = apperror.NotFound(apperror.CodeAuthorNotFound, "author not found")
+	ErrUserNotFound   = apperror.NotFound(apperror.CodeUserNotFound, "user not found")
+)
+
+// Postgres error codes translated by dbError.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgInvalidTextRepr     = "22P02"
+)
+
+// SQLite extended result codes translated by dbError.
+const (
+	sqliteConstraintForeignKey = 787
+	sqliteConstraintPrimaryKey = 1555
+	sqliteConstraintUnique     = 2067
+)
+
+// sqliteError is implemented by the SQLite driver errors.
+type sqliteError interface {
+	error
+	Code() int
+}
+
+// dbError translates gorm, Postgres and SQLite errors into apperror values,
+// returning notFound for gorm.ErrRecordNotFound. Other errors pass through
+// unchanged.
+func dbError(err error, notFound error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	var liteErr sqliteError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return notFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return apperror.Wrap(apperror.KindConflict, apperror.CodeConflict, "record already exists", err)
+	case errors.As(err, &pgErr):
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return apperror.Wrap(apperror.KindConflict, apperror.CodeConflict, "record already exists", err)
+		case pgForeignKeyViolation:
+			return apperror.Validation(apperror.CodeUnknownReference, "referenced record does not exist", err)
+		case pgInvalidTextRepr:
+			return apperror.BadRequest(apperror.CodeInvalidID, "invalid id", err)
+		}
+	case errors.As(err, &liteErr):
+		switch liteErr.Code() {
+		case sqliteConstraintUnique, sqliteConstraintPrimaryKey:
+			return apperror.Wrap(apperror.KindConflict, apperror.CodeConflict, "record already exists", err)
+		case sqliteConstraintForeignKey:
+			return apperror.Validation(apperror.CodeUnknownReference, "referenced record does not exist", err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestDBError(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind apperror.Kind
+		code string
+	}{
+		{gorm.ErrRecordNotFound, apperror.KindNotFound, apperror.CodeBookNotFound},
+		{fmt.Errorf("query: %w", gorm.ErrRecordNotFound), apperror.KindNotFound, apperror.CodeBookNotFound},
+		{gorm.ErrDuplicatedKey, apperror.KindConflict, apperror.CodeConflict},
+		{&pgconn.PgError{Code: pgUniqueViolation}, apperror.KindConflict, apperror.CodeConflict},
+		{&pgconn.PgError{Code: pgForeignKeyViolation}, apperror.KindValidation, apperror.CodeUnknownReference},
+		{&pgconn.PgError{Code: pgInvalidTextRepr}, apperror.KindBadRequest, apperror.CodeInvalidID},
+		{&pgconn.PgError{Code: "57P01"}, apperror.KindInternal, apperror.CodeInternal},
+		{errors.New("conn closed"), apperror.KindInternal, apperror.CodeInternal},
+	}
+	for _, tt := range tests {
+		got := apperror.From(dbError(tt.err, ErrBookNotFound))
+		if got.Kind != tt.kind || got.Code != tt.code {
+			t.Errorf("dbError(%v) = %v/%s, want %v/%s", tt.err, got.Kind, got.Code, tt.kind, tt.code)
+		}
+	}
+	if dbError(nil, ErrBookNotFound) != nil {
+		t.Error("dbError(nil) is not nil")
+	}
+}
+
+func TestNotFoundSentinelMatchesWrapped(t *testing.T) {
+	err := fmt.Errorf("delete: %w", ErrAuthorNotFound)
+	if !errors.Is(err, ErrAuthorNotFound) || !apperror.IsNotFound(err) {
+		t.Error("wrapped sentinel does not match")
+	}
+	if errors.Is(err, ErrBookNotFound) {
+		t.Error("author error matches book sentinel")
+	}
+}
@@ -0,0 +1,281 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+)
+
+type authorMemory struct {
+	store *MemoryStore
+}
+
+// NewMemoryAuthorRepository returns an AuthorRepository keeping its
+// authors in store, for tests.
+func NewMemoryAuthorRepository(store *MemoryStore) AuthorRepository {
+	return &authorMemory{
+		store: store,
+	}
+}
+
+// authorRow is an author as stored: without their books.
+func authorRow(author *entities.Author) entities.Author {
+	return entities.Author{
+		ID:      author.ID,
+		Name:    author.Name,
+		Country: author.Country,
+	}
+}
+
+func (m *authorMemory) Create(ctx context.Context, author *entities.Author) (*dto.AuthorResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	if _, ok := m.store.authors[author.ID.String()]; ok {
+		return nil, errRecordExists
+	}
+	m.store.authors[author.ID.String()] = authorRow(author)
+	authorRes := &dto.AuthorResponse{
+		ID:      author.ID.String(),
+		Name:    author.Name,
+		Country: author.Country,
+	}
+	return authorRes, nil
+}
+
+func (m *authorMemory) Update(ctx context.Context, author *entities.Author) (*dto.UpdateAuthorResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	if err := m.update(author); err != nil {
+		return nil, err
+	}
+	authorRes := &dto.UpdateAuthorResponse{
+		ID:      author.ID.String(),
+		Name:    author.Name,
+		Country: author.Country,
+	}
+	return authorRes, nil
+}
+
+// update sets the non-empty name and country of a stored author, as
+// Updates does with a struct.
+func (m *authorMemory) update(author *entities.Author) error {
+	stored, ok := m.store.authors[author.ID.String()]
+	if !ok {
+		return ErrAuthorNotFound
+	}
+	if author.Name != "" {
+		stored.Name = author.Name
+	}
+	if author.Country != "" {
+		stored.Country = author.Country
+	}
+	m.store.authors[author.ID.String()] = stored
+	return nil
+}
+
+func (m *authorMemory) Delete(ctx context.Context, author entities.Author) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	id := author.ID.String()
+	m.store.unlinkAuthor(id)
+	if _, ok := m.store.authors[id]; !ok {
+		return ErrAuthorNotFound
+	}
+	delete(m.store.authors, id)
+	return nil
+}
+
+func (m *authorMemory) FindByID(ctx context.Context, id string) (*dto.ReadAuthorResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	author, ok := m.store.authors[id]
+	if !ok {
+		return nil, ErrAuthorNotFound
+	}
+	// The GORM repository does not preload the books here either.
+	authorRes := dto.ReadAuthorResponse{
+		ID:      author.ID.String(),
+		Name:    author.Name,
+		Country: author.Country,
+		Book:    []*dto.CreateBookResponse{},
+	}
+	return &authorRes, nil
+}
+
+// GetAuthorByCondition lists an author once per book matching the
+// filters, as the join of the GORM query does, ordered by id.
+func (m *authorMemory) GetAuthorByCondition(ctx context.Context, bookID string, title string, page int, PageSize int) ([]dto.ReadAuthorResponse, entities.Pagination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, entities.Pagination{}, err
+	}
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	links := m.store.links()
+	sort.SliceStable(links, func(i, j int) bool { return links[i].AuthorID < links[j].AuthorID })
+	var matching []entities.Author
+	for _, link := range links {
+		if bookID != "" && link.BookID != bookID {
+			continue
+		}
+		if title != "" && !containsFold(m.store.books[link.BookID].Title, title) {
+			continue
+		}
+		matching = append(matching, m.store.authors[link.AuthorID])
+	}
+	start, end := pageBounds(len(matching), page, PageSize)
+	pageInfo := entities.CalculatePagination(len(matching), page, PageSize)
+
+	authorRes := make([]dto.ReadAuthorResponse, 0, end-start)
+	for _, author := range matching[start:end] {
+		books := m.store.booksOf(author.ID.String())
+		bookRes := make([]*dto.CreateBookResponse, len(books))
+		for j, book := range books {
+			bookRes[j] = &dto.CreateBookResponse{
+				ID:            book.ID.String(),
+				Title:         book.Title,
+				PublishedYear: book.PublishedYear,
+				Isbn:          book.Isbn,
+			}
+		}
+		authorRes = append(authorRes, dto.ReadAuthorResponse{
+			ID:      author.ID.String(),
+			Name:    author.Name,
+			Country: author.Country,
+			Book:    bookRes,
+		})
+	}
+	return authorRes, pageInfo, nil
+}
+
+func (m *authorMemory) CreateBatch(ctx context.Context, authors []*entities.Author) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	ids := make(map[string]bool, len(authors))
+	for _, author := range authors {
+		id := author.ID.String()
+		if _, ok := m.store.authors[id]; ok || ids[id] {
+			return errRecordExists
+		}
+		ids[id] = true
+	}
+	for _, author := range authors {
+		m.store.authors[author.ID.String()] = authorRow(author)
+	}
+	return nil
+}
+
+func (m *authorMemory) UpdateBatch(ctx context.Context, authors []*entities.Author) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	// Roll back to the authors as they were when an item fails.
+	saved := make(map[string]entities.Author, len(authors))
+	for _, author := range authors {
+		if stored, ok := m.store.authors[author.ID.String()]; ok {
+			saved[author.ID.String()] = stored
+		}
+	}
+	for i, author := range authors {
+		if err := m.update(author); err != nil {
+			for id, stored := range saved {
+				m.store.authors[id] = stored
+			}
+			return &BatchItemError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+func (m *authorMemory) DeleteBatch(ctx context.Context, ids []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	for _, id := range ids {
+		m.store.unlinkAuthor(id)
+		delete(m.store.authors, id)
+	}
+	return nil
+}
+
+func (m *authorMemory) ExistingIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	existing := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := m.store.authors[id]; ok {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+// FindByNames returns the authors with exactly one of names, by id.
+func (m *authorMemory) FindByNames(ctx context.Context, names []string) ([]*entities.Author, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var authors []*entities.Author
+	for _, stored := range m.store.authors {
+		if wanted[stored.Name] {
+			author := stored
+			authors = append(authors, &author)
+		}
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].ID.String() < authors[j].ID.String() })
+	return authors, nil
+}
+
+func (m *authorMemory) FindByBookIDs(ctx context.Context, bookIDs []string) (map[string][]*dto.AuthorResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	authors := make(map[string][]*dto.AuthorResponse, len(bookIDs))
+	for _, bookID := range bookIDs {
+		if _, done := authors[bookID]; done {
+			continue
+		}
+		for _, author := range m.store.authorsOf(bookID) {
+			authors[bookID] = append(authors[bookID], &dto.AuthorResponse{
+				ID:      author.ID.String(),
+				Name:    author.Name,
+				Country: author.Country,
+			})
+		}
+		sort.SliceStable(authors[bookID], func(i, j int) bool { return authors[bookID][i].Name < authors[bookID][j].Name })
+	}
+	return authors, nil
+}
@@ -0,0 +1,389 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
+)
+
+type bookMemory struct {
+	store *MemoryStore
+}
+
+// NewMemoryBookRepository returns a BookRepository keeping its books in
+// store, for tests.
+func NewMemoryBookRepository(store *MemoryStore) BookRepository {
+	return &bookMemory{
+		store: store,
+	}
+}
+
+// bookRow is a book as stored: without its authors.
+func bookRow(book *entities.Book) entities.Book {
+	return entities.Book{
+		ID:            book.ID,
+		Title:         book.Title,
+		PublishedYear: book.PublishedYear,
+		Isbn:          book.Isbn,
+	}
+}
+
+func (m *bookMemory) Create(ctx context.Context, book *entities.Book) (*dto.CreateBookResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	if _, ok := m.store.books[book.ID.String()]; ok {
+		return nil, errRecordExists
+	}
+	m.store.books[book.ID.String()] = bookRow(book)
+	bookRes := &dto.CreateBookResponse{
+		ID:            book.ID.String(),
+		Title:         book.Title,
+		PublishedYear: book.PublishedYear,
+		Isbn:          book.Isbn,
+	}
+	return bookRes, nil
+}
+
+func (m *bookMemory) Update(ctx context.Context, book *entities.Book) (*dto.UpdateBookResponse, error) {
+	if book.ID == uuid.Nil {
+		return nil, apperror.Validation(apperror.CodeInvalidID, "id is required", nil)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	if err := m.update(book); err != nil {
+		return nil, err
+	}
+	bookRes := &dto.UpdateBookResponse{
+		ID:    book.ID.String(),
+		Title: book.Title,
+	}
+	return bookRes, nil
+}
+
+// update sets the title of a stored book. Like Updates, an empty title
+// changes no row and so reports the book as not found.
+func (m *bookMemory) update(book *entities.Book) error {
+	stored, ok := m.store.books[book.ID.String()]
+	if !ok || book.Title == "" {
+		return ErrBookNotFound
+	}
+	stored.Title = book.Title
+	m.store.books[book.ID.String()] = stored
+	return nil
+}
+
+func (m *bookMemory) Delete(ctx context.Context, book entities.Book) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	id := book.ID.String()
+	m.store.unlinkBook(id)
+	if _, ok := m.store.books[id]; !ok {
+		return ErrBookNotFound
+	}
+	delete(m.store.books, id)
+	return nil
+}
+
+func (m *bookMemory) FindByID(ctx context.Context, id string) (*dto.ReadBookResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	book, ok := m.store.books[id]
+	if !ok {
+		return nil, ErrBookNotFound
+	}
+	// The GORM repository does not preload the authors here either.
+	bookRes := dto.ReadBookResponse{
+		ID:            book.ID.String(),
+		Title:         book.Title,
+		PublishedYear: book.PublishedYear,
+		Isbn:          book.Isbn,
+		Author:        []*dto.AuthorResponse{},
+	}
+	return &bookRes, nil
+}
+
+func (m *bookMemory) AddAuthor(ctx context.Context, authorbook *entities.AuthorBook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	return m.link(*authorbook)
+}
+
+func (m *bookMemory) link(link entities.AuthorBook) error {
+	_, bookOK := m.store.books[link.BookID]
+	_, authorOK := m.store.authors[link.AuthorID]
+	if !bookOK || !authorOK {
+		return errUnknownReference
+	}
+	if m.store.authorBooks[link] {
+		return errRecordExists
+	}
+	m.store.authorBooks[link] = true
+	return nil
+}
+
+// GetBookByCondition lists a book once per author matching the filters, as
+// the join of the GORM query does, ordered by id.
+func (m *bookMemory) GetBookByCondition(ctx context.Context, authorID string, name string, page int, PageSize int) ([]dto.ReadBookResponse, entities.Pagination, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, entities.Pagination{}, err
+	}
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	var matching []entities.Book
+	for _, link := range m.store.links() {
+		if authorID != "" && link.AuthorID != authorID {
+			continue
+		}
+		if name != "" && !containsFold(m.store.authors[link.AuthorID].Name, name) {
+			continue
+		}
+		matching = append(matching, m.store.books[link.BookID])
+	}
+	start, end := pageBounds(len(matching), page, PageSize)
+	pageInfo := entities.CalculatePagination(len(matching), page, PageSize)
+
+	bookRes := make([]dto.ReadBookResponse, 0, end-start)
+	for _, book := range matching[start:end] {
+		authors := m.store.authorsOf(book.ID.String())
+		authorRes := make([]*dto.AuthorResponse, len(authors))
+		for j, author := range authors {
+			authorRes[j] = &dto.AuthorResponse{
+				ID:      author.ID.String(),
+				Name:    author.Name,
+				Country: author.Country,
+			}
+		}
+		bookRes = append(bookRes, dto.ReadBookResponse{
+			ID:            book.ID.String(),
+			Title:         book.Title,
+			PublishedYear: book.PublishedYear,
+			Isbn:          book.Isbn,
+			Author:        authorRes,
+		})
+	}
+	return bookRes, pageInfo, nil
+}
+
+func (m *bookMemory) CreateBatch(ctx context.Context, books []*entities.Book) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	links, err := m.checkBatch(books, nil)
+	if err != nil {
+		return err
+	}
+	m.storeBatch(books, links)
+	return nil
+}
+
+func (m *bookMemory) ImportBatch(ctx context.Context, authors []*entities.Author, books []*entities.Book) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	created := make(map[string]entities.Author, len(authors))
+	for _, author := range authors {
+		id := author.ID.String()
+		if _, ok := m.store.authors[id]; ok {
+			return errRecordExists
+		}
+		if _, ok := created[id]; ok {
+			return errRecordExists
+		}
+		created[id] = authorRow(author)
+	}
+	links, err := m.checkBatch(books, created)
+	if err != nil {
+		return err
+	}
+	for id, author := range created {
+		m.store.authors[id] = author
+	}
+	m.storeBatch(books, links)
+	return nil
+}
+
+// checkBatch checks everything about books before any is stored, so that
+// the batch is stored whole or not at all, and returns their author links.
+// Authors may be stored or among created. The store must be locked.
+func (m *bookMemory) checkBatch(books []*entities.Book, created map[string]entities.Author) (map[entities.AuthorBook]bool, error) {
+	ids := make(map[string]bool, len(books))
+	for _, book := range books {
+		id := book.ID.String()
+		if _, ok := m.store.books[id]; ok || ids[id] {
+			return nil, errRecordExists
+		}
+		ids[id] = true
+	}
+	links := map[entities.AuthorBook]bool{}
+	for _, book := range books {
+		for _, authorID := range book.AuthorID {
+			link := entities.AuthorBook{AuthorID: authorID, BookID: book.ID.String()}
+			_, stored := m.store.authors[authorID]
+			_, isNew := created[authorID]
+			if !stored && !isNew {
+				return nil, errUnknownReference
+			}
+			if links[link] {
+				return nil, errRecordExists
+			}
+			links[link] = true
+		}
+	}
+	return links, nil
+}
+
+// storeBatch stores books checked by checkBatch. The store must be locked.
+func (m *bookMemory) storeBatch(books []*entities.Book, links map[entities.AuthorBook]bool) {
+	for _, book := range books {
+		m.store.books[book.ID.String()] = bookRow(book)
+	}
+	for link := range links {
+		m.store.authorBooks[link] = true
+	}
+}
+
+func (m *bookMemory) UpdateBatch(ctx context.Context, books []*entities.Book) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	// Roll back to the books as they were when an item fails.
+	saved := make(map[string]entities.Book, len(books))
+	for _, book := range books {
+		if stored, ok := m.store.books[book.ID.String()]; ok {
+			saved[book.ID.String()] = stored
+		}
+	}
+	for i, book := range books {
+		if err := m.update(book); err != nil {
+			for id, stored := range saved {
+				m.store.books[id] = stored
+			}
+			return &BatchItemError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+func (m *bookMemory) DeleteBatch(ctx context.Context, ids []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	for _, id := range ids {
+		m.store.unlinkBook(id)
+		delete(m.store.books, id)
+	}
+	return nil
+}
+
+func (m *bookMemory) ExistingIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	existing := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := m.store.books[id]; ok {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+// Export hands fn the matching books in batches of exportBatchSize, by id.
+// The books are copied up front, so fn may use the repositories.
+func (m *bookMemory) Export(ctx context.Context, authorID string, name string, fn func(books []*entities.Book) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.RLock()
+	matching := map[string]bool{}
+	if authorID != "" || name != "" {
+		for link := range m.store.authorBooks {
+			if authorID != "" && link.AuthorID != authorID {
+				continue
+			}
+			if name != "" && !containsFold(m.store.authors[link.AuthorID].Name, name) {
+				continue
+			}
+			matching[link.BookID] = true
+		}
+	}
+	var books []*entities.Book
+	for id, stored := range m.store.books {
+		if (authorID != "" || name != "") && !matching[id] {
+			continue
+		}
+		book := stored
+		book.Authors = m.store.authorsOf(id)
+		books = append(books, &book)
+	}
+	m.store.mu.RUnlock()
+
+	sort.Slice(books, func(i, j int) bool { return books[i].ID.String() < books[j].ID.String() })
+	for start := 0; start < len(books); start += exportBatchSize {
+		end := start + exportBatchSize
+		if end > len(books) {
+			end = len(books)
+		}
+		if err := fn(books[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *bookMemory) FindByAuthorIDs(ctx context.Context, authorIDs []string) (map[string][]*dto.CreateBookResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	books := make(map[string][]*dto.CreateBookResponse, len(authorIDs))
+	for _, authorID := range authorIDs {
+		if _, done := books[authorID]; done {
+			continue
+		}
+		for _, book := range m.store.booksOf(authorID) {
+			books[authorID] = append(books[authorID], &dto.CreateBookResponse{
+				ID:            book.ID.String(),
+				Title:         book.Title,
+				PublishedYear: book.PublishedYear,
+				Isbn:          book.Isbn,
+			})
+		}
+		sort.SliceStable(books[authorID], func(i, j int) bool { return books[authorID][i].Title < books[authorID][j].Title })
+	}
+	return books, nil
+}
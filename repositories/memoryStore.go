@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/entities"
+)
+
+var (
+	errRecordExists     = apperror.Conflict(apperror.CodeConflict, "record already exists")
+	errUnknownReference = apperror.Validation(apperror.CodeUnknownReference, "referenced record does not exist", nil)
+)
+
+// MemoryStore holds the books, authors, links and users of the in-memory
+// repositories. Repositories sharing a store see each other's writes, as
+// GORM repositories sharing a database do. It is safe for concurrent use.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	books       map[string]entities.Book
+	authors     map[string]entities.Author
+	authorBooks map[entities.AuthorBook]bool
+	users       map[string]entities.User
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		books:       map[string]entities.Book{},
+		authors:     map[string]entities.Author{},
+		authorBooks: map[entities.AuthorBook]bool{},
+		users:       map[string]entities.User{},
+	}
+}
+
+// links returns the author_books rows ordered by book then author, the
+// order the joins of the listing queries are paged in.
+func (s *MemoryStore) links() []entities.AuthorBook {
+	links := make([]entities.AuthorBook, 0, len(s.authorBooks))
+	for link := range s.authorBooks {
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].BookID != links[j].BookID {
+			return links[i].BookID < links[j].BookID
+		}
+		return links[i].AuthorID < links[j].AuthorID
+	})
+	return links
+}
+
+func (s *MemoryStore) unlinkBook(bookID string) {
+	for link := range s.authorBooks {
+		if link.BookID == bookID {
+			delete(s.authorBooks, link)
+		}
+	}
+}
+
+func (s *MemoryStore) unlinkAuthor(authorID string) {
+	for link := range s.authorBooks {
+		if link.AuthorID == authorID {
+			delete(s.authorBooks, link)
+		}
+	}
+}
+
+// authorsOf returns the authors of a book by id, as Preload("Authors")
+// loads them.
+func (s *MemoryStore) authorsOf(bookID string) []*entities.Author {
+	var authors []*entities.Author
+	for link := range s.authorBooks {
+		if link.BookID == bookID {
+			author := s.authors[link.AuthorID]
+			authors = append(authors, &author)
+		}
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].ID.String() < authors[j].ID.String() })
+	return authors
+}
+
+// booksOf returns the books of an author by id, as Preload("Books") loads
+// them.
+func (s *MemoryStore) booksOf(authorID string) []*entities.Book {
+	var books []*entities.Book
+	for link := range s.authorBooks {
+		if link.AuthorID == authorID {
+			book := s.books[link.BookID]
+			books = append(books, &book)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID.String() < books[j].ID.String() })
+	return books
+}
+
+// containsFold matches as whereContains does.
+func containsFold(s, term string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(term))
+}
+
+// pageBounds returns the slice bounds of a page of n rows, with the
+// OFFSET and LIMIT semantics of the GORM queries: a negative offset or
+// page size is ignored.
+func pageBounds(n, page, pageSize int) (start, end int) {
+	start = entities.CalculateOffset(page, pageSize)
+	if start < 0 {
+		start = 0
+	}
+	if start > n {
+		start = n
+	}
+	end = n
+	if pageSize >= 0 && start+pageSize < n {
+		end = start + pageSize
+	}
+	return start, end
+}
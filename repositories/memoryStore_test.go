@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
+)
+
+func TestMemoryRepositoriesConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	books, authors := NewMemoryBookRepository(store), NewMemoryAuthorRepository(store)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			author := &entities.Author{ID: uuid.New(), Name: "Author"}
+			book := &entities.Book{ID: uuid.New(), Title: "Book"}
+			if _, err := authors.Create(ctx, author); err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := books.Create(ctx, book); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := books.AddAuthor(ctx, &entities.AuthorBook{AuthorID: author.ID.String(), BookID: book.ID.String()}); err != nil {
+				t.Error(err)
+			}
+			if _, _, err := books.GetBookByCondition(ctx, "", "auth", 1, 5); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, page, err := books.GetBookByCondition(ctx, "", "", 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.TotalRecords != 20 {
+		t.Errorf("%d books listed, want 20", page.TotalRecords)
+	}
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/entities"
+	"gorm.io/gorm"
+)
+
+type userMemory struct {
+	store *MemoryStore
+}
+
+// NewMemoryUserRepository returns a UserRepository keeping its users in
+// store, for tests. Passwords are hashed as by the GORM repository.
+func NewMemoryUserRepository(store *MemoryStore) UserRepository {
+	return &userMemory{
+		store: store,
+	}
+}
+
+func (m *userMemory) Create(ctx context.Context, user *entities.User) (*entities.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	_, idTaken := m.store.users[user.ID.String()]
+	if _, emailTaken := m.findByEmail(user.Email); idTaken || emailTaken {
+		return nil, apperror.Wrap(apperror.KindConflict, apperror.CodeEmailTaken, "email already registered", errRecordExists)
+	}
+	user.Password = hashAndSalt([]byte(user.Password))
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	stored := *user
+	stored.Token = ""
+	m.store.users[user.ID.String()] = stored
+	return user, nil
+}
+
+func (m *userMemory) findByEmail(email string) (entities.User, bool) {
+	for _, user := range m.store.users {
+		if user.Email == email {
+			return user, true
+		}
+	}
+	return entities.User{}, false
+}
+
+// IsDuplicateEmail reports through the Error of the returned *gorm.DB,
+// which is all callers may read of it: nil when the email is taken and
+// gorm.ErrRecordNotFound otherwise.
+func (m *userMemory) IsDuplicateEmail(email string) (tx *gorm.DB) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	if _, ok := m.findByEmail(email); !ok {
+		return &gorm.DB{Error: gorm.ErrRecordNotFound}
+	}
+	return &gorm.DB{RowsAffected: 1}
+}
+
+func (m *userMemory) VerifyCredential(email string, password string) interface{} {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	if user, ok := m.findByEmail(email); ok {
+		return user
+	}
+	return nil
+}
+
+// FindByEmail returns an empty user for an unknown email, as the GORM
+// repository does.
+func (m *userMemory) FindByEmail(email string) *entities.User {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	user, _ := m.findByEmail(email)
+	return &user
+}
+
+func (m *userMemory) UpdatePassword(ctx context.Context, email string, password string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	user, ok := m.findByEmail(email)
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.Password = hashAndSalt([]byte(password))
+	user.UpdatedAt = time.Now()
+	m.store.users[user.ID.String()] = user
+	return nil
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/database"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// newSQLiteDB opens a migrated in-memory SQLite database for the test.
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	cfg := config.Default().Database
+	cfg.Driver = config.DriverSQLite
+	cfg.URL = ":memory:"
+	db, err := database.ConnectionDB(context.Background(), cfg, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.CloseDatabaseConnection(db) })
+	if _, err := database.Migrate(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSQLiteSearchIgnoresCaseAndWildcards(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	books, authors := NewBookRepository(db), NewAuthorRepository(db)
+
+	author := &entities.Author{ID: uuid.New(), Name: "Ursula K. Le Guin", Country: "US"}
+	book := &entities.Book{ID: uuid.New(), Title: "The Dispossessed", PublishedYear: time.Date(1974, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if _, err := authors.Create(ctx, author); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := books.Create(ctx, book); err != nil {
+		t.Fatal(err)
+	}
+	if err := books.AddAuthor(ctx, &entities.AuthorBook{AuthorID: author.ID.String(), BookID: book.ID.String()}); err != nil {
+		t.Fatal(err)
+	}
+
+	found, page, err := books.GetBookByCondition(ctx, "", "le guin", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != book.ID.String() || page.TotalRecords != 1 {
+		t.Errorf("search by author name found %+v", found)
+	}
+	if found, _, _ := books.GetBookByCondition(ctx, "", "%", 1, 10); len(found) != 0 {
+		t.Errorf("%% matched as a wildcard: %+v", found)
+	}
+	if found, _, _ := authors.GetAuthorByCondition(ctx, "", "DISPOSSESSED", 1, 10); len(found) != 1 || found[0].ID != author.ID.String() {
+		t.Errorf("search by book title found %+v", found)
+	}
+}
+
+func TestSQLiteConstraintErrors(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+
+	book := &entities.Book{ID: uuid.New(), Title: "Orphan"}
+	if _, err := NewBookRepository(db).Create(ctx, book); err != nil {
+		t.Fatal(err)
+	}
+	err := NewBookRepository(db).AddAuthor(ctx, &entities.AuthorBook{AuthorID: uuid.NewString(), BookID: book.ID.String()})
+	if got := apperror.From(err); got.Code != apperror.CodeUnknownReference {
+		t.Errorf("unknown author: %v", err)
+	}
+
+	users := NewUserRepository(db)
+	if _, err := users.Create(ctx, &entities.User{ID: uuid.New(), Email: "reader@example.com", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = users.Create(ctx, &entities.User{ID: uuid.New(), Email: "reader@example.com", Password: "secret"})
+	if got := apperror.From(err); got.Code != apperror.CodeEmailTaken {
+		t.Errorf("duplicate email: %v", err)
+	}
+}
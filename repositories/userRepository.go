@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 
+	"github.com/aldisaputra17/book-store/apperror"
 	"github.com/aldisaputra17/book-store/entities"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -14,6 +15,7 @@ type UserRepository interface {
 	VerifyCredential(email string, password string) interface{}
 	IsDuplicateEmail(email string) (tx *gorm.DB)
 	FindByEmail(email string) *entities.User
+	UpdatePassword(ctx context.Context, email string, password string) error
 }
 
 type userConnection struct {
@@ -30,7 +32,11 @@ func (db *userConnection) Create(ctx context.Context, user *entities.User) (*ent
 	user.Password = hashAndSalt([]byte(user.Password))
 	res := db.connection.WithContext(ctx).Create(&user)
 	if res.Error != nil {
-		return nil, res.Error
+		err := dbError(res.Error, ErrUserNotFound)
+		if apperror.KindOf(err) == apperror.KindConflict {
+			return nil, apperror.Wrap(apperror.KindConflict, apperror.CodeEmailTaken, "email already registered", res.Error)
+		}
+		return nil, err
 	}
 	return user, nil
 }
@@ -55,6 +61,19 @@ func (db *userConnection) FindByEmail(email string) *entities.User {
 	return user
 }
 
+// UpdatePassword replaces the password of the user with email by the hash
+// of password.
+func (db *userConnection) UpdatePassword(ctx context.Context, email string, password string) error {
+	res := db.connection.WithContext(ctx).Model(&entities.User{}).Where("email = ?", email).Update("password", hashAndSalt([]byte(password)))
+	if res.Error != nil {
+		return dbError(res.Error, ErrUserNotFound)
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
 func hashAndSalt(pwd []byte) string {
 	hash, err := bcrypt.GenerateFromPassword(pwd, bcrypt.MinCost)
 	if err != nil {
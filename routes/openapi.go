@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/formats"
+	"github.com/aldisaputra17/book-store/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+var apiInfo = openapi.Info{
+	Title:       "Book Store API",
+	Description: "Books, authors and user authentication.",
+	Version:     "1.0.0",
+}
+
+var apiTags = []openapi.Tag{
+	{Name: "user", Description: "Registration and login"},
+	{Name: "book", Description: "Books"},
+	{Name: "author", Description: "Authors"},
+	{Name: "graphql", Description: "GraphQL endpoint over books and authors"},
+	{Name: "docs", Description: "API documentation"},
+	{Name: "health", Description: "Probes and metrics for the orchestrator"},
+}
+
+var (
+	pageParams = []*openapi.Parameter{
+		openapi.QueryParam("page", "integer", "Page number, starting at 1"),
+		openapi.QueryParam("page_size", "integer", "Items per page, 10 by default"),
+	}
+	bookFilterParams = []*openapi.Parameter{
+		openapi.QueryParam("author_id", "string", "Only books of this author"),
+		openapi.QueryParam("name", "string", "Only books with an author whose name contains this"),
+	}
+	authorFilterParams = []*openapi.Parameter{
+		openapi.QueryParam("book_id", "string", "Only authors of this book"),
+		openapi.QueryParam("title", "string", "Only authors with a book whose title contains this"),
+	}
+	formatParam = &openapi.Parameter{
+		Name:   "format",
+		In:     "query",
+		Schema: &openapi.Schema{Type: "string", Enum: []string{formats.FormatCSV, formats.FormatONIX, formats.FormatMARC, formats.FormatMARCXML}},
+	}
+)
+
+// metaDocs documents the unversioned routes of Register. A route missing
+// here or in the Docs of its Version is left out of the published
+// specification and fails the routes tests.
+var metaDocs = []openapi.Route{
+	{Method: http.MethodGet, Path: "/healthz", Tag: "health", Summary: "Liveness probe", Produces: []string{"application/json"}},
+	{Method: http.MethodGet, Path: "/metrics", Tag: "health", Summary: "Prometheus metrics", Produces: []string{"text/plain"}},
+	{Method: http.MethodGet, Path: "/readyz", Tag: "health", Summary: "Readiness probe, 503 when a required component fails or during shutdown", Produces: []string{"application/json"}},
+
+	{Method: http.MethodGet, Path: specPath, Tag: "docs", Summary: "This OpenAPI document", Produces: []string{"application/json"}},
+	{Method: http.MethodGet, Path: docsPath, Tag: "docs", Summary: "Rendered API documentation", Produces: []string{"text/html"}},
+
+	{Method: http.MethodPost, Path: "/graphql", Tag: "graphql", Summary: "Run a GraphQL operation, mutations need a token", Body: dto.GraphQLRequest{}, Produces: []string{"application/json"}},
+	{
+		Method: http.MethodGet, Path: "/graphql", Tag: "graphql", Summary: "Run a GraphQL query",
+		Query: []*openapi.Parameter{
+			{Name: "query", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+			openapi.QueryParam("operationName", "string", ""),
+			openapi.QueryParam("variables", "string", "JSON encoded variables"),
+		},
+		Produces: []string{"application/json"},
+	},
+}
+
+// v1Docs documents the routes of registerV1.
+var v1Docs = []openapi.Route{
+	{Method: http.MethodPost, Path: "/user/register", Tag: "user", Summary: "Register a user", Body: dto.AuthRequest{}, Status: http.StatusCreated, Data: entities.User{}, Errors: []int{http.StatusConflict}},
+	{Method: http.MethodPost, Path: "/user/login", Tag: "user", Summary: "Log in and get a token", Body: dto.AuthRequest{}, Data: entities.User{}, Errors: []int{http.StatusUnauthorized}},
+
+	{Method: http.MethodPost, Path: "/book", Tag: "book", Summary: "Create a book", Auth: true, Admin: true, Body: dto.CreateBookRequest{}, Status: http.StatusCreated, Data: dto.CreateBookResponse{}},
+	{Method: http.MethodGet, Path: "/book", Tag: "book", Summary: "List books", Query: append(bookFilterParams, pageParams...), Data: []dto.ReadBookResponse{}, Paginated: true},
+	{Method: http.MethodGet, Path: "/book/:id", Tag: "book", Summary: "Get a book", Data: dto.ReadBookResponse{}},
+	{Method: http.MethodPut, Path: "/book", Tag: "book", Summary: "Update a book", Auth: true, Admin: true, Body: dto.UpdateBookRequest{}, Data: dto.UpdateBookResponse{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodDelete, Path: "/book/:id", Tag: "book", Summary: "Delete a book", Auth: true, Admin: true},
+	{Method: http.MethodPost, Path: "/book/bulk", Tag: "book", Summary: "Create books in bulk", Auth: true, Admin: true, Body: dto.BulkCreateBookRequest{}, Status: http.StatusCreated, Data: dto.BulkResponse{}},
+	{Method: http.MethodPut, Path: "/book/bulk", Tag: "book", Summary: "Update books in bulk", Auth: true, Admin: true, Body: dto.BulkUpdateBookRequest{}, Data: dto.BulkResponse{}},
+	{Method: http.MethodDelete, Path: "/book/bulk", Tag: "book", Summary: "Delete books in bulk", Auth: true, Admin: true, Body: dto.BulkDeleteRequest{}, Data: dto.BulkResponse{}},
+	{Method: http.MethodGet, Path: "/book/export", Tag: "book", Summary: "Export books", Query: append([]*openapi.Parameter{formatParam}, bookFilterParams...), Produces: []string{"text/csv", "application/xml", "application/marc", "application/marcxml+xml"}},
+	{
+		Method: http.MethodPost, Path: "/book/import", Tag: "book", Summary: "Import books", Auth: true, Admin: true,
+		Query: []*openapi.Parameter{formatParam, openapi.QueryParam("dry_run", "boolean", "Validate without writing")},
+		Form: []openapi.FormField{
+			{Name: "file", Required: true, File: true, Description: "File to import"},
+			{Name: "mapping[title]", Description: "CSV header holding the title, likewise for id, isbn, published_year, authors, author_ids and author_country"},
+		},
+		Status: http.StatusCreated, Data: dto.ImportResponse{},
+	},
+
+	{Method: http.MethodPost, Path: "/author", Tag: "author", Summary: "Create an author", Auth: true, Admin: true, Body: dto.CreateAuthorRequest{}, Status: http.StatusCreated, Data: dto.AuthorResponse{}},
+	{Method: http.MethodGet, Path: "/author", Tag: "author", Summary: "List authors", Query: append(authorFilterParams, pageParams...), Data: []dto.ReadAuthorResponse{}, Paginated: true},
+	{Method: http.MethodGet, Path: "/author/:id", Tag: "author", Summary: "Get an author", Data: dto.ReadAuthorResponse{}},
+	{Method: http.MethodPut, Path: "/author", Tag: "author", Summary: "Update an author", Auth: true, Admin: true, Body: dto.UpdateAuthorRequest{}, Data: dto.UpdateAuthorResponse{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodDelete, Path: "/author/:id", Tag: "author", Summary: "Delete an author", Auth: true, Admin: true},
+	{Method: http.MethodPost, Path: "/author/bulk", Tag: "author", Summary: "Create authors in bulk", Auth: true, Admin: true, Body: dto.BulkCreateAuthorRequest{}, Status: http.StatusCreated, Data: dto.BulkResponse{}},
+	{Method: http.MethodPut, Path: "/author/bulk", Tag: "author", Summary: "Update authors in bulk", Auth: true, Admin: true, Body: dto.BulkUpdateAuthorRequest{}, Data: dto.BulkResponse{}},
+	{Method: http.MethodDelete, Path: "/author/bulk", Tag: "author", Summary: "Delete authors in bulk", Auth: true, Admin: true, Body: dto.BulkDeleteRequest{}, Data: dto.BulkResponse{}},
+}
+
+// Spec builds the OpenAPI document of the registered routes.
+func Spec(registered gin.RoutesInfo) *openapi.Document {
+	return openapi.Build(apiInfo, apiTags, registered, docs(Versions, Legacy))
+}
@@ -0,0 +1,116 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aldisaputra17/book-store/controllers"
+	"github.com/aldisaputra17/book-store/middleware"
+	"github.com/aldisaputra17/book-store/openapi"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	specPath = "/api/openapi.json"
+	docsPath = "/api/docs"
+)
+
+type Handlers struct {
+	AuthController    controllers.AuthController
+	BookController    controllers.BookController
+	AuthorController  controllers.AuthorController
+	GraphQLController controllers.GraphQLController
+	HealthController  controllers.HealthController
+	Metrics           http.Handler
+	JWTService        services.JWTService
+	IdempotencyStore  middleware.IdempotencyStore
+	IdempotencyTTL    time.Duration
+	// IdempotencyMaxBody limits the body of requests with an
+	// Idempotency-Key, which are read into memory.
+	IdempotencyMaxBody int64
+}
+
+// Register mounts GraphQL, the API documentation and every API version.
+func Register(r *gin.Engine, h Handlers) {
+	register(r, h, Versions, Legacy)
+}
+
+func register(r *gin.Engine, h Handlers, versions []Version, legacy Alias) {
+	r.GET("/healthz", h.HealthController.Live)
+	r.GET("/readyz", h.HealthController.Ready)
+	r.GET("/metrics", gin.WrapH(h.Metrics))
+
+	graphqlRoutes := r.Group("/graphql", middleware.ErrorHandler(), middleware.OptionalJWT(h.JWTService))
+	{
+		graphqlRoutes.POST("", h.GraphQLController.Query)
+		graphqlRoutes.GET("", h.GraphQLController.Query)
+	}
+
+	idempotency := middleware.Idempotency(h.IdempotencyStore, h.IdempotencyTTL, h.IdempotencyMaxBody)
+	api := r.Group(apiPrefix, idempotency, middleware.ErrorHandler())
+	spec := func() *openapi.Document {
+		return openapi.Build(apiInfo, apiTags, r.Routes(), docs(versions, legacy))
+	}
+	api.GET("/openapi.json", openapi.JSONHandler(spec))
+	api.GET("/docs", openapi.DocsHandler(spec, specPath))
+
+	// Deprecation runs first so that replayed idempotent responses carry
+	// its headers too.
+	for i, v := range versions {
+		var handlers []gin.HandlerFunc
+		if !v.Deprecated.IsZero() {
+			successor := ""
+			if i+1 < len(versions) {
+				successor = versions[i+1].prefix()
+			}
+			handlers = append(handlers, middleware.Deprecation(v.Deprecated, v.Sunset, v.prefix(), successor))
+		}
+		handlers = append(handlers, idempotency, middleware.ErrorHandler())
+		v.Register(r.Group(v.prefix(), handlers...), h)
+
+		if v.Name == legacy.Version {
+			aliasRoutes := r.Group(apiPrefix,
+				middleware.Deprecation(legacy.Deprecated, legacy.Sunset, apiPrefix, v.prefix()),
+				idempotency, middleware.ErrorHandler())
+			v.Register(aliasRoutes, h)
+		}
+	}
+}
+
+func registerV1(api *gin.RouterGroup, h Handlers) {
+	authorize := middleware.AuthorizeJWT(h.JWTService)
+	// Writes need the token of an administrator, see user create-admin.
+	admin := middleware.RequireAdmin()
+
+	authRoutes := api.Group("/user")
+	{
+		authRoutes.POST("/register", h.AuthController.Register)
+		authRoutes.POST("/login", h.AuthController.Login)
+	}
+
+	bookRoutes := api.Group("/book")
+	{
+		bookRoutes.POST("", authorize, admin, h.BookController.Create)
+		bookRoutes.GET("/export", h.BookController.Export)
+		bookRoutes.POST("/import", authorize, admin, h.BookController.Import)
+		bookRoutes.GET("/:id", h.BookController.FindByID)
+		bookRoutes.GET("", h.BookController.GetBookByCondition)
+		bookRoutes.PUT("", authorize, admin, h.BookController.Update)
+		bookRoutes.DELETE("/:id", authorize, admin, h.BookController.Delete)
+		bookRoutes.POST("/bulk", authorize, admin, h.BookController.BulkCreate)
+		bookRoutes.PUT("/bulk", authorize, admin, h.BookController.BulkUpdate)
+		bookRoutes.DELETE("/bulk", authorize, admin, h.BookController.BulkDelete)
+	}
+	authorRoutes := api.Group("/author")
+	{
+		authorRoutes.POST("", authorize, admin, h.AuthorController.Create)
+		authorRoutes.GET("", h.AuthorController.GetAuthorByCondition)
+		authorRoutes.GET("/:id", h.AuthorController.FindByID)
+		authorRoutes.PUT("", authorize, admin, h.AuthorController.Update)
+		authorRoutes.DELETE("/:id", authorize, admin, h.AuthorController.Delete)
+		authorRoutes.POST("/bulk", authorize, admin, h.AuthorController.BulkCreate)
+		authorRoutes.PUT("/bulk", authorize, admin, h.AuthorController.BulkUpdate)
+		authorRoutes.DELETE("/bulk", authorize, admin, h.AuthorController.BulkDelete)
+	}
+}
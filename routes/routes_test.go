@@ -0,0 +1,192 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/controllers"
+	"github.com/aldisaputra17/book-store/graph"
+	"github.com/aldisaputra17/book-store/health"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/aldisaputra17/book-store/metrics"
+	"github.com/aldisaputra17/book-store/middleware"
+	"github.com/aldisaputra17/book-store/openapi"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter() *gin.Engine {
+	return newVersionedRouter(Versions, Legacy)
+}
+
+func newVersionedRouter(versions []Version, legacy Alias) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	jwtService := services.NewJWTService(config.Default().JWT)
+	schema, err := graph.NewSchema(nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	register(r, Handlers{
+		GraphQLController: controllers.NewGraphQLController(schema),
+		HealthController:  controllers.NewHealthController(health.New(time.Second)),
+		Metrics:           metrics.New().Handler(),
+		AuthController:    controllers.NewAuthController(nil, jwtService),
+		BookController:    controllers.NewBookController(nil, jwtService),
+		AuthorController:  controllers.NewAuthorController(nil, jwtService),
+		JWTService:        jwtService,
+		IdempotencyStore:  middleware.NewMemoryIdempotencyStore(),
+	}, versions, legacy)
+	return r
+}
+
+func TestSpecDocumentsEveryRoute(t *testing.T) {
+	r := newTestRouter()
+	if missing := openapi.Missing(Spec(r.Routes()), r.Routes()); len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI spec, add them to Docs: %v", missing)
+	}
+}
+
+func TestDocsMatchRegisteredRoutes(t *testing.T) {
+	registered := make(map[string]bool)
+	for _, route := range newTestRouter().Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	for _, doc := range docs(Versions, Legacy) {
+		if !registered[doc.Method+" "+doc.Path] {
+			t.Errorf("Docs describes %s %s which is not registered", doc.Method, doc.Path)
+		}
+	}
+}
+
+func TestOpenAPIEndpoint(t *testing.T) {
+	w := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, specPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d", specPath, w.Code)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != openapi.Version {
+		t.Errorf("openapi = %q, want %q", doc.OpenAPI, openapi.Version)
+	}
+
+	auth, ok := doc.Components.Schemas["AuthRequest"]
+	if !ok {
+		t.Fatal("AuthRequest schema missing")
+	}
+	if len(auth.Required) != 2 {
+		t.Errorf("AuthRequest required = %v, want email and password", auth.Required)
+	}
+	if password := auth.Properties["password"]; password == nil || password.MinLength == nil || *password.MinLength != 6 {
+		t.Errorf("password schema = %+v, want minLength 6 from binding tag", password)
+	}
+
+	book := doc.Paths["/api/v1/book/{id}"]
+	if book == nil || book.Get == nil || book.Delete == nil {
+		t.Fatalf("/api/v1/book/{id} = %+v, want get and delete", book)
+	}
+	if len(book.Delete.Security) == 0 {
+		t.Error("DELETE /api/v1/book/{id} should require the jwt scheme")
+	}
+	if book.Get.Deprecated {
+		t.Error("GET /api/v1/book/{id} should not be deprecated")
+	}
+	if alias := doc.Paths["/api/book/{id}"]; alias == nil || alias.Get == nil || !alias.Get.Deprecated {
+		t.Error("GET /api/book/{id} should be documented as deprecated")
+	}
+}
+
+func TestInvalidIDIsBadRequest(t *testing.T) {
+	r := newTestRouter()
+	for _, path := range []string{"/api/v1/book/abc", "/api/v1/author/abc", "/api/book/abc"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status %d, want 400", path, w.Code)
+		}
+		var res helper.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Code != apperror.CodeInvalidID {
+			t.Errorf("GET %s: code %q, want %q", path, res.Code, apperror.CodeInvalidID)
+		}
+	}
+}
+
+func TestWritesRequireTokenBeforeBinding(t *testing.T) {
+	r := newTestRouter()
+	for _, path := range []string{"/api/v1/book", "/api/v1/author", "/api/book"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader("{}")))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("POST %s without token: status %d, want 401", path, w.Code)
+		}
+	}
+}
+
+func TestLegacyAliasIsDeprecated(t *testing.T) {
+	r := newTestRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/book/abc", nil))
+	if got := w.Header().Get(middleware.HeaderDeprecation); got != "@1792368000" {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if got := w.Header().Get(middleware.HeaderSunset); got != "Thu, 01 Apr 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+	if got := w.Header().Get(middleware.HeaderLink); got != `</api/v1/book/abc>; rel="successor-version"` {
+		t.Errorf("Link = %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/book/abc", nil))
+	if got := w.Header().Get(middleware.HeaderDeprecation); got != "" {
+		t.Errorf("current version sent Deprecation %q", got)
+	}
+}
+
+func TestVersionsSideBySide(t *testing.T) {
+	deprecated := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	v1 := Versions[0]
+	v1.Deprecated = deprecated
+	v2 := Version{
+		Name: "v2",
+		Register: func(api *gin.RouterGroup, h Handlers) {
+			api.GET("/book/:id", h.BookController.FindByID)
+		},
+		Docs: []openapi.Route{{Method: http.MethodGet, Path: "/book/:id", Tag: "book", Summary: "Get a book"}},
+	}
+	versions := []Version{v1, v2}
+	r := newVersionedRouter(versions, Legacy)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/book/abc", nil))
+	if w.Header().Get(middleware.HeaderDeprecation) == "" || w.Header().Get(middleware.HeaderSunset) != "" {
+		t.Errorf("v1 headers = %v, want Deprecation without Sunset", w.Header())
+	}
+	if got := w.Header().Get(middleware.HeaderLink); got != `</api/v2/book/abc>; rel="successor-version"` {
+		t.Errorf("v1 Link = %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/book/abc", nil))
+	if w.Code != http.StatusBadRequest || w.Header().Get(middleware.HeaderDeprecation) != "" {
+		t.Errorf("v2: status %d, headers %v", w.Code, w.Header())
+	}
+	spec := openapi.Build(apiInfo, apiTags, r.Routes(), docs(versions, Legacy))
+	if missing := openapi.Missing(spec, r.Routes()); len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI spec: %v", missing)
+	}
+}
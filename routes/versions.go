@@ -0,0 +1,73 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/aldisaputra17/book-store/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+const apiPrefix = "/api"
+
+// Version is a set of routes mounted under /api/<Name>. Every version is
+// registered from the same Handlers, so a new version reuses the services
+// and only brings its own controllers for the routes whose shape changes.
+type Version struct {
+	Name string
+	// Deprecated, when set, is announced with Sunset on every response of
+	// the version, linking to the same path in the next version.
+	Deprecated time.Time
+	Sunset     time.Time
+	Register   func(api *gin.RouterGroup, h Handlers)
+	// Docs documents the routes of Register, relative to the version root.
+	Docs []openapi.Route
+}
+
+func (v Version) prefix() string {
+	return apiPrefix + "/" + v.Name
+}
+
+// Alias keeps the routes of Version reachable without a version prefix
+// while clients migrate. Responses are marked deprecated and link to the
+// versioned path.
+type Alias struct {
+	Version    string
+	Deprecated time.Time
+	Sunset     time.Time
+}
+
+// Versions are mounted side by side, oldest first. To add /api/v2, append
+// a Version with its own Register and Docs and set Deprecated and Sunset
+// on the one it replaces.
+var Versions = []Version{
+	{Name: "v1", Register: registerV1, Docs: v1Docs},
+}
+
+// Legacy is what the unversioned /api routes serve.
+var Legacy = Alias{
+	Version:    "v1",
+	Deprecated: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+	Sunset:     time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// docs documents the routes of every version and of the legacy alias.
+func docs(versions []Version, legacy Alias) []openapi.Route {
+	all := append([]openapi.Route(nil), metaDocs...)
+	for _, v := range versions {
+		all = append(all, prefixDocs(v.Docs, v.prefix(), !v.Deprecated.IsZero())...)
+		if v.Name == legacy.Version {
+			all = append(all, prefixDocs(v.Docs, apiPrefix, true)...)
+		}
+	}
+	return all
+}
+
+func prefixDocs(docs []openapi.Route, prefix string, deprecated bool) []openapi.Route {
+	prefixed := make([]openapi.Route, len(docs))
+	for i, doc := range docs {
+		doc.Path = prefix + doc.Path
+		doc.Deprecated = deprecated
+		prefixed[i] = doc
+	}
+	return prefixed
+}
@@ -2,7 +2,6 @@ package services
 
 import (
 	"context"
-	"log"
 	"time"
 
 	"github.com/aldisaputra17/book-store/dto"
@@ -15,6 +14,8 @@ import (
 type AuthService interface {
 	VerifyCredential(email string, password string) interface{}
 	Register(ctx context.Context, registerReq *dto.AuthRequest) (*entities.User, error)
+	CreateAdmin(ctx context.Context, adminReq *dto.AuthRequest) (*entities.User, error)
+	ResetPassword(ctx context.Context, resetReq *dto.AuthRequest) error
 	IsDuplicateEmail(email string) bool
 	FindByEmail(email string) *entities.User
 }
@@ -45,6 +46,16 @@ func (service *authService) VerifyCredential(email string, password string) inte
 }
 
 func (service *authService) Register(ctx context.Context, registerReq *dto.AuthRequest) (*entities.User, error) {
+	return service.create(ctx, registerReq, false)
+}
+
+// CreateAdmin registers an administrator, who can write books and authors.
+// It is only reachable from the command line.
+func (service *authService) CreateAdmin(ctx context.Context, adminReq *dto.AuthRequest) (*entities.User, error) {
+	return service.create(ctx, adminReq, true)
+}
+
+func (service *authService) create(ctx context.Context, registerReq *dto.AuthRequest, admin bool) (*entities.User, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -53,6 +64,7 @@ func (service *authService) Register(ctx context.Context, registerReq *dto.AuthR
 		ID:        id,
 		Email:     registerReq.Email,
 		Password:  registerReq.Password,
+		Admin:     admin,
 		CreatedAt: time.Now(),
 	}
 	ctx, cancel := context.WithTimeout(ctx, service.contextTimeout)
@@ -65,6 +77,13 @@ func (service *authService) Register(ctx context.Context, registerReq *dto.AuthR
 	return res, nil
 }
 
+func (service *authService) ResetPassword(ctx context.Context, resetReq *dto.AuthRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, service.contextTimeout)
+	defer cancel()
+
+	return service.userRepository.UpdatePassword(ctx, resetReq.Email, resetReq.Password)
+}
+
 func (service *authService) IsDuplicateEmail(email string) bool {
 	res := service.userRepository.IsDuplicateEmail(email)
 	return !(res.Error == nil)
@@ -72,12 +91,7 @@ func (service *authService) IsDuplicateEmail(email string) bool {
 
 func comparePassword(hashedPwd string, plainPassword []byte) bool {
 	byteHash := []byte(hashedPwd)
-	err := bcrypt.CompareHashAndPassword(byteHash, plainPassword)
-	if err != nil {
-		log.Println(err)
-		return false
-	}
-	return true
+	return bcrypt.CompareHashAndPassword(byteHash, plainPassword) == nil
 }
 
 func (service *authService) FindByEmail(email string) *entities.User {
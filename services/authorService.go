@@ -2,11 +2,11 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/aldisaputra17/book-store/dto"
 	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/helper"
 	"github.com/aldisaputra17/book-store/repositories"
 	"github.com/google/uuid"
 )
@@ -17,7 +17,10 @@ type AuthorService interface {
 	Update(ctx context.Context, authorReq *dto.UpdateAuthorRequest) (*dto.UpdateAuthorResponse, error)
 	Delete(ctx context.Context, author entities.Author) error
 	FindByID(ctx context.Context, id string) (*dto.ReadAuthorResponse, error)
-	IsAllowedToEdit(ctx context.Context, authorID string) bool
+	BulkCreate(ctx context.Context, bulkReq *dto.BulkCreateAuthorRequest) (*dto.BulkResponse, error)
+	BulkUpdate(ctx context.Context, bulkReq *dto.BulkUpdateAuthorRequest) (*dto.BulkResponse, error)
+	BulkDelete(ctx context.Context, bulkReq *dto.BulkDeleteRequest) (*dto.BulkResponse, error)
+	FindByBookIDs(ctx context.Context, bookIDs []string) (map[string][]*dto.AuthorResponse, error)
 }
 
 type authorService struct {
@@ -92,11 +95,156 @@ func (service *authorService) GetAuthorByCondition(ctx context.Context, bookID s
 	return res, pageInfo, nil
 }
 
-func (service *authorService) IsAllowedToEdit(ctx context.Context, authorID string) bool {
-	author, err := service.authorRepository.FindByID(ctx, authorID)
+func (service *authorService) BulkCreate(ctx context.Context, bulkReq *dto.BulkCreateAuthorRequest) (*dto.BulkResponse, error) {
+	mode := dto.BulkMode(bulkReq.Mode)
+	results := newBulkResults(len(bulkReq.Items))
+	authors := make([]*entities.Author, 0, len(bulkReq.Items))
+	indexes := make([]int, 0, len(bulkReq.Items))
+
+	for i := range bulkReq.Items {
+		item := &bulkReq.Items[i]
+		if err := helper.ValidateStruct(item); err != nil {
+			invalidItem(&results[i], err)
+			continue
+		}
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return nil, err
+		}
+		authors = append(authors, &entities.Author{
+			ID:      id,
+			Name:    item.Name,
+			Country: item.Country,
+		})
+		indexes = append(indexes, i)
+	}
+	if mode == dto.BulkModeAtomic && len(authors) != len(bulkReq.Items) {
+		abortBulk(results)
+		return dto.NewBulkResponse(mode, results), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, service.contextTimeOut)
+	defer cancel()
+
+	err := service.authorRepository.CreateBatch(ctx, authors)
+	switch {
+	case err == nil:
+		for j, author := range authors {
+			bulkSucceeded(&results[indexes[j]], author.ID.String(), toAuthorResponse(author))
+		}
+	case mode == dto.BulkModeAtomic:
+		failBatch(ctx, results, indexes, err)
+	default:
+		// The batch insert failed as a whole, retry row by row to isolate
+		// the items that caused it.
+		for j, author := range authors {
+			if err := service.authorRepository.CreateBatch(ctx, []*entities.Author{author}); err != nil {
+				results[indexes[j]].Error = itemError(ctx, err)
+				continue
+			}
+			bulkSucceeded(&results[indexes[j]], author.ID.String(), toAuthorResponse(author))
+		}
+	}
+	return dto.NewBulkResponse(mode, results), nil
+}
+
+func (service *authorService) BulkUpdate(ctx context.Context, bulkReq *dto.BulkUpdateAuthorRequest) (*dto.BulkResponse, error) {
+	mode := dto.BulkMode(bulkReq.Mode)
+	results := newBulkResults(len(bulkReq.Items))
+	ids := make([]string, 0, len(bulkReq.Items))
+	indexes := make([]int, 0, len(bulkReq.Items))
+
+	for i := range bulkReq.Items {
+		item := &bulkReq.Items[i]
+		if err := helper.ValidateStruct(item); err != nil {
+			invalidItem(&results[i], err)
+			continue
+		}
+		ids = append(ids, item.ID.String())
+		indexes = append(indexes, i)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, service.contextTimeOut)
+	defer cancel()
+
+	existing, err := service.authorRepository.ExistingIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	ids, indexes = dropMissing(ids, indexes, existing, results)
+	if mode == dto.BulkModeAtomic && len(ids) != len(bulkReq.Items) {
+		abortBulk(results)
+		return dto.NewBulkResponse(mode, results), nil
+	}
+
+	authors := make([]*entities.Author, len(indexes))
+	for j, i := range indexes {
+		authors[j] = &entities.Author{
+			ID:      bulkReq.Items[i].ID,
+			Name:    bulkReq.Items[i].Name,
+			Country: bulkReq.Items[i].Country,
+		}
+	}
+
+	if mode == dto.BulkModeAtomic {
+		if err := service.authorRepository.UpdateBatch(ctx, authors); err != nil {
+			failBatch(ctx, results, indexes, err)
+			return dto.NewBulkResponse(mode, results), nil
+		}
+		for j, author := range authors {
+			bulkSucceeded(&results[indexes[j]], author.ID.String(), &dto.UpdateAuthorResponse{
+				ID:      author.ID.String(),
+				Name:    author.Name,
+				Country: author.Country,
+			})
+		}
+		return dto.NewBulkResponse(mode, results), nil
+	}
+
+	for j, author := range authors {
+		res, err := service.authorRepository.Update(ctx, author)
+		if err != nil {
+			results[indexes[j]].ID = author.ID.String()
+			results[indexes[j]].Error = itemError(ctx, err)
+			continue
+		}
+		bulkSucceeded(&results[indexes[j]], res.ID, res)
+	}
+	return dto.NewBulkResponse(mode, results), nil
+}
+
+func (service *authorService) BulkDelete(ctx context.Context, bulkReq *dto.BulkDeleteRequest) (*dto.BulkResponse, error) {
+	mode := dto.BulkMode(bulkReq.Mode)
+	results := newBulkResults(len(bulkReq.IDs))
+	ids, indexes := parseBulkIDs(bulkReq.IDs, results)
+
+	ctx, cancel := context.WithTimeout(ctx, service.contextTimeOut)
+	defer cancel()
+
+	existing, err := service.authorRepository.ExistingIDs(ctx, ids)
 	if err != nil {
-		return false
+		return nil, err
+	}
+	ids, indexes = dropMissing(ids, indexes, existing, results)
+	if mode == dto.BulkModeAtomic && len(ids) != len(bulkReq.IDs) {
+		abortBulk(results)
+		return dto.NewBulkResponse(mode, results), nil
+	}
+
+	deleteBatch(ctx, mode, results, ids, indexes, service.authorRepository.DeleteBatch)
+	return dto.NewBulkResponse(mode, results), nil
+}
+
+func (service *authorService) FindByBookIDs(ctx context.Context, bookIDs []string) (map[string][]*dto.AuthorResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.contextTimeOut)
+	defer cancel()
+	return service.authorRepository.FindByBookIDs(ctx, bookIDs)
+}
+
+func toAuthorResponse(author *entities.Author) *dto.AuthorResponse {
+	return &dto.AuthorResponse{
+		ID:      author.ID.String(),
+		Name:    author.Name,
+		Country: author.Country,
 	}
-	id := fmt.Sprintf("%v", author.ID)
-	return authorID == id
 }
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/repositories"
+)
+
+func TestAuthorBulkCreateModes(t *testing.T) {
+	ctx := context.Background()
+	authorRepo := repositories.NewMemoryAuthorRepository(repositories.NewMemoryStore())
+	service := NewAuthorService(authorRepo, time.Second)
+	items := []dto.CreateAuthorRequest{
+		{Name: "Ann Leckie", Country: "US"},
+		{Name: "Nameless"},
+	}
+
+	res, err := service.BulkCreate(ctx, &dto.BulkCreateAuthorRequest{Items: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 0 || len(res.Results[1].Fields) == 0 {
+		t.Fatalf("atomic results %+v", res.Results)
+	}
+	if authors, err := authorRepo.FindByNames(ctx, []string{"Ann Leckie"}); err != nil || len(authors) != 0 {
+		t.Errorf("rolled back batch stored %+v (%v)", authors, err)
+	}
+
+	res, err = service.BulkCreate(ctx, &dto.BulkCreateAuthorRequest{Mode: dto.BulkModeBestEffort, Items: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 1 || res.Failed != 1 {
+		t.Fatalf("best effort results %+v", res.Results)
+	}
+	if authors, err := authorRepo.FindByNames(ctx, []string{"Ann Leckie"}); err != nil || len(authors) != 1 {
+		t.Errorf("best effort batch stored %+v (%v)", authors, err)
+	}
+}
@@ -2,12 +2,12 @@ package services
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"time"
 
+	"github.com/aldisaputra17/book-store/apperror"
 	"github.com/aldisaputra17/book-store/dto"
 	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/formats"
 	"github.com/aldisaputra17/book-store/helper"
 	"github.com/aldisaputra17/book-store/repositories"
 	"github.com/google/uuid"
@@ -19,18 +19,25 @@ type BookService interface {
 	Delete(ctx context.Context, book entities.Book) error
 	FindByID(ctx context.Context, id string) (*dto.ReadBookResponse, error)
 	GetBookByCondition(ctx context.Context, authorID string, name string, page int, PageSize int) ([]dto.ReadBookResponse, entities.Pagination, error)
-	IsAllowedToEdit(ctx context.Context, bookID string) bool
+	BulkCreate(ctx context.Context, bulkReq *dto.BulkCreateBookRequest) (*dto.BulkResponse, error)
+	BulkUpdate(ctx context.Context, bulkReq *dto.BulkUpdateBookRequest) (*dto.BulkResponse, error)
+	BulkDelete(ctx context.Context, bulkReq *dto.BulkDeleteRequest) (*dto.BulkResponse, error)
+	Export(ctx context.Context, authorID string, name string, fn func(book *entities.Book) error) error
+	Import(ctx context.Context, records []formats.Record, dryRun bool) (*dto.ImportResponse, error)
+	FindByAuthorIDs(ctx context.Context, authorIDs []string) (map[string][]*dto.CreateBookResponse, error)
 }
 
 type bookService struct {
-	bookRepository repositories.BookRepository
-	contextTimeOut time.Duration
+	bookRepository   repositories.BookRepository
+	authorRepository repositories.AuthorRepository
+	contextTimeOut   time.Duration
 }
 
-func NewBookService(bookRepo repositories.BookRepository, timeout time.Duration) BookService {
+func NewBookService(bookRepo repositories.BookRepository, authorRepo repositories.AuthorRepository, timeout time.Duration) BookService {
 	return &bookService{
-		bookRepository: bookRepo,
-		contextTimeOut: timeout,
+		bookRepository:   bookRepo,
+		authorRepository: authorRepo,
+		contextTimeOut:   timeout,
 	}
 }
 
@@ -57,7 +64,6 @@ func (service *bookService) Create(ctx context.Context, bookReq *dto.CreateBookR
 	for _, authorID := range bookCreate.AuthorID {
 		authorBook := new(entities.AuthorBook)
 		authorBook.BookID = bookCreate.ID.String()
-		log.Println(authorBook)
 		authorBook.AuthorID = authorID
 
 		err := service.bookRepository.AddAuthor(ctx, authorBook)
@@ -91,7 +97,7 @@ func (service *bookService) FindByID(ctx context.Context, id string) (*dto.ReadB
 	ctx, cancel := context.WithTimeout(ctx, service.contextTimeOut)
 	defer cancel()
 	if id == "" {
-		return nil, fmt.Errorf("id not found")
+		return nil, apperror.Validation(apperror.CodeInvalidID, "id is required", nil)
 	}
 	res, err := service.bookRepository.FindByID(ctx, id)
 	if err != nil {
@@ -110,11 +116,350 @@ func (service *bookService) GetBookByCondition(ctx context.Context, authorID str
 	return res, pageInfo, nil
 }
 
-func (service *bookService) IsAllowedToEdit(ctx context.Context, bookID string) bool {
-	book, err := service.bookRepository.FindByID(ctx, bookID)
+func (service *bookService) BulkCreate(ctx context.Context, bulkReq *dto.BulkCreateBookRequest) (*dto.BulkResponse, error) {
+	mode := dto.BulkMode(bulkReq.Mode)
+	results := newBulkResults(len(bulkReq.Items))
+	books := make([]*entities.Book, 0, len(bulkReq.Items))
+	indexes := make([]int, 0, len(bulkReq.Items))
+
+	for i := range bulkReq.Items {
+		item := &bulkReq.Items[i]
+		if err := helper.ValidateStruct(item); err != nil {
+			invalidItem(&results[i], err)
+			continue
+		}
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, &entities.Book{
+			ID:            id,
+			Title:         item.Title,
+			PublishedYear: time.Now(),
+			Isbn:          helper.GenerateRandomISBN(),
+			AuthorID:      item.AuthorID,
+		})
+		indexes = append(indexes, i)
+	}
+	if mode == dto.BulkModeAtomic && len(books) != len(bulkReq.Items) {
+		abortBulk(results)
+		return dto.NewBulkResponse(mode, results), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, service.contextTimeOut)
+	defer cancel()
+
+	err := service.bookRepository.CreateBatch(ctx, books)
+	switch {
+	case err == nil:
+		for j, book := range books {
+			bulkSucceeded(&results[indexes[j]], book.ID.String(), toCreateBookResponse(book))
+		}
+	case mode == dto.BulkModeAtomic:
+		failBatch(ctx, results, indexes, err)
+	default:
+		// The batch insert failed as a whole, retry row by row to isolate
+		// the items that caused it.
+		for j, book := range books {
+			if err := service.bookRepository.CreateBatch(ctx, []*entities.Book{book}); err != nil {
+				results[indexes[j]].Error = itemError(ctx, err)
+				continue
+			}
+			bulkSucceeded(&results[indexes[j]], book.ID.String(), toCreateBookResponse(book))
+		}
+	}
+	return dto.NewBulkResponse(mode, results), nil
+}
+
+func (service *bookService) BulkUpdate(ctx context.Context, bulkReq *dto.BulkUpdateBookRequest) (*dto.BulkResponse, error) {
+	mode := dto.BulkMode(bulkReq.Mode)
+	results := newBulkResults(len(bulkReq.Items))
+	ids := make([]string, 0, len(bulkReq.Items))
+	indexes := make([]int, 0, len(bulkReq.Items))
+
+	for i := range bulkReq.Items {
+		item := &bulkReq.Items[i]
+		if err := helper.ValidateStruct(item); err != nil {
+			invalidItem(&results[i], err)
+			continue
+		}
+		ids = append(ids, item.ID.String())
+		indexes = append(indexes, i)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, service.contextTimeOut)
+	defer cancel()
+
+	existing, err := service.bookRepository.ExistingIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	ids, indexes = dropMissing(ids, indexes, existing, results)
+	if mode == dto.BulkModeAtomic && len(ids) != len(bulkReq.Items) {
+		abortBulk(results)
+		return dto.NewBulkResponse(mode, results), nil
+	}
+
+	books := make([]*entities.Book, len(indexes))
+	for j, i := range indexes {
+		books[j] = &entities.Book{
+			ID:    bulkReq.Items[i].ID,
+			Title: bulkReq.Items[i].Title,
+		}
+	}
+
+	if mode == dto.BulkModeAtomic {
+		if err := service.bookRepository.UpdateBatch(ctx, books); err != nil {
+			failBatch(ctx, results, indexes, err)
+			return dto.NewBulkResponse(mode, results), nil
+		}
+		for j, book := range books {
+			bulkSucceeded(&results[indexes[j]], book.ID.String(), &dto.UpdateBookResponse{
+				ID:    book.ID.String(),
+				Title: book.Title,
+			})
+		}
+		return dto.NewBulkResponse(mode, results), nil
+	}
+
+	for j, book := range books {
+		res, err := service.bookRepository.Update(ctx, book)
+		if err != nil {
+			results[indexes[j]].ID = book.ID.String()
+			results[indexes[j]].Error = itemError(ctx, err)
+			continue
+		}
+		bulkSucceeded(&results[indexes[j]], res.ID, res)
+	}
+	return dto.NewBulkResponse(mode, results), nil
+}
+
+func (service *bookService) BulkDelete(ctx context.Context, bulkReq *dto.BulkDeleteRequest) (*dto.BulkResponse, error) {
+	mode := dto.BulkMode(bulkReq.Mode)
+	results := newBulkResults(len(bulkReq.IDs))
+	ids, indexes := parseBulkIDs(bulkReq.IDs, results)
+
+	ctx, cancel := context.WithTimeout(ctx, service.contextTimeOut)
+	defer cancel()
+
+	existing, err := service.bookRepository.ExistingIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	ids, indexes = dropMissing(ids, indexes, existing, results)
+	if mode == dto.BulkModeAtomic && len(ids) != len(bulkReq.IDs) {
+		abortBulk(results)
+		return dto.NewBulkResponse(mode, results), nil
+	}
+
+	deleteBatch(ctx, mode, results, ids, indexes, service.bookRepository.DeleteBatch)
+	return dto.NewBulkResponse(mode, results), nil
+}
+
+// Export hands every book matching the GetBookByCondition filters to fn. It
+// is not bound by the service timeout, a full export may legitimately take
+// longer; cancelling ctx still stops it.
+func (service *bookService) Export(ctx context.Context, authorID string, name string, fn func(book *entities.Book) error) error {
+	return service.bookRepository.Export(ctx, authorID, name, func(books []*entities.Book) error {
+		for _, book := range books {
+			if err := fn(book); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Import creates the books of records under new ids, linking them to
+// existing authors by id, else by exact name, and creating the authors that
+// do not exist yet under the id of the record, if any. Records that failed
+// to parse are reported and skipped. New authors are only kept along with a
+// book that references them. With dryRun nothing is written.
+func (service *bookService) Import(ctx context.Context, records []formats.Record, dryRun bool) (*dto.ImportResponse, error) {
+	var names, ids []string
+	seen := make(map[string]bool)
+	for _, record := range records {
+		if record.Err != nil {
+			continue
+		}
+		for _, author := range record.Book.Authors {
+			if !seen[author.Name] {
+				seen[author.Name] = true
+				names = append(names, author.Name)
+			}
+			if author.ID != uuid.Nil && !seen[author.ID.String()] {
+				seen[author.ID.String()] = true
+				ids = append(ids, author.ID.String())
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, service.contextTimeOut)
+	defer cancel()
+
+	existing := make(map[string]bool)
+	if len(ids) > 0 {
+		var err error
+		if existing, err = service.authorRepository.ExistingIDs(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+	found, err := service.authorRepository.FindByNames(ctx, names)
 	if err != nil {
-		return false
+		return nil, err
+	}
+	authorsByName := make(map[string]*entities.Author, len(names))
+	for _, author := range found {
+		authorsByName[author.Name] = author
+	}
+
+	results := make([]dto.ImportRowResult, len(records))
+	books := make([]*entities.Book, 0, len(records))
+	indexes := make([]int, 0, len(records))
+	// bookAuthors holds the new authors each book references.
+	bookAuthors := make([][]*entities.Author, 0, len(records))
+	var newAuthors []*entities.Author
+	createdByID := make(map[uuid.UUID]*entities.Author)
+	for i, record := range records {
+		results[i].Row = record.Line
+		if record.Err != nil {
+			results[i].Error = record.Err.Error()
+			continue
+		}
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return nil, err
+		}
+		book := &entities.Book{
+			ID:            id,
+			Title:         record.Book.Title,
+			PublishedYear: record.Book.PublishedYear,
+			Isbn:          record.Book.Isbn,
+		}
+		if book.Isbn == "" {
+			book.Isbn = helper.GenerateRandomISBN()
+		}
+		if book.PublishedYear.IsZero() {
+			book.PublishedYear = time.Now()
+		}
+		var authors []*entities.Author
+		for _, recordAuthor := range record.Book.Authors {
+			if existing[recordAuthor.ID.String()] {
+				book.AuthorID = append(book.AuthorID, recordAuthor.ID.String())
+				continue
+			}
+			author, ok := createdByID[recordAuthor.ID]
+			if !ok {
+				author, ok = authorsByName[recordAuthor.Name]
+			}
+			if !ok {
+				authorID := recordAuthor.ID
+				if authorID == uuid.Nil {
+					if authorID, err = uuid.NewRandom(); err != nil {
+						return nil, err
+					}
+				}
+				author = &entities.Author{
+					ID:      authorID,
+					Name:    recordAuthor.Name,
+					Country: recordAuthor.Country,
+				}
+				authorsByName[author.Name] = author
+				createdByID[author.ID] = author
+				newAuthors = append(newAuthors, author)
+			}
+			if createdByID[author.ID] != nil && !containsAuthor(authors, author) {
+				authors = append(authors, author)
+			}
+			book.AuthorID = append(book.AuthorID, author.ID.String())
+		}
+		books = append(books, book)
+		bookAuthors = append(bookAuthors, authors)
+		indexes = append(indexes, i)
+	}
+
+	imported := make([]bool, len(books))
+	created := make(map[uuid.UUID]bool, len(newAuthors))
+	if dryRun || service.bookRepository.ImportBatch(ctx, newAuthors, books) == nil {
+		for j := range books {
+			imported[j] = true
+		}
+		for _, author := range newAuthors {
+			created[author.ID] = true
+		}
+	} else {
+		// The batch insert failed as a whole, retry row by row, each with
+		// the new authors it references, to isolate the rows that caused
+		// it.
+		for j, book := range books {
+			var authors []*entities.Author
+			for _, author := range bookAuthors[j] {
+				if !created[author.ID] {
+					authors = append(authors, author)
+				}
+			}
+			if err := service.bookRepository.ImportBatch(ctx, authors, []*entities.Book{book}); err != nil {
+				results[indexes[j]].Error = itemError(ctx, err)
+				continue
+			}
+			imported[j] = true
+			for _, author := range authors {
+				created[author.ID] = true
+			}
+		}
+	}
+
+	res := &dto.ImportResponse{
+		DryRun:         dryRun,
+		AuthorsCreated: make([]*dto.AuthorResponse, 0, len(newAuthors)),
+		Results:        results,
+	}
+	for _, author := range newAuthors {
+		if created[author.ID] {
+			res.AuthorsCreated = append(res.AuthorsCreated, toAuthorResponse(author))
+		}
+	}
+
+	for j, book := range books {
+		if !imported[j] {
+			continue
+		}
+		result := &results[indexes[j]]
+		result.ID = book.ID.String()
+		result.Success = true
+		result.Data = toCreateBookResponse(book)
+	}
+	res.Total = len(results)
+	for _, result := range results {
+		if result.Success {
+			res.Imported++
+		} else {
+			res.Failed++
+		}
+	}
+	return res, nil
+}
+
+func containsAuthor(authors []*entities.Author, author *entities.Author) bool {
+	for _, a := range authors {
+		if a == author {
+			return true
+		}
+	}
+	return false
+}
+
+func (service *bookService) FindByAuthorIDs(ctx context.Context, authorIDs []string) (map[string][]*dto.CreateBookResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.contextTimeOut)
+	defer cancel()
+	return service.bookRepository.FindByAuthorIDs(ctx, authorIDs)
+}
+
+func toCreateBookResponse(book *entities.Book) *dto.CreateBookResponse {
+	return &dto.CreateBookResponse{
+		ID:            book.ID.String(),
+		Title:         book.Title,
+		PublishedYear: book.PublishedYear,
+		Isbn:          book.Isbn,
 	}
-	id := fmt.Sprintf("%v", book.ID)
-	return bookID == id
 }
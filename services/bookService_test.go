@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/database"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/formats"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// newMemoryBookService returns a book service on empty in-memory
+// repositories, with one author stored.
+func newMemoryBookService(t *testing.T) (BookService, repositories.BookRepository, *entities.Author) {
+	t.Helper()
+	store := repositories.NewMemoryStore()
+	bookRepo := repositories.NewMemoryBookRepository(store)
+	authorRepo := repositories.NewMemoryAuthorRepository(store)
+	author := &entities.Author{ID: uuid.New(), Name: "Terry Pratchett", Country: "GB"}
+	if _, err := authorRepo.Create(context.Background(), author); err != nil {
+		t.Fatal(err)
+	}
+	return NewBookService(bookRepo, authorRepo, time.Second), bookRepo, author
+}
+
+func TestBulkCreateBestEffortIsolatesFailingItems(t *testing.T) {
+	ctx := context.Background()
+	service, bookRepo, author := newMemoryBookService(t)
+
+	res, err := service.BulkCreate(ctx, &dto.BulkCreateBookRequest{
+		Mode: dto.BulkModeBestEffort,
+		Items: []dto.CreateBookRequest{
+			{Title: "Mort", AuthorID: []string{author.ID.String()}},
+			{Title: "Nobody's", AuthorID: []string{uuid.NewString()}},
+			{Title: "Sourcery", AuthorID: []string{author.ID.String()}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 2 || res.Failed != 1 || res.Results[1].Success || res.Results[1].Error == "" {
+		t.Fatalf("results %+v", res.Results)
+	}
+	books, _, err := bookRepo.GetBookByCondition(ctx, author.ID.String(), "", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 2 {
+		t.Errorf("%d books stored, want 2", len(books))
+	}
+}
+
+func TestBulkCreateAtomicRollsBack(t *testing.T) {
+	ctx := context.Background()
+	service, bookRepo, author := newMemoryBookService(t)
+
+	res, err := service.BulkCreate(ctx, &dto.BulkCreateBookRequest{
+		Items: []dto.CreateBookRequest{
+			{Title: "Mort", AuthorID: []string{author.ID.String()}},
+			{Title: "Nobody's", AuthorID: []string{uuid.NewString()}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Mode != dto.BulkModeAtomic || res.Succeeded != 0 {
+		t.Fatalf("results %+v", res.Results)
+	}
+	var stored int
+	err = bookRepo.Export(ctx, "", "", func(books []*entities.Book) error {
+		stored += len(books)
+		return nil
+	})
+	if err != nil || stored != 0 {
+		t.Errorf("%d books stored after a rolled back batch (%v)", stored, err)
+	}
+}
+
+// createBooks stores a book per title by author and returns their ids.
+func createBooks(t *testing.T, service BookService, author *entities.Author, titles ...string) []string {
+	t.Helper()
+	ids := make([]string, len(titles))
+	for i, title := range titles {
+		res, err := service.Create(context.Background(), &dto.CreateBookRequest{Title: title, AuthorID: []string{author.ID.String()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = res.ID
+	}
+	return ids
+}
+
+func TestBulkUpdateModes(t *testing.T) {
+	ctx := context.Background()
+	service, _, author := newMemoryBookService(t)
+	ids := createBooks(t, service, author, "Mort", "Sourcery")
+	items := []dto.UpdateBookRequest{
+		{ID: uuid.MustParse(ids[0]), Title: "Mort (revised)"},
+		{ID: uuid.New(), Title: "Nothing"},
+	}
+
+	res, err := service.BulkUpdate(ctx, &dto.BulkUpdateBookRequest{Items: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 0 || res.Results[0].Error == "" {
+		t.Fatalf("atomic results %+v", res.Results)
+	}
+	if book, _ := service.FindByID(ctx, ids[0]); book.Title != "Mort" {
+		t.Errorf("rolled back batch renamed the book to %q", book.Title)
+	}
+
+	res, err = service.BulkUpdate(ctx, &dto.BulkUpdateBookRequest{Mode: dto.BulkModeBestEffort, Items: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 1 || res.Failed != 1 || !res.Results[0].Success {
+		t.Fatalf("best effort results %+v", res.Results)
+	}
+	if book, _ := service.FindByID(ctx, ids[0]); book.Title != "Mort (revised)" {
+		t.Errorf("best effort batch left the title %q", book.Title)
+	}
+}
+
+func TestBulkDeleteModes(t *testing.T) {
+	ctx := context.Background()
+	service, _, author := newMemoryBookService(t)
+	ids := createBooks(t, service, author, "Mort", "Sourcery")
+
+	res, err := service.BulkDelete(ctx, &dto.BulkDeleteRequest{IDs: []string{ids[0], "42"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 0 {
+		t.Fatalf("atomic results %+v", res.Results)
+	}
+	if _, err := service.FindByID(ctx, ids[0]); err != nil {
+		t.Errorf("rolled back batch deleted the book: %v", err)
+	}
+
+	res, err = service.BulkDelete(ctx, &dto.BulkDeleteRequest{Mode: dto.BulkModeBestEffort, IDs: []string{ids[0], "42"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 1 || res.Failed != 1 {
+		t.Fatalf("best effort results %+v", res.Results)
+	}
+	if _, err := service.FindByID(ctx, ids[0]); err == nil {
+		t.Error("best effort batch kept the book")
+	}
+	if _, err := service.FindByID(ctx, ids[1]); err != nil {
+		t.Errorf("book outside the batch: %v", err)
+	}
+}
+
+// newSQLiteDB returns an empty in-memory SQLite database.
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	ctx := context.Background()
+	cfg := config.Default().Database
+	cfg.Driver, cfg.URL = config.DriverSQLite, ":memory:"
+	db, err := database.ConnectionDB(ctx, cfg, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.CloseDatabaseConnection(db) })
+	if _, err := database.Migrate(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestBulkErrorsHideDriverDetails(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	authorRepo := repositories.NewAuthorRepository(db)
+	author := &entities.Author{ID: uuid.New(), Name: "Terry Pratchett", Country: "GB"}
+	if _, err := authorRepo.Create(ctx, author); err != nil {
+		t.Fatal(err)
+	}
+	service := NewBookService(repositories.NewBookRepository(db), authorRepo, time.Second)
+
+	for _, mode := range []string{dto.BulkModeAtomic, dto.BulkModeBestEffort} {
+		res, err := service.BulkCreate(ctx, &dto.BulkCreateBookRequest{
+			Mode: mode,
+			Items: []dto.CreateBookRequest{
+				{Title: "Mort", AuthorID: []string{author.ID.String()}},
+				{Title: "Nobody's", AuthorID: []string{uuid.NewString()}},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.Results[1].Error; got != "referenced record does not exist" {
+			t.Errorf("%s: item error %q", mode, got)
+		}
+		for _, result := range res.Results {
+			if strings.Contains(strings.ToUpper(result.Error), "FOREIGN KEY") {
+				t.Errorf("%s: driver error leaked: %q", mode, result.Error)
+			}
+		}
+	}
+}
+
+func TestImportLinksAuthorsByID(t *testing.T) {
+	ctx := context.Background()
+	service, bookRepo, author := newMemoryBookService(t)
+	exported := uuid.New()
+
+	res, err := service.Import(ctx, []formats.Record{
+		// A renamed author is still linked by id.
+		{Line: 2, Book: &entities.Book{Title: "Mort", Authors: []*entities.Author{{ID: author.ID, Name: "Sir Terry Pratchett"}}}},
+		// An unknown author is created under the exported id, once.
+		{Line: 3, Book: &entities.Book{Title: "Coraline", Authors: []*entities.Author{{ID: exported, Name: "Neil Gaiman", Country: "GB"}}}},
+		{Line: 4, Book: &entities.Book{Title: "Stardust", Authors: []*entities.Author{{ID: exported, Name: "Neil Gaiman", Country: "GB"}}}},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Imported != 3 || len(res.AuthorsCreated) != 1 || res.AuthorsCreated[0].ID != exported.String() {
+		t.Fatalf("import %+v", res)
+	}
+	for _, id := range []uuid.UUID{author.ID, exported} {
+		books, _, err := bookRepo.GetBookByCondition(ctx, id.String(), "", 1, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(books) == 0 {
+			t.Errorf("no books linked to %s", id)
+		}
+	}
+}
+
+// lockedBooks fails every batch delete that includes the locked book.
+type lockedBooks struct {
+	repositories.BookRepository
+	locked string
+}
+
+func (r lockedBooks) DeleteBatch(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		if id == r.locked {
+			return errors.New("database is locked")
+		}
+	}
+	return r.BookRepository.DeleteBatch(ctx, ids)
+}
+
+func TestBulkDeleteBestEffortIsolatesFailingIDs(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	authorRepo := repositories.NewMemoryAuthorRepository(store)
+	author := &entities.Author{ID: uuid.New(), Name: "Terry Pratchett", Country: "GB"}
+	if _, err := authorRepo.Create(ctx, author); err != nil {
+		t.Fatal(err)
+	}
+	bookRepo := repositories.NewMemoryBookRepository(store)
+	ids := createBooks(t, NewBookService(bookRepo, authorRepo, time.Second), author, "Mort", "Sourcery")
+	service := NewBookService(lockedBooks{BookRepository: bookRepo, locked: ids[1]}, authorRepo, time.Second)
+
+	res, err := service.BulkDelete(ctx, &dto.BulkDeleteRequest{Mode: dto.BulkModeBestEffort, IDs: ids})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 1 || !res.Results[0].Success || res.Results[1].Error != "internal error" {
+		t.Fatalf("results %+v", res.Results)
+	}
+	if _, err := service.FindByID(ctx, ids[0]); err == nil {
+		t.Error("deletable book kept")
+	}
+
+	res, err = service.BulkDelete(ctx, &dto.BulkDeleteRequest{IDs: ids[1:]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Succeeded != 0 || res.Results[0].Error != "internal error" {
+		t.Fatalf("atomic results %+v", res.Results)
+	}
+}
+
+func TestImportDoesNotKeepAuthorsOfFailedBooks(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	authorRepo := repositories.NewAuthorRepository(db)
+	service := NewBookService(repositories.NewBookRepository(db), authorRepo, time.Second)
+
+	// Listing an author twice links the book to it twice, which fails the
+	// book insert.
+	gaiman := &entities.Author{ID: uuid.New(), Name: "Neil Gaiman", Country: "GB"}
+	res, err := service.Import(ctx, []formats.Record{
+		{Line: 2, Book: &entities.Book{Title: "Mort", Authors: []*entities.Author{{Name: "Terry Pratchett", Country: "GB"}}}},
+		{Line: 3, Book: &entities.Book{Title: "Coraline", Authors: []*entities.Author{gaiman, gaiman}}},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Imported != 1 || res.Results[1].Success || len(res.AuthorsCreated) != 1 || res.AuthorsCreated[0].Name != "Terry Pratchett" {
+		t.Fatalf("import %+v", res)
+	}
+	existing, err := authorRepo.ExistingIDs(ctx, []string{gaiman.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if existing[gaiman.ID.String()] {
+		t.Error("author of the failed book was kept")
+	}
+}
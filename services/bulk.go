@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/helper"
+	"github.com/aldisaputra17/book-store/repositories"
+	"github.com/google/uuid"
+)
+
+var (
+	errBulkNotFound   = errors.New("record not found")
+	errBulkRolledBack = errors.New("not applied: batch rolled back")
+)
+
+func newBulkResults(n int) []dto.BulkItemResult {
+	results := make([]dto.BulkItemResult, n)
+	for i := range results {
+		results[i].Index = i
+	}
+	return results
+}
+
+func bulkSucceeded(result *dto.BulkItemResult, id string, data interface{}) {
+	result.ID = id
+	result.Success = true
+	result.Error = ""
+	result.Data = data
+}
+
+// abortBulk marks every item that did not fail on its own as rolled back,
+// used when an atomic batch cannot be applied as a whole.
+func abortBulk(results []dto.BulkItemResult) {
+	for i := range results {
+		results[i].Success = false
+		results[i].Data = nil
+		if results[i].Error == "" {
+			results[i].Error = errBulkRolledBack.Error()
+		}
+	}
+}
+
+// invalidItem records the failed validation rules of a bulk item.
+func invalidItem(result *dto.BulkItemResult, err error) {
+	result.Error = apperror.From(err).Message
+	result.Fields = helper.FieldErrors(err, helper.DefaultLocale)
+}
+
+// itemError is the message reported for a failed bulk item: that of the
+// domain error, without the driver error it may wrap. Internal errors are
+// logged with their cause.
+func itemError(ctx context.Context, err error) string {
+	appErr := apperror.From(err)
+	if appErr.Kind == apperror.KindInternal {
+		helper.Logger(ctx).WithError(err).Error("bulk item failed")
+	}
+	return appErr.Message
+}
+
+// failBatch records err against the item that caused an atomic batch to fail
+// and rolls back the others. indexes maps batch positions to request indexes.
+func failBatch(ctx context.Context, results []dto.BulkItemResult, indexes []int, err error) {
+	var itemErr *repositories.BatchItemError
+	if errors.As(err, &itemErr) && itemErr.Index < len(indexes) {
+		results[indexes[itemErr.Index]].Error = itemError(ctx, itemErr.Err)
+	} else {
+		message := itemError(ctx, err)
+		for _, i := range indexes {
+			results[i].Error = message
+		}
+	}
+	abortBulk(results)
+}
+
+// deleteBatch deletes ids with deleteFn and records the outcome of each.
+// When the batch fails as a whole, an atomic batch is rolled back while a
+// best effort one is retried id by id to isolate the ids that caused it.
+// indexes maps batch positions to request indexes.
+func deleteBatch(ctx context.Context, mode string, results []dto.BulkItemResult, ids []string, indexes []int, deleteFn func(ctx context.Context, ids []string) error) {
+	for j, id := range ids {
+		results[indexes[j]].ID = id
+	}
+	err := deleteFn(ctx, ids)
+	switch {
+	case err == nil:
+		for j, id := range ids {
+			bulkSucceeded(&results[indexes[j]], id, nil)
+		}
+	case mode == dto.BulkModeAtomic:
+		failBatch(ctx, results, indexes, err)
+	default:
+		for j, id := range ids {
+			if err := deleteFn(ctx, []string{id}); err != nil {
+				results[indexes[j]].Error = itemError(ctx, err)
+				continue
+			}
+			bulkSucceeded(&results[indexes[j]], id, nil)
+		}
+	}
+}
+
+// parseBulkIDs validates the ids of a bulk request, recording an error for
+// every malformed one, and returns the valid ids with their request indexes.
+func parseBulkIDs(ids []string, results []dto.BulkItemResult) ([]string, []int) {
+	valid := make([]string, 0, len(ids))
+	indexes := make([]int, 0, len(ids))
+	for i, id := range ids {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			results[i].ID = id
+			results[i].Error = err.Error()
+			continue
+		}
+		valid = append(valid, parsed.String())
+		indexes = append(indexes, i)
+	}
+	return valid, indexes
+}
+
+// dropMissing records a not found error for every id absent from existing
+// and returns the remaining ids with their request indexes.
+func dropMissing(ids []string, indexes []int, existing map[string]bool, results []dto.BulkItemResult) ([]string, []int) {
+	keptIDs := make([]string, 0, len(ids))
+	keptIndexes := make([]int, 0, len(indexes))
+	for j, id := range ids {
+		if !existing[id] {
+			results[indexes[j]].ID = id
+			results[indexes[j]].Error = errBulkNotFound.Error()
+			continue
+		}
+		keptIDs = append(keptIDs, id)
+		keptIndexes = append(keptIndexes, indexes[j])
+	}
+	return keptIDs, keptIndexes
+}
@@ -2,53 +2,51 @@ package services
 
 import (
 	"fmt"
-	"os"
 	"time"
 
+	"github.com/aldisaputra17/book-store/config"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
 )
 
 type JWTService interface {
-	GenerateToken(userID uuid.UUID) string
+	GenerateToken(userID uuid.UUID, admin bool) string
 	ValidateToken(token string) (*jwt.Token, error)
 }
 
 type jwtCustomClaim struct {
 	UserID uuid.UUID `json:"user_id"`
-	claim  jwt.StandardClaims
+	Admin  bool      `json:"admin,omitempty"`
+	jwt.StandardClaims
 }
 
 type jwtService struct {
 	secretKey string
 	issuer    string
+	ttl       time.Duration
 }
 
-func NewJWTService() JWTService {
+func NewJWTService(cfg config.JWTConfig) JWTService {
 	return &jwtService{
-		issuer:    "book-store",
-		secretKey: getSecretKey(),
+		issuer:    cfg.Issuer,
+		secretKey: cfg.Secret,
+		ttl:       cfg.TTL,
 	}
 }
 
-func getSecretKey() string {
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey != "" {
-		secretKey = "book-store"
-	}
-	return secretKey
-}
-
-func (j *jwtService) GenerateToken(UserID uuid.UUID) string {
+// GenerateToken issues a token for the user. admin is copied from the user
+// when the token is issued: a change of the flag applies to new tokens.
+func (j *jwtService) GenerateToken(UserID uuid.UUID, admin bool) string {
 	claims := &jwtCustomClaim{
 		UserID: UserID,
-		claim: jwt.StandardClaims{
-			ExpiresAt: time.Now().AddDate(1, 0, 0).Unix(),
+		Admin:  admin,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(j.ttl).Unix(),
 			Issuer:    j.issuer,
 			IssuedAt:  time.Now().Unix(),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims.claim)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	t, err := token.SignedString([]byte(j.secretKey))
 	if err != nil {
 		panic(err)
@@ -64,3 +62,25 @@ func (j *jwtService) ValidateToken(token string) (*jwt.Token, error) {
 		return []byte(j.secretKey), nil
 	})
 }
+
+// TokenUserID is the user_id claim of a validated token, empty when the
+// token has none.
+func TokenUserID(token *jwt.Token) string {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	userID, _ := claims["user_id"].(string)
+	return userID
+}
+
+// TokenAdmin is the admin claim of a validated token, false when the token
+// has none.
+func TokenAdmin(token *jwt.Token) bool {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	admin, _ := claims["admin"].(bool)
+	return admin
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+type gormPlugin struct{}
+
+// GormPlugin starts a span for every query of the database it is used on,
+// as a child of the span in the statement context (see
+// gorm.DB.WithContext). Preloads and counts get spans of their own.
+func GormPlugin() gorm.Plugin {
+	return &gormPlugin{}
+}
+
+func (p *gormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	registrations := []error{
+		cb.Create().Before("gorm:create").Register("tracing:before_create", p.before("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", p.before("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", p.after),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", p.before("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", p.after),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", p.before("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", p.after),
+	}
+	return errors.Join(registrations...)
+}
+
+func (p *gormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement.Context == nil {
+			return
+		}
+		ctx, span := tracer.Start(db.Statement.Context, "gorm."+operation, trace.WithSpanKind(trace.SpanKindClient))
+		span.SetAttributes(semconv.DBOperation(operation))
+		if db.Statement.Table != "" {
+			span.SetAttributes(semconv.DBSQLTable(db.Statement.Table))
+		}
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *gormPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+	if db.Dialector != nil {
+		span.SetAttributes(semconv.DBSystemKey.String(db.Dialector.Name()))
+	}
+	// The statement is built by now; its variables are left out.
+	span.SetAttributes(
+		semconv.DBStatement(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	end(span, err)
+}
@@ -0,0 +1,193 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/formats"
+	"github.com/aldisaputra17/book-store/services"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type bookService struct {
+	next services.BookService
+}
+
+// BookService starts a span around every call to next.
+func BookService(next services.BookService) services.BookService {
+	return &bookService{next: next}
+}
+
+func (s *bookService) Create(ctx context.Context, bookReq *dto.CreateBookRequest) (res *dto.CreateBookResponse, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.Create")
+	defer func() { end(span, err) }()
+	return s.next.Create(ctx, bookReq)
+}
+
+func (s *bookService) Update(ctx context.Context, bookReq *dto.UpdateBookRequest) (res *dto.UpdateBookResponse, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.Update")
+	defer func() { end(span, err) }()
+	return s.next.Update(ctx, bookReq)
+}
+
+func (s *bookService) Delete(ctx context.Context, book entities.Book) (err error) {
+	ctx, span := tracer.Start(ctx, "BookService.Delete")
+	span.SetAttributes(attribute.String("book.id", book.ID.String()))
+	defer func() { end(span, err) }()
+	return s.next.Delete(ctx, book)
+}
+
+func (s *bookService) FindByID(ctx context.Context, id string) (res *dto.ReadBookResponse, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.FindByID")
+	span.SetAttributes(attribute.String("book.id", id))
+	defer func() { end(span, err) }()
+	return s.next.FindByID(ctx, id)
+}
+
+func (s *bookService) GetBookByCondition(ctx context.Context, authorID string, name string, page int, PageSize int) (res []dto.ReadBookResponse, pageInfo entities.Pagination, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.GetBookByCondition")
+	span.SetAttributes(attribute.Int("page", page), attribute.Int("page_size", PageSize))
+	defer func() { end(span, err) }()
+	return s.next.GetBookByCondition(ctx, authorID, name, page, PageSize)
+}
+
+func (s *bookService) BulkCreate(ctx context.Context, bulkReq *dto.BulkCreateBookRequest) (res *dto.BulkResponse, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.BulkCreate")
+	span.SetAttributes(attribute.Int("bulk.items", len(bulkReq.Items)))
+	defer func() { end(span, err) }()
+	return s.next.BulkCreate(ctx, bulkReq)
+}
+
+func (s *bookService) BulkUpdate(ctx context.Context, bulkReq *dto.BulkUpdateBookRequest) (res *dto.BulkResponse, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.BulkUpdate")
+	span.SetAttributes(attribute.Int("bulk.items", len(bulkReq.Items)))
+	defer func() { end(span, err) }()
+	return s.next.BulkUpdate(ctx, bulkReq)
+}
+
+func (s *bookService) BulkDelete(ctx context.Context, bulkReq *dto.BulkDeleteRequest) (res *dto.BulkResponse, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.BulkDelete")
+	span.SetAttributes(attribute.Int("bulk.items", len(bulkReq.IDs)))
+	defer func() { end(span, err) }()
+	return s.next.BulkDelete(ctx, bulkReq)
+}
+
+func (s *bookService) Export(ctx context.Context, authorID string, name string, fn func(book *entities.Book) error) (err error) {
+	ctx, span := tracer.Start(ctx, "BookService.Export")
+	defer func() { end(span, err) }()
+	return s.next.Export(ctx, authorID, name, fn)
+}
+
+func (s *bookService) Import(ctx context.Context, records []formats.Record, dryRun bool) (res *dto.ImportResponse, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.Import")
+	span.SetAttributes(attribute.Int("import.records", len(records)), attribute.Bool("import.dry_run", dryRun))
+	defer func() { end(span, err) }()
+	return s.next.Import(ctx, records, dryRun)
+}
+
+func (s *bookService) FindByAuthorIDs(ctx context.Context, authorIDs []string) (res map[string][]*dto.CreateBookResponse, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.FindByAuthorIDs")
+	span.SetAttributes(attribute.Int("author.ids", len(authorIDs)))
+	defer func() { end(span, err) }()
+	return s.next.FindByAuthorIDs(ctx, authorIDs)
+}
+
+type authorService struct {
+	next services.AuthorService
+}
+
+// AuthorService starts a span around every call to next.
+func AuthorService(next services.AuthorService) services.AuthorService {
+	return &authorService{next: next}
+}
+
+func (s *authorService) Create(ctx context.Context, authorReq *dto.CreateAuthorRequest) (res *dto.AuthorResponse, err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.Create")
+	defer func() { end(span, err) }()
+	return s.next.Create(ctx, authorReq)
+}
+
+func (s *authorService) GetAuthorByCondition(ctx context.Context, bookID string, title string, page int, PageSize int) (res []dto.ReadAuthorResponse, pageInfo entities.Pagination, err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.GetAuthorByCondition")
+	span.SetAttributes(attribute.Int("page", page), attribute.Int("page_size", PageSize))
+	defer func() { end(span, err) }()
+	return s.next.GetAuthorByCondition(ctx, bookID, title, page, PageSize)
+}
+
+func (s *authorService) Update(ctx context.Context, authorReq *dto.UpdateAuthorRequest) (res *dto.UpdateAuthorResponse, err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.Update")
+	defer func() { end(span, err) }()
+	return s.next.Update(ctx, authorReq)
+}
+
+func (s *authorService) Delete(ctx context.Context, author entities.Author) (err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.Delete")
+	span.SetAttributes(attribute.String("author.id", author.ID.String()))
+	defer func() { end(span, err) }()
+	return s.next.Delete(ctx, author)
+}
+
+func (s *authorService) FindByID(ctx context.Context, id string) (res *dto.ReadAuthorResponse, err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.FindByID")
+	span.SetAttributes(attribute.String("author.id", id))
+	defer func() { end(span, err) }()
+	return s.next.FindByID(ctx, id)
+}
+
+func (s *authorService) BulkCreate(ctx context.Context, bulkReq *dto.BulkCreateAuthorRequest) (res *dto.BulkResponse, err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.BulkCreate")
+	span.SetAttributes(attribute.Int("bulk.items", len(bulkReq.Items)))
+	defer func() { end(span, err) }()
+	return s.next.BulkCreate(ctx, bulkReq)
+}
+
+func (s *authorService) BulkUpdate(ctx context.Context, bulkReq *dto.BulkUpdateAuthorRequest) (res *dto.BulkResponse, err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.BulkUpdate")
+	span.SetAttributes(attribute.Int("bulk.items", len(bulkReq.Items)))
+	defer func() { end(span, err) }()
+	return s.next.BulkUpdate(ctx, bulkReq)
+}
+
+func (s *authorService) BulkDelete(ctx context.Context, bulkReq *dto.BulkDeleteRequest) (res *dto.BulkResponse, err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.BulkDelete")
+	span.SetAttributes(attribute.Int("bulk.items", len(bulkReq.IDs)))
+	defer func() { end(span, err) }()
+	return s.next.BulkDelete(ctx, bulkReq)
+}
+
+func (s *authorService) FindByBookIDs(ctx context.Context, bookIDs []string) (res map[string][]*dto.AuthorResponse, err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.FindByBookIDs")
+	span.SetAttributes(attribute.Int("book.ids", len(bookIDs)))
+	defer func() { end(span, err) }()
+	return s.next.FindByBookIDs(ctx, bookIDs)
+}
+
+type authService struct {
+	services.AuthService
+}
+
+// AuthService starts a span around registrations and password changes.
+// Credential checks take no context and are left to the HTTP and gRPC
+// spans.
+func AuthService(next services.AuthService) services.AuthService {
+	return &authService{AuthService: next}
+}
+
+func (s *authService) Register(ctx context.Context, registerReq *dto.AuthRequest) (user *entities.User, err error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Register")
+	defer func() { end(span, err) }()
+	return s.AuthService.Register(ctx, registerReq)
+}
+
+func (s *authService) CreateAdmin(ctx context.Context, adminReq *dto.AuthRequest) (user *entities.User, err error) {
+	ctx, span := tracer.Start(ctx, "AuthService.CreateAdmin")
+	defer func() { end(span, err) }()
+	return s.AuthService.CreateAdmin(ctx, adminReq)
+}
+
+func (s *authService) ResetPassword(ctx context.Context, resetReq *dto.AuthRequest) (err error) {
+	ctx, span := tracer.Start(ctx, "AuthService.ResetPassword")
+	defer func() { end(span, err) }()
+	return s.AuthService.ResetPassword(ctx, resetReq)
+}
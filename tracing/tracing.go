@@ -0,0 +1,83 @@
+// Package tracing sets up OpenTelemetry tracing and instruments the
+// services and GORM with spans. HTTP and gRPC servers are instrumented
+// with the otelgin and otelgrpc middleware, which pick up the W3C trace
+// context of incoming requests.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/aldisaputra17/book-store"
+
+var tracer = otel.Tracer(instrumentationName)
+
+// Setup installs the tracer provider of cfg and the W3C trace context
+// propagator. The returned function flushes and stops the exporter. With
+// the "none" exporter no spans are recorded but incoming trace context is
+// still propagated, so log entries carry the caller's trace ID.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if cfg.Exporter == "none" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case "otlp":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	}
+	return nil, fmt.Errorf("tracing: unknown exporter %q", cfg.Exporter)
+}
+
+// end records err on span and ends it. Only internal errors mark the span
+// as failed; not found, validation and the like are expected outcomes.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if apperror.KindOf(err) == apperror.KindInternal {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
@@ -0,0 +1,170 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/aldisaputra17/book-store/apperror"
+	"github.com/aldisaputra17/book-store/config"
+	"github.com/aldisaputra17/book-store/dto"
+	"github.com/aldisaputra17/book-store/entities"
+	"github.com/aldisaputra17/book-store/services"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recorder collects the ended spans of every test; the global provider
+// can only be installed once.
+var recorder = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	if _, err := Setup(context.Background(), config.Default().Tracing); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// spans returns the spans ended by fn.
+func spans(fn func()) []sdktrace.ReadOnlySpan {
+	before := len(recorder.Ended())
+	fn()
+	return recorder.Ended()[before:]
+}
+
+func find(t *testing.T, spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, s := range spans {
+		if s.Name() == name {
+			return s
+		}
+	}
+	t.Fatalf("no span %q in %d spans", name, len(spans))
+	return nil
+}
+
+func attr(s sdktrace.ReadOnlySpan, key string) string {
+	for _, kv := range s.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+type stubBookService struct {
+	services.BookService
+	err error
+}
+
+func (s stubBookService) FindByID(ctx context.Context, id string) (*dto.ReadBookResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &dto.ReadBookResponse{}, nil
+}
+
+func TestServiceSpanStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status codes.Code
+		events int
+	}{
+		{"ok", nil, codes.Unset, 0},
+		{"not found", apperror.NotFound("book_not_found", "book not found"), codes.Unset, 1},
+		{"internal", errors.New("connection refused"), codes.Error, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			books := BookService(stubBookService{err: tt.err})
+			got := spans(func() { _, _ = books.FindByID(context.Background(), "42") })
+
+			span := find(t, got, "BookService.FindByID")
+			if span.Status().Code != tt.status {
+				t.Errorf("status = %v, want %v", span.Status().Code, tt.status)
+			}
+			if len(span.Events()) != tt.events {
+				t.Errorf("events = %d, want %d", len(span.Events()), tt.events)
+			}
+			if id := attr(span, "book.id"); id != "42" {
+				t.Errorf("book.id = %q", id)
+			}
+		})
+	}
+}
+
+func TestGormPluginNestsQueries(t *testing.T) {
+	// DryRun builds the SQL without a database; the callbacks still run.
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(GormPlugin()); err != nil {
+		t.Fatal(err)
+	}
+
+	var parent trace.SpanContext
+	got := spans(func() {
+		ctx, span := tracer.Start(context.Background(), "parent")
+		parent = span.SpanContext()
+		var books []entities.Book
+		db.WithContext(ctx).Where("name = ?", "Dune").Find(&books)
+		span.End()
+	})
+
+	query := find(t, got, "gorm.query")
+	if query.Parent().SpanID() != parent.SpanID() {
+		t.Errorf("gorm.query is not a child of the caller's span")
+	}
+	if query.SpanKind() != trace.SpanKindClient {
+		t.Errorf("kind = %v", query.SpanKind())
+	}
+	for key, want := range map[string]string{
+		"db.system":    "postgres",
+		"db.operation": "query",
+		"db.sql.table": "books",
+		"db.statement": `SELECT * FROM "books" WHERE name = $1`,
+	} {
+		if got := attr(query, key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestHTTPTraceContextReachesServices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	books := BookService(stubBookService{})
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(otelgin.Middleware("book-store"))
+	r.GET("/books/:id", func(c *gin.Context) {
+		_, _ = books.FindByID(c, c.Param("id"))
+		c.Status(http.StatusOK)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/books/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	got := spans(func() { r.ServeHTTP(httptest.NewRecorder(), req) })
+
+	server := find(t, got, "/books/:id")
+	service := find(t, got, "BookService.FindByID")
+	if server.SpanContext().TraceID().String() != traceID {
+		t.Errorf("server span did not continue the incoming trace")
+	}
+	if service.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Errorf("service span is not a child of the server span")
+	}
+}